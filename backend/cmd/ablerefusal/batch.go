@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ablerefusal/ablerefusal/pkg/client"
+)
+
+// batchItem is one request read from a batch file
+type batchItem struct {
+	line int
+	body map[string]interface{}
+}
+
+// csvNumbers lists the request fields parsed as numbers in CSV files
+var csvNumbers = map[string]bool{
+	"width": true, "height": true, "steps": true, "cfg_scale": true, "seed": true,
+	"batch_size": true, "strength": true, "prompt_count": true,
+}
+
+func runBatch(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("batch", "[flags] <file.jsonl|file.csv>")
+	defaults := addRequestFlags(fs)
+	concurrency := fs.Int("concurrency", 4, "maximum number of generations in flight")
+	wait := fs.Bool("wait", false, "wait for every generation to finish")
+	out := fs.String("out", "", "directory to download the images to (implies --wait)")
+	format := fs.String("format", "", `file format, "jsonl" or "csv" (default from the file extension)`)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	if *concurrency < 1 {
+		return errors.New("--concurrency must be at least 1")
+	}
+
+	base, err := defaults.body()
+	if err != nil {
+		return err
+	}
+	delete(base, "id") // IDs must be unique, only a line may set one
+
+	items, err := readBatch(fs.Arg(0), *format)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu     sync.Mutex
+		failed int
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, *concurrency)
+	)
+	report := func(item batchItem, format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Printf("line %d\t"+format+"\n", append([]interface{}{item.line}, args...)...)
+	}
+	fail := func(item batchItem, err error) {
+		mu.Lock()
+		failed++
+		mu.Unlock()
+		report(item, "error\t%v", err)
+	}
+
+	for _, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(item batchItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			body := make(map[string]interface{}, len(base)+len(item.body))
+			for key, value := range base {
+				body[key] = value
+			}
+			for key, value := range item.body {
+				body[key] = value
+			}
+
+			resp, err := c.GenerateBody(ctx, body)
+			if err != nil {
+				fail(item, err)
+				return
+			}
+			if !*wait && *out == "" {
+				report(item, "%s", strings.Join(resp.JobIDs(), ","))
+				return
+			}
+
+			for _, id := range resp.JobIDs() {
+				status, err := c.Wait(ctx, id)
+				if err != nil {
+					fail(item, fmt.Errorf("%s: %w", id, err))
+					continue
+				}
+				if status.Status != client.StatusCompleted {
+					fail(item, fmt.Errorf("%s %s: %s", id, status.Status, status.Error))
+					continue
+				}
+
+				files := []string{}
+				if *out != "" {
+					if files, err = download(ctx, c, status, *out); err != nil {
+						fail(item, fmt.Errorf("%s: %w", id, err))
+						continue
+					}
+				}
+				report(item, "%s\t%s\t%s", id, status.Status, strings.Join(files, ","))
+			}
+		}(item)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d requests failed", failed, len(items))
+	}
+	return nil
+}
+
+// readBatch reads the requests of a JSONL or CSV batch file
+func readBatch(name, format string) ([]batchItem, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	}
+
+	switch format {
+	case "jsonl", "ndjson":
+		return readJSONL(f)
+	case "csv":
+		return readCSV(f)
+	default:
+		return nil, fmt.Errorf("unknown batch format %q, use --format jsonl or csv", format)
+	}
+}
+
+// readJSONL reads one request object per line. Blank lines and lines
+// starting with # are skipped.
+func readJSONL(r io.Reader) ([]batchItem, error) {
+	var items []batchItem
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &body); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		items = append(items, batchItem{line: line, body: body})
+	}
+	return items, scanner.Err()
+}
+
+// readCSV reads requests from a CSV file whose header names the request
+// fields, e.g. "prompt,negative_prompt,seed". Empty cells are left out and
+// "styles" is a semicolon-separated list.
+func readCSV(r io.Reader) ([]batchItem, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var items []batchItem
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		body := make(map[string]interface{})
+		for i, cell := range record {
+			cell = strings.TrimSpace(cell)
+			if cell == "" || header[i] == "" {
+				continue
+			}
+
+			field := header[i]
+			switch {
+			case csvNumbers[field]:
+				if _, err := strconv.ParseFloat(cell, 64); err != nil {
+					return nil, fmt.Errorf("line %d: invalid %s %q", line, field, cell)
+				}
+				body[field] = json.Number(cell)
+			case field == "styles":
+				body[field] = strings.Split(cell, ";")
+			default:
+				body[field] = cell
+			}
+		}
+		items = append(items, batchItem{line: line, body: body})
+	}
+	return items, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/pkg/client"
+)
+
+func runStatus(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("status", "<id>")
+	watch := fs.Bool("watch", false, "show progress until the generation finishes")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	if *watch {
+		status, err := waitWithProgress(ctx, c, fs.Arg(0), "")
+		if err != nil {
+			return err
+		}
+		return printJSON(status)
+	}
+
+	status, err := c.Status(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printJSON(status)
+}
+
+func runCancel(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("cancel", "<id>...")
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+
+	for _, id := range fs.Args() {
+		if err := c.Cancel(ctx, id); err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		fmt.Printf("%s cancelled\n", id)
+	}
+	return nil
+}
+
+func runQueue(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("queue", "")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	items, err := c.Queue(ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(items)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "POSITION\tID\tSTATUS\tPROGRESS\tPROMPT")
+	for _, item := range items {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%.0f%%\t%s\n", item.Position, item.Request.ID, item.Status.Status, item.Status.Progress, truncate(item.Request.Prompt, 50))
+	}
+	return w.Flush()
+}
+
+func runModels(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("models", "")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	models, err := c.Models(ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(models)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tVERSION\tREADY")
+	for _, model := range models {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", model.ID, model.Name, model.Version, model.Ready)
+	}
+	return w.Flush()
+}
+
+func runHistory(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("history", "[flags]")
+	status := fs.String("status", "", "only show generations with this status")
+	query := fs.String("q", "", "only show prompts containing this text")
+	limit := fs.Int("limit", 20, "maximum number of entries")
+	offset := fs.Int("offset", 0, "number of entries to skip")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	page, err := c.History(ctx, client.HistoryOptions{
+		Status: client.GenerationStatusType(*status),
+		Query:  *query,
+		Limit:  *limit,
+		Offset: *offset,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(page)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tCREATED\tIMAGES\tPROMPT")
+	for _, entry := range page.Entries {
+		prompt := ""
+		if entry.Request != nil {
+			prompt = entry.Request.Prompt
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", entry.ID, entry.Status, entry.CreatedAt.Local().Format(time.DateTime), len(entry.Results), truncate(prompt, 50))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d entries\n", page.Count, page.Total)
+	return nil
+}
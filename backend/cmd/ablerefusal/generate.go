@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ablerefusal/ablerefusal/pkg/client"
+)
+
+// listFlag collects a repeatable string flag
+type listFlag []string
+
+func (l *listFlag) String() string     { return strings.Join(*l, ",") }
+func (l *listFlag) Set(v string) error { *l = append(*l, v); return nil }
+
+// requestFlags holds a flag for every GenerationRequest field. Only the flags
+// given on the command line are sent, so presets and server defaults apply
+// to the rest.
+type requestFlags struct {
+	fs *flag.FlagSet
+
+	id, prompt, negPrompt, model, sampler string
+	width, height, steps, batchSize       int
+	cfgScale, strength                    float64
+	seed                                  int64
+	initImage, mask, controlNets          string
+	preset, promptMode                    string
+	styles                                listFlag
+	promptCount                           int
+	webhookURL, webhookSecret             string
+	webhookEvents, extra                  listFlag
+}
+
+func addRequestFlags(fs *flag.FlagSet) *requestFlags {
+	f := &requestFlags{fs: fs}
+	fs.StringVar(&f.id, "id", "", "generation ID (default generated by the server)")
+	fs.StringVar(&f.prompt, "prompt", "", "prompt (default the positional arguments)")
+	fs.StringVar(&f.negPrompt, "negative-prompt", "", "negative prompt")
+	fs.StringVar(&f.model, "model", "", "model name")
+	fs.IntVar(&f.width, "width", 0, "image width")
+	fs.IntVar(&f.height, "height", 0, "image height")
+	fs.IntVar(&f.steps, "steps", 0, "inference steps")
+	fs.Float64Var(&f.cfgScale, "cfg-scale", 0, "classifier-free guidance scale")
+	fs.Int64Var(&f.seed, "seed", 0, "seed, -1 for random")
+	fs.IntVar(&f.batchSize, "batch-size", 0, "images per generation")
+	fs.StringVar(&f.sampler, "sampler", "", "sampler name")
+	fs.StringVar(&f.initImage, "init-image", "", "image file for image-to-image")
+	fs.Float64Var(&f.strength, "strength", 0, "image-to-image denoising strength (0-1)")
+	fs.StringVar(&f.mask, "mask", "", "inpainting mask file, white areas are repainted")
+	fs.StringVar(&f.controlNets, "controlnets", "", "JSON file with a list of ControlNet units")
+	fs.StringVar(&f.preset, "preset", "", "preset name")
+	fs.Var(&f.styles, "style", "style template name (repeatable)")
+	fs.StringVar(&f.promptMode, "prompt-mode", "", `dynamic prompt mode, "random" or "combinatorial"`)
+	fs.IntVar(&f.promptCount, "prompt-count", 0, "number of prompts to expand a dynamic prompt into")
+	fs.StringVar(&f.webhookURL, "webhook-url", "", "webhook called when the generation finishes")
+	fs.StringVar(&f.webhookSecret, "webhook-secret", "", "webhook signing secret")
+	fs.Var(&f.webhookEvents, "webhook-event", "webhook event to deliver (repeatable)")
+	fs.Var(&f.extra, "extra", "extra backend parameter as key=value, value parsed as JSON when possible (repeatable)")
+	return f
+}
+
+// body builds the request body from the flags set on the command line
+func (f *requestFlags) body() (map[string]interface{}, error) {
+	body := make(map[string]interface{})
+	var err error
+	f.fs.Visit(func(fl *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch fl.Name {
+		case "id":
+			body["id"] = f.id
+		case "prompt":
+			body["prompt"] = f.prompt
+		case "negative-prompt":
+			body["negative_prompt"] = f.negPrompt
+		case "model":
+			body["model"] = f.model
+		case "width":
+			body["width"] = f.width
+		case "height":
+			body["height"] = f.height
+		case "steps":
+			body["steps"] = f.steps
+		case "cfg-scale":
+			body["cfg_scale"] = f.cfgScale
+		case "seed":
+			body["seed"] = f.seed
+		case "batch-size":
+			body["batch_size"] = f.batchSize
+		case "sampler":
+			body["sampler"] = f.sampler
+		case "init-image":
+			body["init_image"], err = readBase64(f.initImage)
+		case "strength":
+			body["strength"] = f.strength
+		case "mask":
+			body["mask"], err = readBase64(f.mask)
+		case "controlnets":
+			body["controlnets"], err = readControlNets(f.controlNets)
+		case "preset":
+			body["preset"] = f.preset
+		case "style":
+			body["styles"] = []string(f.styles)
+		case "prompt-mode":
+			body["prompt_mode"] = f.promptMode
+		case "prompt-count":
+			body["prompt_count"] = f.promptCount
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if f.webhookURL != "" {
+		body["webhook"] = map[string]interface{}{
+			"url":    f.webhookURL,
+			"secret": f.webhookSecret,
+			"events": []string(f.webhookEvents),
+		}
+	}
+
+	if len(f.extra) > 0 {
+		extra := make(map[string]interface{}, len(f.extra))
+		for _, kv := range f.extra {
+			key, raw, ok := strings.Cut(kv, "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid --extra %q, want key=value", kv)
+			}
+			var value interface{}
+			if json.Unmarshal([]byte(raw), &value) != nil {
+				value = raw
+			}
+			extra[key] = value
+		}
+		body["extra_params"] = extra
+	}
+
+	return body, nil
+}
+
+// readBase64 reads a file and returns its base64 encoding
+func readBase64(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// readControlNets reads ControlNet units from a JSON file. An "image" that
+// names an existing file is replaced by the file's base64 encoding.
+func readControlNets(name string) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var units []map[string]interface{}
+	if err := json.Unmarshal(data, &units); err != nil {
+		return nil, fmt.Errorf("invalid ControlNet file %s: %w", name, err)
+	}
+
+	for _, unit := range units {
+		image, _ := unit["image"].(string)
+		if image == "" {
+			continue
+		}
+		if !filepath.IsAbs(image) {
+			image = filepath.Join(filepath.Dir(name), image)
+		}
+		if _, err := os.Stat(image); err == nil {
+			if unit["image"], err = readBase64(image); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return units, nil
+}
+
+func runGenerate(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("generate", "[flags] [prompt...]")
+	req := addRequestFlags(fs)
+	wait := fs.Bool("wait", false, "wait for the generation to finish and show progress")
+	out := fs.String("out", "", "directory to download the images to (implies --wait)")
+	key := fs.String("idempotency-key", "", "idempotency key (default random)")
+	quiet := fs.Bool("quiet", false, "don't show the progress bar")
+	if err := parseFlags(fs, args, 0, -1); err != nil {
+		return err
+	}
+
+	body, err := req.body()
+	if err != nil {
+		return err
+	}
+	if _, ok := body["prompt"]; !ok && fs.NArg() > 0 {
+		body["prompt"] = strings.Join(fs.Args(), " ")
+	}
+	if *key != "" {
+		ctx = client.WithIdempotencyKey(ctx, *key)
+	}
+
+	resp, err := c.GenerateBody(ctx, body)
+	if err != nil {
+		return err
+	}
+
+	ids := resp.JobIDs()
+	if !*wait && *out == "" {
+		for _, id := range ids {
+			fmt.Println(id)
+		}
+		return nil
+	}
+
+	var failed int
+	for i, id := range ids {
+		label := ""
+		if len(ids) > 1 {
+			label = fmt.Sprintf("[%d/%d] ", i+1, len(ids))
+		}
+
+		var status *client.GenerationStatus
+		if *quiet {
+			status, err = c.Wait(ctx, id)
+		} else {
+			status, err = waitWithProgress(ctx, c, id, label)
+		}
+		if err != nil {
+			return err
+		}
+
+		if status.Status != client.StatusCompleted {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s%s %s: %s\n", label, id, status.Status, status.Error)
+			continue
+		}
+
+		if *out == "" {
+			fmt.Println(id)
+			continue
+		}
+		files, err := download(ctx, c, status, *out)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			fmt.Println(file)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d generations did not complete", failed, len(ids))
+	}
+	return nil
+}
+
+// waitWithProgress waits for a generation while drawing a progress bar on stderr
+func waitWithProgress(ctx context.Context, c *client.Client, id, label string) (*client.GenerationStatus, error) {
+	updates, errs := c.Watch(ctx, id)
+
+	var last *client.GenerationStatus
+	for status := range updates {
+		status := status
+		last = &status
+		fmt.Fprintf(os.Stderr, "\r\033[K%s%s", label, progressBar(last))
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if last == nil || !last.Status.IsTerminal() {
+		return nil, errors.New("status stream ended before the generation finished")
+	}
+	return last, nil
+}
+
+// progressBar renders a status as a single line
+func progressBar(status *client.GenerationStatus) string {
+	const width = 30
+	filled := int(status.Progress / 100 * width)
+	filled = max(0, min(width, filled))
+
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+	line := fmt.Sprintf("%s %3.0f%% %s", bar, status.Progress, status.Status)
+	if status.TotalSteps > 0 && status.Status == client.StatusProcessing {
+		line += fmt.Sprintf(" (step %d/%d)", status.CurrentStep, status.TotalSteps)
+	}
+	return line
+}
+
+// download saves the images of a finished generation into dir
+func download(ctx context.Context, c *client.Client, status *client.GenerationStatus, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(status.Results))
+	for i, result := range status.Results {
+		name := path.Base(result.ImageURL)
+		if result.ImageURL == "" {
+			name = filepath.Base(result.ImagePath)
+		}
+		if name == "" || name == "." || name == "/" {
+			name = fmt.Sprintf("%s_%d.png", status.ID, i)
+		}
+		file := filepath.Join(dir, name)
+
+		if err := downloadFile(ctx, c, result, file); err != nil {
+			return files, fmt.Errorf("failed to download %s: %w", name, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func downloadFile(ctx context.Context, c *client.Client, result client.GenerationResult, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Download(ctx, result, f); err != nil {
+		f.Close()
+		os.Remove(file)
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,135 @@
+// Command ablerefusal is a command-line client for the AbleRefusal server.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/ablerefusal/ablerefusal/pkg/client"
+)
+
+// command is a CLI subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, c *client.Client, args []string) error
+}
+
+var commands = []command{
+	{"generate", "Submit a generation, optionally waiting for and downloading the images", runGenerate},
+	{"status", "Show the status of a generation", runStatus},
+	{"cancel", "Cancel a queued or running generation", runCancel},
+	{"queue", "List queued and running generations", runQueue},
+	{"models", "List available models", runModels},
+	{"history", "List finished generations", runHistory},
+	{"batch", "Submit prompts from a JSONL or CSV file", runBatch},
+}
+
+// errUsage reports invalid arguments after the usage has been printed
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	global := flag.NewFlagSet("ablerefusal", flag.ContinueOnError)
+	server := global.String("server", envOr("ABLEREFUSAL_SERVER", "http://localhost:8080"), "server URL (env ABLEREFUSAL_SERVER)")
+	apiKey := global.String("api-key", os.Getenv("ABLEREFUSAL_API_KEY"), "API key, required when the server has auth enabled (env ABLEREFUSAL_API_KEY)")
+	global.Usage = func() {
+		out := global.Output()
+		fmt.Fprintln(out, "Usage: ablerefusal [--server URL] [--api-key KEY] <command> [flags]")
+		fmt.Fprintln(out, "\nCommands:")
+		for _, cmd := range commands {
+			fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+		}
+		fmt.Fprintln(out, "\nRun 'ablerefusal <command> -h' for command flags.")
+	}
+
+	if err := global.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+
+	name, args := global.Arg(0), global.Args()[1:]
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "ablerefusal: unknown command %q\n", name)
+		global.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.run(ctx, client.New(*server, client.WithAPIKey(*apiKey)), args); err != nil {
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			os.Exit(0)
+		case errors.Is(err, errUsage):
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "ablerefusal %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet creates the flag set of a subcommand
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: ablerefusal %s %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args and checks the number of positional arguments
+func parseFlags(fs *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() < minArgs || (maxArgs >= 0 && fs.NArg() > maxArgs) {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// truncate shortens s to n runes for table output
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/storage"
+)
+
+// command is a maintenance subcommand of the server binary
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"migrate-outputs", "Move flat output files into content-addressed storage", runMigrateOutputs},
+	{"config", "Check the configuration (check) or show it (print [--effective])", runConfig},
+}
+
+// runCommand runs the named subcommand and returns the exit code
+func runCommand(name string, args []string) int {
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		if name != "-h" && name != "--help" && name != "help" {
+			fmt.Fprintf(os.Stderr, "ablerefusal-backend: unknown command %q\n", name)
+		}
+		fmt.Fprintln(os.Stderr, "Usage: ablerefusal-backend [command] [flags]")
+		fmt.Fprintln(os.Stderr, "\nWithout a command the server is started.\n\nCommands:")
+		for _, cmd := range commands {
+			fmt.Fprintf(os.Stderr, "  %-16s %s\n", cmd.name, cmd.summary)
+		}
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.run(ctx, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "ablerefusal-backend %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// runMigrateOutputs moves outputs stored flat by earlier versions into
+// content-addressed storage, keeping their names
+func runMigrateOutputs(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate-outputs", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be migrated without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	storageManager, err := storage.NewManager(cfg.Storage, cfg.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	report, err := storageManager.MigrateOutputs(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Migrated"
+	if report.DryRun {
+		verb = "Would migrate"
+	}
+	fmt.Printf("%s %d files (%d bytes), %d duplicates\n", verb, report.Files, report.Bytes, report.Deduplicated)
+	for _, message := range report.Errors {
+		fmt.Fprintln(os.Stderr, "  failed:", message)
+	}
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d files could not be migrated", len(report.Errors))
+	}
+	return nil
+}
+
+// runConfig checks or prints the configuration the server would start with
+func runConfig(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: config check | config print [--effective]")
+	}
+	switch args[0] {
+	case "check":
+		return runConfigCheck(args[1:])
+	case "print":
+		return runConfigPrint(args[1:])
+	default:
+		return fmt.Errorf("unknown config command %q, want check or print", args[0])
+	}
+}
+
+// runConfigCheck validates the merged configuration and lists every invalid key
+func runConfigCheck(args []string) error {
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Read()
+	if err != nil {
+		return err
+	}
+	if file := config.File(); file != "" {
+		fmt.Println("Config file:", file)
+	} else {
+		fmt.Println("No config file found, using defaults and environment variables")
+	}
+
+	err = cfg.Validate()
+	var fieldErrs models.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		if err != nil {
+			return err
+		}
+		fmt.Println("Configuration is valid")
+		return nil
+	}
+	for _, fieldErr := range fieldErrs {
+		fmt.Fprintln(os.Stderr, "  "+fieldErr.Error())
+	}
+	return fmt.Errorf("%d invalid settings", len(fieldErrs))
+}
+
+// runConfigPrint shows the config file, or with --effective the merged
+// result of defaults, the file and SD_* environment variables. Secrets are
+// redacted either way.
+func runConfigPrint(args []string) error {
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	effective := fs.Bool("effective", false, "show every setting after defaults and environment variables are applied")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Read()
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	switch file := config.File(); {
+	case *effective:
+		out, err = cfg.Redact().YAML()
+	case file == "":
+		return fmt.Errorf("no config file found, use --effective to show the defaults")
+	default:
+		data, readErr := os.ReadFile(file)
+		if readErr != nil {
+			return readErr
+		}
+		fmt.Println("# " + file)
+		out, err = config.RedactFile(data)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,15 +12,29 @@ import (
 
 	"github.com/ablerefusal/ablerefusal/internal/api/routes"
 	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/export"
+	"github.com/ablerefusal/ablerefusal/internal/generation"
+	"github.com/ablerefusal/ablerefusal/internal/grpcapi"
+	"github.com/ablerefusal/ablerefusal/internal/history"
 	"github.com/ablerefusal/ablerefusal/internal/inference"
+	"github.com/ablerefusal/ablerefusal/internal/library"
 	"github.com/ablerefusal/ablerefusal/internal/logger"
+	"github.com/ablerefusal/ablerefusal/internal/presets"
+	"github.com/ablerefusal/ablerefusal/internal/prompt"
 	"github.com/ablerefusal/ablerefusal/internal/queue"
 	"github.com/ablerefusal/ablerefusal/internal/storage"
+	"github.com/ablerefusal/ablerefusal/internal/webhook"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 func main() {
+	// Run a maintenance command instead of the server if one is given
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
 	// Initialize logger
 	log := logger.New()
 	log.Info("Starting AbleRefusal Server...")
@@ -30,6 +45,21 @@ func main() {
 		log.WithError(err).Fatal("Failed to load configuration")
 	}
 
+	// LOG_LEVEL wins over the config file until the file is edited
+	if os.Getenv("LOG_LEVEL") == "" {
+		if err := logger.SetLevel(log, cfg.Logging.Level); err != nil {
+			log.WithError(err).Warn("Invalid logging level")
+		}
+	}
+
+	// Watch the config file, subscribers apply the settings that can change live
+	watcher := config.NewWatcher(cfg, log)
+	watcher.Subscribe(func(change *config.Change) {
+		if change.Changed("logging.level") {
+			logger.SetLevel(log, change.New.Logging.Level)
+		}
+	})
+
 	// Set gin mode based on environment
 	if cfg.Server.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -54,7 +84,7 @@ func main() {
 	}
 
 	// Initialize storage manager
-	storageManager, err := storage.NewManager(cfg.Storage)
+	storageManager, err := storage.NewManager(cfg.Storage, cfg.Auth)
 	if err != nil {
 		log.WithError(err).Fatal("Failed to initialize storage manager")
 	}
@@ -65,14 +95,67 @@ func main() {
 		log.WithError(err).Fatal("Failed to initialize inference engine")
 	}
 
+	// Initialize preset manager
+	presetManager, err := presets.NewManager(cfg.Presets, cfg.Storage, log)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to initialize preset manager")
+	}
+
+	// Initialize webhook manager
+	webhookManager, err := webhook.NewManager(cfg.Webhooks, cfg.Storage, log)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to initialize webhook manager")
+	}
+	webhookManager.Start(context.Background())
+
+	// Initialize history manager
+	historyManager, err := history.NewManager(cfg.History, cfg.Storage, log)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to initialize history manager")
+	}
+
+	// Initialize library manager
+	libraryManager, err := library.NewManager(cfg.Library, cfg.Storage, log)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to initialize library manager")
+	}
+
+	// Initialize export manager
+	exportManager := export.NewManager(cfg.Storage, historyManager, storageManager, log)
+	exportManager.Start(context.Background())
+
+	// Start storage janitor, history and exports decide which outputs are still wanted
+	references := func() map[string]bool {
+		files := historyManager.OutputFiles()
+		for name, protected := range exportManager.OutputFiles() {
+			files[name] = files[name] || protected
+		}
+		return files
+	}
+	janitor := storage.NewJanitor(cfg.Storage, storageManager.Outputs(), references, log)
+	janitor.Start(context.Background())
+
 	// Initialize queue manager
 	queueManager := queue.NewManager(cfg.Queue, inferenceEngine, storageManager, log)
+	queueManager.AddListener(webhookManager.Notify)
+	queueManager.AddListener(historyManager.Record)
+	watcher.Subscribe(func(change *config.Change) {
+		if change.Changed("queue") {
+			queueManager.UpdateConfig(change.New.Queue)
+		}
+	})
 	
+	// Initialize generation manager
+	generationManager := generation.NewManager(cfg, queueManager, inferenceEngine, presetManager, historyManager, prompt.NewProcessor(cfg.Prompts), log)
+
 	// Start queue processor
 	go queueManager.StartProcessor(context.Background())
 
 	// Setup routes
-	router := routes.Setup(cfg, queueManager, storageManager, log)
+	router := routes.Setup(cfg, watcher, queueManager, storageManager, inferenceEngine, presetManager, webhookManager, generationManager, historyManager, libraryManager, exportManager, janitor, log)
+
+	// Subscribers are in place, apply config edits from now on
+	watcher.Start()
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -94,6 +177,24 @@ func main() {
 		}
 	}()
 
+	// Start gRPC server alongside the REST API
+	var grpcServer *grpc.Server
+	if cfg.Server.GRPCPort > 0 {
+		grpcAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.GRPCPort)
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to listen for gRPC")
+		}
+		grpcServer = grpcapi.NewServer(queueManager, generationManager, log).Register(grpcapi.Auth(cfg.Auth)...)
+
+		go func() {
+			log.WithField("port", cfg.Server.GRPCPort).Info("gRPC server started")
+			if err := grpcServer.Serve(listener); err != nil {
+				log.WithError(err).Fatal("Failed to start gRPC server")
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -113,9 +214,35 @@ func main() {
 		}
 	}
 
+	// Open WatchJob streams only end with their job, so stop the gRPC
+	// server forcibly if it has not drained by the deadline
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		if grpcServer == nil {
+			return
+		}
+		drained := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			log.Warn("gRPC server did not stop in time, closing open streams")
+			grpcServer.Stop()
+		}
+	}()
+
 	if err := srv.Shutdown(ctx); err != nil {
 		log.WithError(err).Fatal("Server forced to shutdown")
 	}
+	<-grpcStopped
+
+	// Save what the background writers have not written yet
+	historyManager.Flush()
+	webhookManager.Flush()
 
 	log.Info("Server exited")
 }
\ No newline at end of file
@@ -0,0 +1,530 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/generation"
+	"github.com/ablerefusal/ablerefusal/internal/inference"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/queue"
+	"github.com/ablerefusal/ablerefusal/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// A1111Handler implements the subset of the Automatic1111 web UI API
+// (/sdapi/v1) used by common plugins and scripts
+type A1111Handler struct {
+	queue      queue.Manager
+	generation generation.Manager
+	inference  inference.Engine
+	storage    storage.Manager
+	config     *config.Config
+	logger     *logrus.Logger
+}
+
+// a1111Request is the txt2img and img2img payload. Fields this backend
+// does not support are accepted and ignored.
+type a1111Request struct {
+	Prompt            string                 `json:"prompt"`
+	NegativePrompt    string                 `json:"negative_prompt"`
+	Styles            []string               `json:"styles"`
+	Seed              int64                  `json:"seed"`
+	SamplerName       string                 `json:"sampler_name"`
+	SamplerIndex      string                 `json:"sampler_index"` // Deprecated alias of sampler_name
+	Scheduler         string                 `json:"scheduler"`
+	BatchSize         int                    `json:"batch_size"`
+	NIter             int                    `json:"n_iter"`
+	Steps             int                    `json:"steps"`
+	CFGScale          float32                `json:"cfg_scale"`
+	Width             int                    `json:"width"`
+	Height            int                    `json:"height"`
+	DenoisingStrength float32                `json:"denoising_strength"`
+	OverrideSettings  map[string]interface{} `json:"override_settings,omitempty"`
+	SendImages        bool                   `json:"send_images"`
+	// img2img only
+	InitImages []string `json:"init_images,omitempty"`
+	Mask       string   `json:"mask,omitempty"`
+}
+
+// a1111Info is the generation summary returned JSON-encoded in the "info" field
+type a1111Info struct {
+	Prompt             string   `json:"prompt"`
+	AllPrompts         []string `json:"all_prompts"`
+	NegativePrompt     string   `json:"negative_prompt"`
+	AllNegativePrompts []string `json:"all_negative_prompts"`
+	Seed               int64    `json:"seed"`
+	AllSeeds           []int64  `json:"all_seeds"`
+	Subseed            int64    `json:"subseed"`
+	AllSubseeds        []int64  `json:"all_subseeds"`
+	Width              int      `json:"width"`
+	Height             int      `json:"height"`
+	SamplerName        string   `json:"sampler_name"`
+	CFGScale           float32  `json:"cfg_scale"`
+	Steps              int      `json:"steps"`
+	BatchSize          int      `json:"batch_size"`
+	SDModelName        string   `json:"sd_model_name"`
+	DenoisingStrength  float32  `json:"denoising_strength"`
+	Styles             []string `json:"styles"`
+	JobTimestamp       string   `json:"job_timestamp"`
+	Infotexts          []string `json:"infotexts"`
+}
+
+// checkpointHash matches the " [hash]" suffix A1111 appends to checkpoint titles
+var checkpointHash = regexp.MustCompile(`\s*\[[0-9a-fA-F]+\]$`)
+
+// NewA1111Handler creates a new Automatic1111 compatibility handler
+func NewA1111Handler(queue queue.Manager, generation generation.Manager, inference inference.Engine, storage storage.Manager, cfg *config.Config, logger *logrus.Logger) *A1111Handler {
+	return &A1111Handler{
+		queue:      queue,
+		generation: generation,
+		inference:  inference,
+		storage:    storage,
+		config:     cfg,
+		logger:     logger,
+	}
+}
+
+// Txt2Img handles POST /sdapi/v1/txt2img
+func (h *A1111Handler) Txt2Img(c *gin.Context) {
+	payload, ok := h.bindPayload(c)
+	if !ok {
+		return
+	}
+
+	h.generate(c, payload)
+}
+
+// Img2Img handles POST /sdapi/v1/img2img
+func (h *A1111Handler) Img2Img(c *gin.Context) {
+	payload, ok := h.bindPayload(c)
+	if !ok {
+		return
+	}
+
+	if len(payload.InitImages) == 0 {
+		h.validationError(c, "init_images", "at least one init image is required")
+		return
+	}
+
+	h.generate(c, payload)
+}
+
+// Progress handles GET /sdapi/v1/progress, reporting the caller's jobs
+func (h *A1111Handler) Progress(c *gin.Context) {
+	owner := c.GetString("api_key_id")
+	items, err := h.queue.GetQueue(owner)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get queue")
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	state := gin.H{
+		"skipped":        false,
+		"interrupted":    false,
+		"job":            "",
+		"job_count":      len(items),
+		"job_timestamp":  "0",
+		"job_no":         0,
+		"sampling_step":  0,
+		"sampling_steps": 0,
+	}
+	progress, eta := 0.0, 0.0
+
+	for _, item := range items {
+		status, err := h.queue.GetStatus(item.Request.ID, owner)
+		if err != nil || status.Status != models.StatusProcessing {
+			continue
+		}
+
+		progress = status.Progress / 100
+		if status.StartedAt != nil {
+			state["job_timestamp"] = status.StartedAt.Format("20060102150405")
+			if progress > 0 {
+				elapsed := time.Since(*status.StartedAt).Seconds()
+				eta = elapsed/progress - elapsed
+			}
+		}
+		state["job"] = status.ID
+		state["sampling_step"] = status.CurrentStep
+		state["sampling_steps"] = status.TotalSteps
+		break
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"progress":      progress,
+		"eta_relative":  eta,
+		"state":         state,
+		"current_image": nil,
+		"textinfo":      nil,
+	})
+}
+
+// Interrupt handles POST /sdapi/v1/interrupt by cancelling the caller's
+// running generations
+func (h *A1111Handler) Interrupt(c *gin.Context) {
+	owner := c.GetString("api_key_id")
+	items, err := h.queue.GetQueue(owner)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get queue")
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, item := range items {
+		if item.Status.Status != models.StatusProcessing {
+			continue
+		}
+		if err := h.queue.Cancel(item.Request.ID, owner); err != nil {
+			h.logger.WithError(err).WithField("request_id", item.Request.ID).Warn("Failed to interrupt generation")
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// SDModels handles GET /sdapi/v1/sd-models
+func (h *A1111Handler) SDModels(c *gin.Context) {
+	checkpoints := make([]gin.H, 0, len(h.config.Models.Available))
+	for _, model := range h.config.Models.Available {
+		checkpoints = append(checkpoints, gin.H{
+			"title":      model.Name,
+			"model_name": model.Name,
+			"hash":       nil,
+			"sha256":     nil,
+			"filename":   model.Path,
+			"config":     nil,
+		})
+	}
+
+	c.JSON(http.StatusOK, checkpoints)
+}
+
+// Samplers handles GET /sdapi/v1/samplers
+func (h *A1111Handler) Samplers(c *gin.Context) {
+	catalogue, err := h.inference.ListSamplers()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list samplers")
+		h.error(c, http.StatusBadGateway, err)
+		return
+	}
+
+	samplers := make([]gin.H, 0, len(catalogue.Samplers))
+	for _, sampler := range catalogue.Samplers {
+		aliases := sampler.Aliases
+		if aliases == nil {
+			aliases = []string{}
+		}
+		samplers = append(samplers, gin.H{
+			"name":    sampler.Name,
+			"aliases": aliases,
+			"options": gin.H{},
+		})
+	}
+
+	c.JSON(http.StatusOK, samplers)
+}
+
+// bindPayload decodes an A1111 payload over the backend defaults
+func (h *A1111Handler) bindPayload(c *gin.Context) (*a1111Request, bool) {
+	defaults := models.NewGenerationRequest()
+	payload := &a1111Request{
+		Seed:              -1,
+		BatchSize:         1,
+		NIter:             1,
+		Steps:             defaults.Steps,
+		CFGScale:          defaults.CFGScale,
+		Width:             defaults.Width,
+		Height:            defaults.Height,
+		DenoisingStrength: defaults.Strength,
+		SendImages:        true,
+	}
+
+	if err := c.ShouldBindJSON(payload); err != nil {
+		h.logger.WithError(err).Error("Failed to bind A1111 request")
+		h.validationError(c, "body", err.Error())
+		return nil, false
+	}
+
+	return payload, true
+}
+
+// generate queues one request per iteration, waits for all of them and
+// writes the A1111 response
+func (h *A1111Handler) generate(c *gin.Context, payload *a1111Request) {
+	var queued []*models.GenerationRequest
+	for i := 0; i < max(payload.NIter, 1); i++ {
+		req := h.toGenerationRequest(payload)
+		req.Owner = c.GetString("api_key_id")
+		if payload.Seed != -1 {
+			req.Seed = payload.Seed + int64(i*req.BatchSize)
+		}
+
+		reqs, _, err := h.generation.Submit(req)
+		if err != nil {
+			for _, r := range queued {
+				h.queue.Cancel(r.ID, r.Owner)
+			}
+			h.submitError(c, err)
+			return
+		}
+		queued = append(queued, reqs...)
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id": queued[0].ID,
+		"count":      len(queued),
+	}).Info("A1111 generation request queued")
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), waitTimeout(c, h.config, 0))
+	defer cancel()
+
+	statuses, err := h.generation.Wait(ctx, queued)
+	if err != nil {
+		// The jobs keep running, their results can still be fetched through
+		// the native API and history
+		ids := make([]string, len(queued))
+		for i, r := range queued {
+			ids[i] = r.ID
+		}
+		h.logger.WithError(err).WithField("request_id", queued[0].ID).Warn("A1111 generation did not finish in time")
+		h.error(c, http.StatusGatewayTimeout, fmt.Errorf("generation did not finish in time and is still running as %s: %w", strings.Join(ids, ", "), err))
+		return
+	}
+
+	info := &a1111Info{
+		NegativePrompt:    payload.NegativePrompt,
+		Seed:              -1,
+		Subseed:           -1,
+		Width:             payload.Width,
+		Height:            payload.Height,
+		CFGScale:          payload.CFGScale,
+		Steps:             payload.Steps,
+		BatchSize:         payload.BatchSize,
+		SDModelName:       queued[0].Model,
+		SamplerName:       queued[0].Sampler,
+		DenoisingStrength: payload.DenoisingStrength,
+		Styles:            payload.Styles,
+		JobTimestamp:      time.Now().Format("20060102150405"),
+		Prompt:            queued[0].Prompt,
+	}
+	images := make([]string, 0)
+
+	for i, status := range statuses {
+		// Interrupted and failed jobs have no images to return
+		if err := jobError(status); err != nil {
+			h.apiError(c, err)
+			return
+		}
+
+		req := queued[i]
+		for _, result := range status.Results {
+			if info.Seed == -1 {
+				info.Seed = result.Seed
+			}
+			info.AllPrompts = append(info.AllPrompts, req.Prompt)
+			info.AllNegativePrompts = append(info.AllNegativePrompts, req.NegPrompt)
+			info.AllSeeds = append(info.AllSeeds, result.Seed)
+			info.AllSubseeds = append(info.AllSubseeds, -1)
+			info.Infotexts = append(info.Infotexts, a1111Infotext(req, result))
+
+			if !payload.SendImages {
+				continue
+			}
+			data, err := loadResultImage(c.Request.Context(), h.storage, result)
+			if err != nil {
+				h.logger.WithError(err).WithField("request_id", req.ID).Error("Failed to read generated image")
+				h.error(c, http.StatusInternalServerError, err)
+				return
+			}
+			images = append(images, base64.StdEncoding.EncodeToString(data))
+		}
+	}
+
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"images":     images,
+		"parameters": payload,
+		"info":       string(infoJSON),
+	})
+}
+
+// toGenerationRequest translates an A1111 payload into a generation request
+func (h *A1111Handler) toGenerationRequest(payload *a1111Request) *models.GenerationRequest {
+	req := models.NewGenerationRequest()
+	req.ID = uuid.New().String()
+	req.Prompt = payload.Prompt
+	req.NegPrompt = payload.NegativePrompt
+	req.Styles = payload.Styles
+	req.Seed = payload.Seed
+	req.BatchSize = payload.BatchSize
+	req.Steps = payload.Steps
+	req.CFGScale = payload.CFGScale
+	req.Width = payload.Width
+	req.Height = payload.Height
+	req.Sampler = h.resolveSampler(payload)
+
+	if checkpoint, ok := payload.OverrideSettings["sd_model_checkpoint"].(string); ok && checkpoint != "" {
+		req.Model = h.resolveCheckpoint(checkpoint)
+	}
+
+	if len(payload.InitImages) > 0 {
+		req.InitImage = payload.InitImages[0]
+		req.Strength = payload.DenoisingStrength
+		req.Mask = payload.Mask
+	}
+
+	return req
+}
+
+// resolveSampler combines A1111's separate sampler and scheduler fields
+// ("DPM++ 2M" + "Karras") when the catalogue knows the combination
+func (h *A1111Handler) resolveSampler(payload *a1111Request) string {
+	name := payload.SamplerName
+	if name == "" {
+		name = payload.SamplerIndex
+	}
+	if name == "" {
+		return models.NewGenerationRequest().Sampler
+	}
+
+	scheduler := payload.Scheduler
+	if scheduler == "" || strings.EqualFold(scheduler, "automatic") {
+		return name
+	}
+
+	catalogue, err := h.inference.ListSamplers()
+	if err != nil {
+		catalogue = models.DefaultSamplerCatalogue()
+	}
+	if combined, err := catalogue.Resolve(name + " " + scheduler); err == nil {
+		return combined
+	}
+	return name
+}
+
+// resolveCheckpoint maps an A1111 checkpoint title ("sd15.safetensors [6ce0161689]")
+// to a configured model name
+func (h *A1111Handler) resolveCheckpoint(checkpoint string) string {
+	title := checkpointHash.ReplaceAllString(checkpoint, "")
+	stem := strings.TrimSuffix(title, filepath.Ext(title))
+	for _, model := range h.config.Models.Available {
+		if model.Name == title || model.Name == stem || filepath.Base(model.Path) == title {
+			return model.Name
+		}
+	}
+	return stem
+}
+
+// submitError writes the A1111-shaped response for a failed submission
+func (h *A1111Handler) submitError(c *gin.Context, err error) {
+	h.logger.WithError(err).Error("Failed to queue A1111 generation")
+
+	var validationErrs models.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrs):
+		detail := make([]gin.H, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			detail[i] = a1111FieldError(fieldErr.Field, fieldErr.Message)
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"detail": detail})
+	case errors.Is(err, models.ErrQueueFull):
+		h.error(c, http.StatusServiceUnavailable, err)
+	case errors.Is(err, models.ErrStorageFull):
+		h.error(c, http.StatusInsufficientStorage, err)
+	default:
+		h.error(c, http.StatusBadRequest, err)
+	}
+}
+
+// validationError writes a FastAPI-style 422 response for a single field
+func (h *A1111Handler) validationError(c *gin.Context, field, message string) {
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"detail": []gin.H{a1111FieldError(field, message)},
+	})
+}
+
+// error writes an A1111-style error response
+func (h *A1111Handler) error(c *gin.Context, status int, err error) {
+	c.JSON(status, gin.H{
+		"error":  http.StatusText(status),
+		"detail": err.Error(),
+	})
+}
+
+// apiError writes an A1111-style error response for an API error, adding
+// its code
+func (h *A1111Handler) apiError(c *gin.Context, err *models.Error) {
+	c.JSON(err.Status, gin.H{
+		"error":  http.StatusText(err.Status),
+		"detail": err.Message,
+		"code":   err.Code,
+	})
+}
+
+// jobError returns the error of a generation that was cancelled or failed,
+// or nil when it completed
+func jobError(status *models.GenerationStatus) *models.Error {
+	switch status.Status {
+	case models.StatusCancelled:
+		return models.AsError(models.ErrGenerationCancelled)
+	case models.StatusFailed:
+		message := status.Error
+		if message == "" {
+			message = "Generation failed"
+		}
+		return models.NewError(models.CodeInferenceFailed, http.StatusInternalServerError, message)
+	}
+	return nil
+}
+
+func a1111FieldError(field, message string) gin.H {
+	return gin.H{
+		"loc":  []string{"body", field},
+		"msg":  message,
+		"type": "value_error",
+	}
+}
+
+// a1111Infotext renders the parameters line A1111 embeds in its images
+func a1111Infotext(req *models.GenerationRequest, result models.GenerationResult) string {
+	var b strings.Builder
+	b.WriteString(req.Prompt)
+	if req.NegPrompt != "" {
+		b.WriteString("\nNegative prompt: " + req.NegPrompt)
+	}
+	fmt.Fprintf(&b, "\nSteps: %d, Sampler: %s, CFG scale: %g, Seed: %d, Size: %dx%d, Model: %s",
+		req.Steps, req.Sampler, req.CFGScale, result.Seed, result.Width, result.Height, req.Model)
+	if req.InitImage != "" {
+		fmt.Fprintf(&b, ", Denoising strength: %g", req.Strength)
+	}
+	return b.String()
+}
+
+// loadResultImage reads a generated image from output storage
+func loadResultImage(ctx context.Context, storage storage.Manager, result models.GenerationResult) ([]byte, error) {
+	image, _, err := storage.OpenOutput(ctx, filepath.Base(result.ImagePath))
+	if err != nil {
+		return nil, err
+	}
+	defer image.Close()
+	return io.ReadAll(image)
+}
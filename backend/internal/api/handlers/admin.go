@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminHandler handles maintenance endpoints
+type AdminHandler struct {
+	janitor storage.Janitor
+	logger  *logrus.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(janitor storage.Janitor, logger *logrus.Logger) *AdminHandler {
+	return &AdminHandler{
+		janitor: janitor,
+		logger:  logger,
+	}
+}
+
+// Cleanup handles POST /api/v1/admin/cleanup
+func (h *AdminHandler) Cleanup(c *gin.Context) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.Error(models.InvalidRequest("Invalid dry_run"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	report, err := h.janitor.Run(dryRun)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// CleanupStats handles GET /api/v1/admin/cleanup
+func (h *AdminHandler) CleanupStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.janitor.Stats())
+}
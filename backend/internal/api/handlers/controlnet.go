@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ablerefusal/ablerefusal/internal/inference"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ControlNetHandler handles ControlNet endpoints
+type ControlNetHandler struct {
+	inference inference.Engine
+	logger    *logrus.Logger
+}
+
+// NewControlNetHandler creates a new ControlNet handler
+func NewControlNetHandler(inference inference.Engine, logger *logrus.Logger) *ControlNetHandler {
+	return &ControlNetHandler{
+		inference: inference,
+		logger:    logger,
+	}
+}
+
+// List handles GET /api/v1/controlnets
+func (h *ControlNetHandler) List(c *gin.Context) {
+	controlNets, err := h.inference.ListControlNets()
+	if err != nil {
+		c.Error(models.NewError(models.CodeInferenceUnavailable, http.StatusBadGateway, "Failed to list controlnets").Wrap(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"controlnets": controlNets,
+		"count":       len(controlNets),
+	})
+}
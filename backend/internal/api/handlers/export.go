@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/export"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ExportHandler handles export and import endpoints
+type ExportHandler struct {
+	exports export.Manager
+	config  config.StorageConfig
+	logger  *logrus.Logger
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exports export.Manager, cfg config.StorageConfig, logger *logrus.Logger) *ExportHandler {
+	return &ExportHandler{
+		exports: exports,
+		config:  cfg,
+		logger:  logger,
+	}
+}
+
+// Create handles POST /api/v1/exports
+func (h *ExportHandler) Create(c *gin.Context) {
+	var filter models.ExportFilter
+	if err := c.ShouldBindJSON(&filter); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(models.InvalidRequest(err.Error()))
+		return
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.Error(models.InvalidRequest("from must be before to"))
+		return
+	}
+
+	job, err := h.exports.Create(filter, c.GetString("api_key_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.logger.WithField("export_id", job.ID).Info("Export queued")
+	c.JSON(http.StatusAccepted, job)
+}
+
+// List handles GET /api/v1/exports
+func (h *ExportHandler) List(c *gin.Context) {
+	jobs := h.exports.List(c.GetString("api_key_id"))
+	c.JSON(http.StatusOK, gin.H{
+		"exports": jobs,
+		"count":   len(jobs),
+	})
+}
+
+// Get handles GET /api/v1/exports/:id
+func (h *ExportHandler) Get(c *gin.Context) {
+	job, err := h.exports.Get(c.Param("id"), c.GetString("api_key_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// Download handles GET /api/v1/exports/:id/download
+func (h *ExportHandler) Download(c *gin.Context) {
+	reader, info, err := h.exports.Open(c.Request.Context(), c.Param("id"), c.GetString("api_key_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, info.Size, "application/zip", reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="export-%s.zip"`, c.Param("id")),
+	})
+}
+
+// Import handles POST /api/v1/imports. The archive is sent as the
+// "archive" field of a multipart form or as an application/zip body.
+func (h *ExportHandler) Import(c *gin.Context) {
+	body := io.Reader(c.Request.Body)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("archive")
+		if err != nil {
+			c.Error(models.InvalidRequest("Missing archive file"))
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.Error(models.InvalidRequest("Failed to read archive").Wrap(err))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	// zip needs random access, so the upload is spooled to the temp directory
+	tmp, err := os.CreateTemp(h.config.TempDir, ".import-*.zip")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	limit := h.config.MaxFileSize
+	if limit <= 0 {
+		limit = 1<<63 - 2
+	}
+	size, err := io.Copy(tmp, io.LimitReader(body, limit+1))
+	if err != nil {
+		c.Error(models.InvalidRequest("Failed to read archive").Wrap(err))
+		return
+	}
+	if size > limit {
+		c.Error(fmt.Errorf("%w: archive exceeds %d bytes", models.ErrFileTooLarge, limit))
+		return
+	}
+
+	report, err := h.exports.Import(c.Request.Context(), tmp, size, c.GetString("api_key_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
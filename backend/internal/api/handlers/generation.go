@@ -1,99 +1,163 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/generation"
+	"github.com/ablerefusal/ablerefusal/internal/imaging"
 	"github.com/ablerefusal/ablerefusal/internal/models"
 	"github.com/ablerefusal/ablerefusal/internal/queue"
+	"github.com/ablerefusal/ablerefusal/internal/storage"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 // GenerationHandler handles generation endpoints
 type GenerationHandler struct {
-	queue  queue.Manager
-	logger *logrus.Logger
+	queue      queue.Manager
+	generation generation.Manager
+	storage    storage.Manager
+	config     *config.Config
+	logger     *logrus.Logger
 }
 
 // NewGenerationHandler creates a new generation handler
-func NewGenerationHandler(queue queue.Manager, logger *logrus.Logger) *GenerationHandler {
+func NewGenerationHandler(queue queue.Manager, generation generation.Manager, storage storage.Manager, cfg *config.Config, logger *logrus.Logger) *GenerationHandler {
 	return &GenerationHandler{
-		queue:  queue,
-		logger: logger,
+		queue:      queue,
+		generation: generation,
+		storage:    storage,
+		config:     cfg,
+		logger:     logger,
 	}
 }
 
 // Generate handles POST /api/v1/generate
 func (h *GenerationHandler) Generate(c *gin.Context) {
-	// Create request with defaults
-	req := models.NewGenerationRequest()
-	
-	// Bind JSON request (this will override defaults with provided values)
-	if err := c.BindJSON(&req); err != nil {
-		h.logger.WithError(err).Error("Failed to bind generation request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	body, err := c.GetRawData()
+	if err != nil {
+		c.Error(models.InvalidRequest("Failed to read request body").Wrap(err))
 		return
 	}
 
-	// Ensure ID is set
-	if req.ID == "" {
-		req.ID = uuid.New().String()
-	}
-	
-	// Ensure model is set
-	if req.Model == "" {
-		req.Model = "sd15"
-	}
-
-	// Validate request
-	if err := req.Validate(); err != nil {
-		h.logger.WithError(err).Error("Invalid generation request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Build request from defaults, the named preset and the explicit fields
+	req, err := h.generation.BuildRequest(body)
+	if err != nil {
+		c.Error(requestError(err))
 		return
 	}
+	req.Owner = c.GetString("api_key_id")
 
-	// Add to queue
-	position, err := h.queue.Enqueue(req)
+	// Validate, expand and queue the request
+	reqs, position, err := h.generation.Submit(req)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to enqueue generation")
-		if err == models.ErrQueueFull {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Queue is full, please try again later"})
+		if errors.Is(err, models.ErrQueueFull) || errors.Is(err, models.ErrStorageFull) {
+			c.Error(err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue generation"})
+		c.Error(requestError(err))
 		return
 	}
 
 	h.logger.WithFields(logrus.Fields{
 		"request_id": req.ID,
 		"position":   position,
+		"count":      len(reqs),
 	}).Info("Generation request queued")
 
+	// Synchronous mode: block until the job finishes or the wait expires
+	if wait, _ := strconv.ParseBool(c.Query("wait")); wait {
+		if h.respondWhenDone(c, reqs) {
+			return
+		}
+	}
+
 	// Return response
-	c.JSON(http.StatusAccepted, gin.H{
+	response := gin.H{
 		"id":       req.ID,
 		"status":   "queued",
 		"position": position,
 		"message":  "Generation request queued successfully",
+	}
+	if len(reqs) > 1 {
+		ids := make([]string, len(reqs))
+		for i, r := range reqs {
+			ids[i] = r.ID
+		}
+		response["ids"] = ids
+	}
+	c.JSON(http.StatusAccepted, response)
+}
+
+// respondWhenDone waits for the queued requests to finish and writes the final
+// status, or the first image when the client prefers an image. It returns
+// false without writing anything if the wait expires.
+func (h *GenerationHandler) respondWhenDone(c *gin.Context, reqs []*models.GenerationRequest) bool {
+	var requested time.Duration
+	if seconds, err := strconv.ParseFloat(c.Query("timeout"), 64); err == nil && seconds > 0 {
+		requested = time.Duration(seconds * float64(time.Second))
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), waitTimeout(c, h.config, requested))
+	defer cancel()
+
+	statuses, err := h.generation.Wait(ctx, reqs)
+	if err != nil {
+		h.logger.WithField("request_id", reqs[0].ID).Info("Wait expired, responding asynchronously")
+		return false
+	}
+
+	first := statuses[0]
+	if first.Status == models.StatusCompleted && len(first.Results) > 0 {
+		name := filepath.Base(first.Results[0].ImagePath)
+		offers := imageOffers(name)
+		accepted := c.NegotiateFormat(offers...)
+		if accepted == "" {
+			c.Error(models.NewError(models.CodeInvalidRequest, http.StatusNotAcceptable, "Accept allows none of the available response types").
+				WithDetail("id", first.ID).WithDetail("available", offers))
+			return true
+		}
+		if accepted != gin.MIMEJSON {
+			h.respondImage(c, first, name, accepted)
+			return true
+		}
+	}
+
+	if len(statuses) == 1 {
+		c.JSON(http.StatusOK, first)
+		return true
+	}
+
+	ids := make([]string, len(reqs))
+	for i, req := range reqs {
+		ids[i] = req.ID
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"id":       first.ID,
+		"ids":      ids,
+		"statuses": statuses,
 	})
+	return true
 }
 
 // Cancel handles POST /api/v1/generate/:id/cancel
 func (h *GenerationHandler) Cancel(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing generation ID"})
+		c.Error(models.InvalidRequest("Missing generation ID"))
 		return
 	}
 
-	if err := h.queue.Cancel(id); err != nil {
-		if err == models.ErrGenerationNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Generation not found"})
-			return
-		}
-		h.logger.WithError(err).Error("Failed to cancel generation")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel generation"})
+	if err := h.queue.Cancel(id, c.GetString("api_key_id")); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -103,4 +167,89 @@ func (h *GenerationHandler) Cancel(c *gin.Context) {
 		"status":  "cancelled",
 		"message": "Generation cancelled successfully",
 	})
-}
\ No newline at end of file
+}
+
+// respondImage writes the first image of a finished generation in the
+// negotiated content type, converting the stored output when they differ
+func (h *GenerationHandler) respondImage(c *gin.Context, status *models.GenerationStatus, name, contentType string) {
+	var (
+		image io.ReadCloser
+		info  *storage.ObjectInfo
+		err   error
+	)
+	format, _ := models.ParseImageFormat(strings.TrimPrefix(contentType, "image/"))
+	if stored, ok := imaging.FormatOf(name); ok && stored == format {
+		image, info, err = h.storage.OpenOutput(c.Request.Context(), name)
+	} else {
+		image, info, err = h.storage.OpenVariant(c.Request.Context(), name, storage.Variant{Format: format})
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidOutputFormat) {
+			c.Error(models.AsError(err).WithDetail("id", status.ID))
+			return
+		}
+		c.Error(models.NewError(models.CodeImageNotFound, http.StatusNotFound, "Generated image not found in output storage").WithDetail("id", status.ID).Wrap(err))
+		return
+	}
+	defer image.Close()
+
+	c.Header("X-Generation-ID", status.ID)
+	c.Header("X-Image-Count", strconv.Itoa(len(status.Results)))
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, image, nil)
+}
+
+// imageOffers lists the response types of a synchronous generation: JSON,
+// the stored output's type, then every type it can be converted to
+func imageOffers(name string) []string {
+	offers := []string{gin.MIMEJSON}
+	stored, ok := imaging.FormatOf(name)
+	if ok {
+		offers = append(offers, imaging.ContentType(stored))
+	}
+	for _, format := range imaging.Formats() {
+		if !ok || format != stored {
+			offers = append(offers, imaging.ContentType(format))
+		}
+	}
+	return offers
+}
+
+// waitTimeout returns how long a synchronous request may block: the requested
+// duration if set, capped by server.max_wait. The connection's write deadline
+// is moved past the wait, leaving the usual write timeout to send the
+// response. Writers that cannot extend it cap the wait by the write timeout.
+func waitTimeout(c *gin.Context, cfg *config.Config, requested time.Duration) time.Duration {
+	maxWait := time.Duration(cfg.Server.MaxWait) * time.Second
+	if requested > 0 && requested < maxWait {
+		maxWait = requested
+	}
+
+	writeTimeout := time.Duration(cfg.Server.WriteTimeout) * time.Second
+	if writeTimeout <= 0 {
+		return maxWait
+	}
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(maxWait + writeTimeout)); err == nil {
+		return maxWait
+	}
+
+	// Leave time to write the response before the server's write timeout
+	if writeTimeout -= time.Second; writeTimeout > 0 && maxWait > writeTimeout {
+		maxWait = writeTimeout
+	}
+
+	return maxWait
+}
+
+// requestError converts an error from building or validating a generation
+// request into an API error. Unknown presets, styles and models referenced
+// by the request are the client's mistake, so they become 400.
+func requestError(err error) *models.Error {
+	apiErr := models.AsError(err)
+	switch {
+	case apiErr.Code == models.CodeValidationFailed:
+		apiErr.Message = "Invalid generation request"
+	case apiErr.Status == http.StatusNotFound:
+		return apiErr.WithStatus(http.StatusBadRequest)
+	}
+	return apiErr
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ablerefusal/ablerefusal/internal/history"
+	"github.com/ablerefusal/ablerefusal/internal/library"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// HistoryHandler handles generation history endpoints
+type HistoryHandler struct {
+	history history.Manager
+	library library.Manager
+	storage storage.Manager
+	logger  *logrus.Logger
+}
+
+// NewHistoryHandler creates a new history handler
+func NewHistoryHandler(history history.Manager, library library.Manager, storage storage.Manager, logger *logrus.Logger) *HistoryHandler {
+	return &HistoryHandler{
+		history: history,
+		library: library,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// List handles GET /api/v1/history
+func (h *HistoryHandler) List(c *gin.Context) {
+	filter := history.Filter{
+		Owner:  c.GetString("api_key_id"),
+		Status: models.GenerationStatusType(c.Query("status")),
+		Query:  c.Query("q"),
+		Model:  c.Query("model"),
+		Tag:    models.NormalizeTag(c.Query("tag")),
+		Limit:  50,
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			c.Error(models.InvalidRequest("Invalid limit"))
+			return
+		}
+		filter.Limit = n
+	}
+	if offset := c.Query("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			c.Error(models.InvalidRequest("Invalid offset"))
+			return
+		}
+		filter.Offset = n
+	}
+	if favourite := c.Query("favourite"); favourite != "" {
+		value, err := strconv.ParseBool(favourite)
+		if err != nil {
+			c.Error(models.InvalidRequest("Invalid favourite"))
+			return
+		}
+		filter.Favourite = value
+	}
+	if rating := c.Query("min_rating"); rating != "" {
+		n, err := strconv.Atoi(rating)
+		if err != nil || n < 1 || n > models.MaxRating {
+			c.Error(models.InvalidRequest("Invalid min_rating"))
+			return
+		}
+		filter.MinRating = n
+	}
+	if id := c.Query("collection"); id != "" {
+		collection, err := h.library.Get(id, filter.Owner)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		filter.IDs = collection.EntryIDs
+	}
+
+	entries, total := h.history.List(filter)
+	for _, entry := range entries {
+		h.refreshURLs(entry)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+		"total":   total,
+	})
+}
+
+// Get handles GET /api/v1/history/:id
+func (h *HistoryHandler) Get(c *gin.Context) {
+	entry, err := h.history.Get(c.Param("id"), c.GetString("api_key_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.refreshURLs(entry)
+	c.JSON(http.StatusOK, entry)
+}
+
+// refreshURLs reissues the image URLs of an entry snapshot, since signed
+// URLs recorded at completion expire while the entry is kept
+func (h *HistoryHandler) refreshURLs(entry *models.HistoryEntry) {
+	results := make([]models.GenerationResult, len(entry.Results))
+	for i, result := range entry.Results {
+		if result.ImagePath != "" {
+			result.ImageURL = h.storage.OutputPath(filepath.Base(result.ImagePath))
+		}
+		thumbnails := make([]models.Thumbnail, len(result.Thumbnails))
+		for j, thumbnail := range result.Thumbnails {
+			thumbnail.ImageURL = h.storage.OutputPath(thumbnail.ImagePath)
+			thumbnails[j] = thumbnail
+		}
+		if len(thumbnails) > 0 {
+			result.Thumbnails = thumbnails
+		}
+		results[i] = result
+	}
+	entry.Results = results
+}
+
+// Pin handles PUT /api/v1/history/:id/pin
+func (h *HistoryHandler) Pin(c *gin.Context) {
+	h.setPinned(c, true)
+}
+
+// Unpin handles DELETE /api/v1/history/:id/pin
+func (h *HistoryHandler) Unpin(c *gin.Context) {
+	h.setPinned(c, false)
+}
+
+// Favourite handles PUT /api/v1/history/:id/favourite
+func (h *HistoryHandler) Favourite(c *gin.Context) {
+	entry, err := h.history.SetFavourite(c.Param("id"), c.GetString("api_key_id"), true)
+	h.writeUpdate(c, entry, err, "favourite", true)
+}
+
+// Unfavourite handles DELETE /api/v1/history/:id/favourite
+func (h *HistoryHandler) Unfavourite(c *gin.Context) {
+	entry, err := h.history.SetFavourite(c.Param("id"), c.GetString("api_key_id"), false)
+	h.writeUpdate(c, entry, err, "favourite", false)
+}
+
+// Rate handles PUT /api/v1/history/:id/rating
+func (h *HistoryHandler) Rate(c *gin.Context) {
+	var body models.RatingRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(models.InvalidRequest(err.Error()))
+		return
+	}
+	entry, err := h.history.SetRating(c.Param("id"), c.GetString("api_key_id"), body.Rating)
+	h.writeUpdate(c, entry, err, "rating", body.Rating)
+}
+
+// ClearRating handles DELETE /api/v1/history/:id/rating
+func (h *HistoryHandler) ClearRating(c *gin.Context) {
+	entry, err := h.history.SetRating(c.Param("id"), c.GetString("api_key_id"), 0)
+	h.writeUpdate(c, entry, err, "rating", 0)
+}
+
+// SetTags handles PUT /api/v1/history/:id/tags
+func (h *HistoryHandler) SetTags(c *gin.Context) {
+	var body models.TagsRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(models.InvalidRequest(err.Error()))
+		return
+	}
+	entry, err := h.history.SetTags(c.Param("id"), c.GetString("api_key_id"), body.Tags)
+	h.writeUpdate(c, entry, err, "tags", body.Tags)
+}
+
+// writeUpdate writes the entry returned by an update, or its error
+func (h *HistoryHandler) writeUpdate(c *gin.Context, entry *models.HistoryEntry, err error, field string, value interface{}) {
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"id":  entry.ID,
+		field: value,
+	}).Info("History entry updated")
+	h.refreshURLs(entry)
+	c.JSON(http.StatusOK, entry)
+}
+
+// setPinned pins or unpins the entry named in the path
+func (h *HistoryHandler) setPinned(c *gin.Context, pinned bool) {
+	entry, err := h.history.SetPinned(c.Param("id"), c.GetString("api_key_id"), pinned)
+	h.writeUpdate(c, entry, err, "pinned", pinned)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ablerefusal/ablerefusal/internal/history"
+	"github.com/ablerefusal/ablerefusal/internal/library"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// LibraryHandler handles collection and tag endpoints
+type LibraryHandler struct {
+	library library.Manager
+	history history.Manager
+	logger  *logrus.Logger
+}
+
+// NewLibraryHandler creates a new library handler
+func NewLibraryHandler(library library.Manager, history history.Manager, logger *logrus.Logger) *LibraryHandler {
+	return &LibraryHandler{
+		library: library,
+		history: history,
+		logger:  logger,
+	}
+}
+
+// ListCollections handles GET /api/v1/collections
+func (h *LibraryHandler) ListCollections(c *gin.Context) {
+	collections := h.library.List(c.GetString("api_key_id"))
+	c.JSON(http.StatusOK, gin.H{
+		"collections": collections,
+		"count":       len(collections),
+	})
+}
+
+// CreateCollection handles POST /api/v1/collections
+func (h *LibraryHandler) CreateCollection(c *gin.Context) {
+	var body models.CollectionRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(models.InvalidRequest(err.Error()))
+		return
+	}
+
+	collection, err := h.library.Create(body.Name, body.Description, c.GetString("api_key_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.logger.WithField("collection", collection.Name).Info("Collection created")
+	c.JSON(http.StatusCreated, collection)
+}
+
+// GetCollection handles GET /api/v1/collections/:id
+func (h *LibraryHandler) GetCollection(c *gin.Context) {
+	collection, err := h.library.Get(c.Param("id"), c.GetString("api_key_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// UpdateCollection handles PUT /api/v1/collections/:id
+func (h *LibraryHandler) UpdateCollection(c *gin.Context) {
+	var body models.CollectionRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(models.InvalidRequest(err.Error()))
+		return
+	}
+
+	collection, err := h.library.Update(c.Param("id"), c.GetString("api_key_id"), body.Name, body.Description)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection handles DELETE /api/v1/collections/:id
+func (h *LibraryHandler) DeleteCollection(c *gin.Context) {
+	if err := h.library.Delete(c.Param("id"), c.GetString("api_key_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.logger.WithField("id", c.Param("id")).Info("Collection deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// AddEntry handles PUT /api/v1/collections/:id/entries/:entry_id
+func (h *LibraryHandler) AddEntry(c *gin.Context) {
+	if _, err := h.history.Get(c.Param("entry_id"), c.GetString("api_key_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	collection, err := h.library.AddEntry(c.Param("id"), c.GetString("api_key_id"), c.Param("entry_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// RemoveEntry handles DELETE /api/v1/collections/:id/entries/:entry_id
+func (h *LibraryHandler) RemoveEntry(c *gin.Context) {
+	collection, err := h.library.RemoveEntry(c.Param("id"), c.GetString("api_key_id"), c.Param("entry_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// ListTags handles GET /api/v1/tags, counting the caller's entries
+func (h *LibraryHandler) ListTags(c *gin.Context) {
+	tags := h.history.Tags(c.GetString("api_key_id"))
+	c.JSON(http.StatusOK, gin.H{
+		"tags":  tags,
+		"count": len(tags),
+	})
+}
+
+// DeleteTag handles DELETE /api/v1/tags/:tag, removing it from the
+// caller's entries
+func (h *LibraryHandler) DeleteTag(c *gin.Context) {
+	removed := h.history.DeleteTag(c.Param("tag"), c.GetString("api_key_id"))
+	h.logger.WithFields(logrus.Fields{
+		"tag":     c.Param("tag"),
+		"entries": removed,
+	}).Info("Tag deleted")
+	c.JSON(http.StatusOK, gin.H{
+		"tag":     models.NormalizeTag(c.Param("tag")),
+		"removed": removed,
+	})
+}
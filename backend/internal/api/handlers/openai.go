@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/generation"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/queue"
+	"github.com/ablerefusal/ablerefusal/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// maxOpenAIUpload is the largest multipart body accepted by /v1/images/edits
+const maxOpenAIUpload = 25 << 20
+
+// maxOpenAIImages is the largest n accepted per request
+const maxOpenAIImages = 10
+
+// OpenAIHandler implements the OpenAI Images API (/v1/images)
+type OpenAIHandler struct {
+	queue      queue.Manager
+	generation generation.Manager
+	storage    storage.Manager
+	config     *config.Config
+	logger     *logrus.Logger
+}
+
+// openAIImageRequest is the body of images.generate and the form of images.edit
+type openAIImageRequest struct {
+	Prompt         string `json:"prompt" form:"prompt"`
+	Model          string `json:"model" form:"model"`
+	N              int    `json:"n" form:"n"`
+	Size           string `json:"size" form:"size"`
+	ResponseFormat string `json:"response_format" form:"response_format"`
+	User           string `json:"user" form:"user"`
+}
+
+// openAIError is returned as {"error": {...}} in the OpenAI format
+type openAIError struct {
+	Status  int     `json:"-"`
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
+}
+
+// NewOpenAIHandler creates a new OpenAI Images API handler
+func NewOpenAIHandler(queue queue.Manager, generation generation.Manager, storage storage.Manager, cfg *config.Config, logger *logrus.Logger) *OpenAIHandler {
+	return &OpenAIHandler{
+		queue:      queue,
+		generation: generation,
+		storage:    storage,
+		config:     cfg,
+		logger:     logger,
+	}
+}
+
+// Generations handles POST /v1/images/generations
+func (h *OpenAIHandler) Generations(c *gin.Context) {
+	payload := &openAIImageRequest{N: 1, ResponseFormat: "url"}
+	if err := c.ShouldBindJSON(payload); err != nil {
+		h.respondError(c, invalidRequest("", "We could not parse the JSON body of your request: "+err.Error()))
+		return
+	}
+
+	req, apiErr := h.toGenerationRequest(payload)
+	if apiErr != nil {
+		h.respondError(c, apiErr)
+		return
+	}
+
+	h.generate(c, payload, req)
+}
+
+// Edits handles POST /v1/images/edits. Transparent areas of the mask, or of
+// the image when no mask is sent, are repainted; without any transparency the
+// request runs as image-to-image.
+func (h *OpenAIHandler) Edits(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxOpenAIUpload)
+
+	payload := &openAIImageRequest{N: 1, ResponseFormat: "url"}
+	if err := c.ShouldBind(payload); err != nil {
+		h.respondError(c, invalidRequest("", "We could not parse the form body of your request: "+err.Error()))
+		return
+	}
+
+	req, apiErr := h.toGenerationRequest(payload)
+	if apiErr != nil {
+		h.respondError(c, apiErr)
+		return
+	}
+
+	imageData, img, err := readFormImage(c, "image", "image[]")
+	if err != nil {
+		h.respondError(c, invalidRequest("image", err.Error()))
+		return
+	}
+	req.InitImage = base64.StdEncoding.EncodeToString(imageData)
+
+	// The mask defaults to the image's own alpha channel
+	maskSource := img
+	if _, mask, err := readFormImage(c, "mask"); err == nil {
+		if mask.Bounds().Size() != img.Bounds().Size() {
+			h.respondError(c, invalidRequest("mask", "The mask must have the same dimensions as the image."))
+			return
+		}
+		maskSource = mask
+	} else if !errors.Is(err, http.ErrMissingFile) {
+		h.respondError(c, invalidRequest("mask", err.Error()))
+		return
+	}
+
+	if mask, ok := alphaToMask(maskSource); ok {
+		encoded, err := encodePNG(mask)
+		if err != nil {
+			h.respondError(c, serverError(err.Error()))
+			return
+		}
+		req.Mask = base64.StdEncoding.EncodeToString(encoded)
+		req.Strength = 1
+	}
+
+	// Default to the input size, rounded down to a multiple of 8
+	if payload.Size == "" || payload.Size == "auto" {
+		size := img.Bounds().Size()
+		req.Width = size.X - size.X%8
+		req.Height = size.Y - size.Y%8
+	}
+
+	h.generate(c, payload, req)
+}
+
+// toGenerationRequest translates the shared request fields
+func (h *OpenAIHandler) toGenerationRequest(payload *openAIImageRequest) (*models.GenerationRequest, *openAIError) {
+	if strings.TrimSpace(payload.Prompt) == "" {
+		return nil, invalidRequest("prompt", "prompt is a required parameter.")
+	}
+	if payload.N < 1 || payload.N > maxOpenAIImages {
+		return nil, invalidRequest("n", fmt.Sprintf("n must be between 1 and %d.", maxOpenAIImages))
+	}
+	if payload.ResponseFormat != "url" && payload.ResponseFormat != "b64_json" {
+		return nil, invalidRequest("response_format", "response_format must be one of url, b64_json.")
+	}
+
+	model, ok := h.config.ResolveOpenAIModel(payload.Model)
+	if !ok {
+		apiErr := invalidRequest("model", fmt.Sprintf("The model '%s' does not exist.", payload.Model))
+		apiErr.Status = http.StatusNotFound
+		apiErr.Code = stringPtr("model_not_found")
+		return nil, apiErr
+	}
+
+	req := models.NewGenerationRequest()
+	req.Prompt = payload.Prompt
+	req.Model = model
+	if payload.User != "" {
+		req.ExtraParams = map[string]interface{}{"user": payload.User}
+	}
+
+	if payload.Size != "" && payload.Size != "auto" {
+		size, err := models.ParseImageSize(payload.Size)
+		if err != nil {
+			return nil, invalidRequest("size", fmt.Sprintf("'%s' is not a valid size, expected WIDTHxHEIGHT.", payload.Size))
+		}
+		req.Width = size.Width
+		req.Height = size.Height
+	}
+
+	return req, nil
+}
+
+// generate queues n copies of the request, waits for them and writes the
+// OpenAI images response
+func (h *OpenAIHandler) generate(c *gin.Context, payload *openAIImageRequest, template *models.GenerationRequest) {
+	var queued []*models.GenerationRequest
+	for i := 0; i < payload.N; i++ {
+		// Each request gets its own LoRAs, ControlNets and metadata
+		req := template.Clone()
+		req.ID = ""
+		req.Owner = c.GetString("api_key_id")
+
+		reqs, _, err := h.generation.Submit(req)
+		if err != nil {
+			for _, r := range queued {
+				h.queue.Cancel(r.ID, r.Owner)
+			}
+			h.logger.WithError(err).Error("Failed to queue OpenAI image request")
+			h.respondError(c, submitErrorToOpenAI(err))
+			return
+		}
+		queued = append(queued, reqs...)
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id": queued[0].ID,
+		"count":      len(queued),
+		"user":       payload.User,
+	}).Info("OpenAI image request queued")
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), waitTimeout(c, h.config, 0))
+	defer cancel()
+
+	statuses, err := h.generation.Wait(ctx, queued)
+	if err != nil {
+		// The jobs keep running, their results can still be fetched through
+		// the native API and history
+		ids := make([]string, len(queued))
+		for i, r := range queued {
+			ids[i] = r.ID
+		}
+		h.logger.WithError(err).WithField("request_id", queued[0].ID).Warn("OpenAI image request did not finish in time")
+		apiErr := serverError(fmt.Sprintf("The image generation did not finish in time and is still running as %s.", strings.Join(ids, ", ")))
+		apiErr.Status = http.StatusGatewayTimeout
+		h.respondError(c, apiErr)
+		return
+	}
+
+	data := make([]gin.H, 0, len(queued))
+	for i, status := range statuses {
+		if status.Status != models.StatusCompleted {
+			message := status.Error
+			if message == "" {
+				message = fmt.Sprintf("The image generation was %s.", status.Status)
+			}
+			h.respondError(c, serverError(message))
+			return
+		}
+
+		for _, result := range status.Results {
+			item := gin.H{}
+			if queued[i].Prompt != payload.Prompt {
+				item["revised_prompt"] = queued[i].Prompt
+			}
+
+			if payload.ResponseFormat == "b64_json" {
+				imageData, err := loadResultImage(c.Request.Context(), h.storage, result)
+				if err != nil {
+					h.logger.WithError(err).WithField("request_id", status.ID).Error("Failed to read generated image")
+					h.respondError(c, serverError("The generated image could not be read."))
+					return
+				}
+				item["b64_json"] = base64.StdEncoding.EncodeToString(imageData)
+			} else {
+				item["url"] = h.imageURL(c, result)
+			}
+			data = append(data, item)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"created": time.Now().Unix(),
+		"data":    data,
+	})
+}
+
+// respondError writes an OpenAI-shaped error
+func (h *OpenAIHandler) respondError(c *gin.Context, apiErr *openAIError) {
+	c.JSON(apiErr.Status, gin.H{"error": apiErr})
+}
+
+// submitErrorToOpenAI maps a generation.Manager error to an OpenAI error,
+// naming the OpenAI parameter behind the first invalid field
+func submitErrorToOpenAI(err error) *openAIError {
+	var validationErrs models.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrs):
+		messages := make([]string, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			messages[i] = fieldErr.Error()
+		}
+		param := validationErrs[0].Field
+		switch param {
+		case "width", "height":
+			param = "size"
+		case "batch_size":
+			param = "n"
+		}
+		return invalidRequest(param, strings.Join(messages, "; "))
+	case errors.Is(err, models.ErrQueueFull):
+		apiErr := serverError("The server is currently overloaded with other requests, please retry later.")
+		apiErr.Status = http.StatusServiceUnavailable
+		return apiErr
+	case errors.Is(err, models.ErrStorageFull):
+		apiErr := serverError("The server is out of storage for generated images, please retry later.")
+		apiErr.Status = http.StatusInsufficientStorage
+		apiErr.Code = stringPtr(string(models.CodeStorageFull))
+		return apiErr
+	default:
+		return invalidRequest("", err.Error())
+	}
+}
+
+func invalidRequest(param, message string) *openAIError {
+	apiErr := &openAIError{Status: http.StatusBadRequest, Message: message, Type: "invalid_request_error"}
+	if param != "" {
+		apiErr.Param = stringPtr(param)
+	}
+	return apiErr
+}
+
+func serverError(message string) *openAIError {
+	return &openAIError{Status: http.StatusInternalServerError, Message: message, Type: "server_error"}
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+// imageURL returns the link to a generated image: a presigned link when the
+// storage issues them, otherwise the absolute URL of the proxied path
+func (h *OpenAIHandler) imageURL(c *gin.Context, result models.GenerationResult) string {
+	link, err := h.storage.OutputURL(c.Request.Context(), filepath.Base(result.ImagePath))
+	if err != nil {
+		return absoluteURL(c, result.ImageURL)
+	}
+	if strings.HasPrefix(link, "/") {
+		return absoluteURL(c, link)
+	}
+	return link
+}
+
+// absoluteURL returns the public URL of a path, honouring reverse proxies
+func absoluteURL(c *gin.Context, path string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host + path
+}
+
+// readFormImage reads and decodes the first uploaded file found under names
+func readFormImage(c *gin.Context, names ...string) ([]byte, image.Image, error) {
+	var header *multipart.FileHeader
+	err := http.ErrMissingFile
+	for _, name := range names {
+		if header, err = c.FormFile(name); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid image %s: %w", header.Filename, err)
+	}
+	return data, img, nil
+}
+
+// alphaToMask converts the fully transparent pixels of img into the white
+// areas of an inpainting mask. It returns false if nothing is transparent.
+func alphaToMask(img image.Image) (*image.Gray, bool) {
+	bounds := img.Bounds()
+	mask := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	transparent := false
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a == 0 {
+				mask.SetGray(x-bounds.Min.X, y-bounds.Min.Y, color.Gray{Y: 255})
+				transparent = true
+			}
+		}
+	}
+
+	return mask, transparent
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ablerefusal/ablerefusal/internal/api/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OpenAPIHandler serves the API description
+type OpenAPIHandler struct {
+	doc    *openapi.Document
+	logger *logrus.Logger
+}
+
+// NewOpenAPIHandler creates a new OpenAPI handler
+func NewOpenAPIHandler(doc *openapi.Document, logger *logrus.Logger) *OpenAPIHandler {
+	return &OpenAPIHandler{
+		doc:    doc,
+		logger: logger,
+	}
+}
+
+// Spec handles GET /api/v1/openapi.json
+func (h *OpenAPIHandler) Spec(c *gin.Context) {
+	c.JSON(http.StatusOK, h.doc)
+}
+
+// Docs handles GET /api/v1/docs
+func (h *OpenAPIHandler) Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage)
+}
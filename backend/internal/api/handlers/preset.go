@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/presets"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PresetHandler handles preset and style template endpoints
+type PresetHandler struct {
+	presets presets.Manager
+	logger  *logrus.Logger
+}
+
+// NewPresetHandler creates a new preset handler
+func NewPresetHandler(presets presets.Manager, logger *logrus.Logger) *PresetHandler {
+	return &PresetHandler{
+		presets: presets,
+		logger:  logger,
+	}
+}
+
+// ListPresets handles GET /api/v1/presets
+func (h *PresetHandler) ListPresets(c *gin.Context) {
+	presets := h.presets.ListPresets()
+	c.JSON(http.StatusOK, gin.H{
+		"presets": presets,
+		"count":   len(presets),
+	})
+}
+
+// GetPreset handles GET /api/v1/presets/:name
+func (h *PresetHandler) GetPreset(c *gin.Context) {
+	preset, err := h.presets.GetPreset(c.Param("name"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, preset)
+}
+
+// CreatePreset handles POST /api/v1/presets
+func (h *PresetHandler) CreatePreset(c *gin.Context) {
+	var preset models.Preset
+	if err := c.ShouldBindJSON(&preset); err != nil {
+		c.Error(models.InvalidRequest(err.Error()))
+		return
+	}
+
+	if err := h.presets.CreatePreset(&preset); err != nil {
+		if errors.Is(err, models.ErrPresetExists) {
+			c.Error(models.AsError(err).WithDetail("name", preset.Name))
+			return
+		}
+		h.respondError(c, err)
+		return
+	}
+
+	h.logger.WithField("preset", preset.Name).Info("Preset created")
+	c.JSON(http.StatusCreated, preset)
+}
+
+// UpdatePreset handles PUT /api/v1/presets/:name
+func (h *PresetHandler) UpdatePreset(c *gin.Context) {
+	var preset models.Preset
+	if err := c.ShouldBindJSON(&preset); err != nil {
+		c.Error(models.InvalidRequest(err.Error()))
+		return
+	}
+	preset.Name = c.Param("name")
+
+	if err := h.presets.SavePreset(&preset); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	h.logger.WithField("preset", preset.Name).Info("Preset updated")
+	c.JSON(http.StatusOK, preset)
+}
+
+// DeletePreset handles DELETE /api/v1/presets/:name
+func (h *PresetHandler) DeletePreset(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.presets.DeletePreset(name); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	h.logger.WithField("preset", name).Info("Preset deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// ListStyles handles GET /api/v1/styles
+func (h *PresetHandler) ListStyles(c *gin.Context) {
+	styles := h.presets.ListStyles()
+	c.JSON(http.StatusOK, gin.H{
+		"styles": styles,
+		"count":  len(styles),
+	})
+}
+
+// GetStyle handles GET /api/v1/styles/:name
+func (h *PresetHandler) GetStyle(c *gin.Context) {
+	style, err := h.presets.GetStyle(c.Param("name"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, style)
+}
+
+// CreateStyle handles POST /api/v1/styles
+func (h *PresetHandler) CreateStyle(c *gin.Context) {
+	var style models.StyleTemplate
+	if err := c.ShouldBindJSON(&style); err != nil {
+		c.Error(models.InvalidRequest(err.Error()))
+		return
+	}
+
+	if err := h.presets.CreateStyle(&style); err != nil {
+		if errors.Is(err, models.ErrStyleExists) {
+			c.Error(models.AsError(err).WithDetail("name", style.Name))
+			return
+		}
+		h.respondError(c, err)
+		return
+	}
+
+	h.logger.WithField("style", style.Name).Info("Style created")
+	c.JSON(http.StatusCreated, style)
+}
+
+// UpdateStyle handles PUT /api/v1/styles/:name
+func (h *PresetHandler) UpdateStyle(c *gin.Context) {
+	var style models.StyleTemplate
+	if err := c.ShouldBindJSON(&style); err != nil {
+		c.Error(models.InvalidRequest(err.Error()))
+		return
+	}
+	style.Name = c.Param("name")
+
+	if err := h.presets.SaveStyle(&style); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	h.logger.WithField("style", style.Name).Info("Style updated")
+	c.JSON(http.StatusOK, style)
+}
+
+// DeleteStyle handles DELETE /api/v1/styles/:name
+func (h *PresetHandler) DeleteStyle(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.presets.DeleteStyle(name); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	h.logger.WithField("style", name).Info("Style deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// respondError maps preset errors to API errors
+func (h *PresetHandler) respondError(c *gin.Context, err error) {
+	apiErr := models.AsError(err)
+	switch {
+	case apiErr.Status == http.StatusNotFound && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodDelete:
+		// A saved preset referencing a missing style is a bad request
+		apiErr = apiErr.WithStatus(http.StatusBadRequest)
+	case apiErr.Code == models.CodeInternal:
+		apiErr = models.NewError(models.CodeInternal, http.StatusInternalServerError, "Failed to save presets").Wrap(err)
+	}
+	c.Error(apiErr)
+}
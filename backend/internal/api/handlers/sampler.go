@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ablerefusal/ablerefusal/internal/inference"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SamplerHandler handles sampler catalogue endpoints
+type SamplerHandler struct {
+	inference inference.Engine
+	logger    *logrus.Logger
+}
+
+// NewSamplerHandler creates a new sampler handler
+func NewSamplerHandler(inference inference.Engine, logger *logrus.Logger) *SamplerHandler {
+	return &SamplerHandler{
+		inference: inference,
+		logger:    logger,
+	}
+}
+
+// List handles GET /api/v1/samplers
+func (h *SamplerHandler) List(c *gin.Context) {
+	catalogue, err := h.inference.ListSamplers()
+	if err != nil {
+		c.Error(models.NewError(models.CodeInferenceUnavailable, http.StatusBadGateway, "Failed to list samplers").Wrap(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, catalogue)
+}
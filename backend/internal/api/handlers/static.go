@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"fmt"
+	"io"
 	"net/http"
-	"path/filepath"
+	"path"
+	"strconv"
 
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/imaging"
+	"github.com/ablerefusal/ablerefusal/internal/models"
 	"github.com/ablerefusal/ablerefusal/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -11,39 +17,146 @@ import (
 
 // StaticHandler handles static file serving
 type StaticHandler struct {
-	storage storage.Manager
-	logger  *logrus.Logger
+	storage      storage.Manager
+	urlMode      string
+	maxWidth     int
+	cacheControl string
+	logger       *logrus.Logger
 }
 
 // NewStaticHandler creates a new static handler
-func NewStaticHandler(storage storage.Manager, logger *logrus.Logger) *StaticHandler {
+func NewStaticHandler(storage storage.Manager, cfg config.StorageConfig, auth config.AuthConfig, logger *logrus.Logger) *StaticHandler {
+	// Shared caches must not keep serving signed URLs past their expiry
+	cacheControl := "public, max-age=86400"
+	if auth.SignOutputs() {
+		cacheControl = "private, max-age=3600"
+	}
 	return &StaticHandler{
-		storage: storage,
-		logger:  logger,
+		storage:      storage,
+		urlMode:      cfg.URLMode,
+		maxWidth:     cfg.Thumbnails.MaxWidth,
+		cacheControl: cacheControl,
+		logger:       logger,
 	}
 }
 
-// ServeImage serves a generated image
+// ServeImage handles GET /outputs/*filepath. Outputs are streamed from the
+// storage backend, or redirected to a presigned link in presign mode. The
+// w, fmt and q query parameters serve a resized or converted copy instead.
+// When auth is enabled the URL must be signed, or the request must carry the
+// API key the output was generated with.
 func (h *StaticHandler) ServeImage(c *gin.Context) {
-	filename := c.Param("filename")
-	if filename == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing filename"})
+	name := path.Clean("/" + c.Param("filepath"))[1:]
+	if name == "" {
+		c.Error(models.InvalidRequest("Missing filename"))
+		return
+	}
+	if err := h.storage.AuthorizeOutput(c.Request.Context(), name, c.Request.URL.Query(), c.GetString("api_key_id")); err != nil {
+		c.Error(err)
 		return
 	}
 
-	// Validate filename (prevent directory traversal)
-	if filepath.Base(filename) != filename {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+	variant, ok, err := h.parseVariant(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	if ok {
+		h.serveVariant(c, name, variant)
+		return
+	}
+
+	if h.urlMode == storage.URLModePresign {
+		link, err := h.storage.OutputURL(c.Request.Context(), name)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if link != "/outputs/"+name {
+			c.Redirect(http.StatusTemporaryRedirect, link)
+			return
+		}
+	}
 
-	// Get file path
-	filePath, err := h.storage.GetOutputPath(filename)
+	reader, info, err := h.storage.OpenOutput(c.Request.Context(), name)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		c.Error(err)
 		return
 	}
+	defer reader.Close()
 
-	// Serve file
-	c.File(filePath)
-}
\ No newline at end of file
+	c.Header("Cache-Control", h.cacheControl)
+	// Originals are tagged with their content hash, like resized copies
+	if info.ETag != "" {
+		c.Header("ETag", strconv.Quote(info.ETag))
+	}
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		if info.ContentType != "" {
+			c.Header("Content-Type", info.ContentType)
+		}
+		http.ServeContent(c.Writer, c.Request, path.Base(name), info.ModifiedAt, seeker)
+		return
+	}
+	if info.ETag != "" && c.GetHeader("If-None-Match") == strconv.Quote(info.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, reader, nil)
+}
+
+// serveVariant serves a resized or converted copy of an output. Copies are
+// always streamed, presigned links only exist for the originals.
+func (h *StaticHandler) serveVariant(c *gin.Context, name string, variant storage.Variant) {
+	reader, info, err := h.storage.OpenVariant(c.Request.Context(), name, variant)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer reader.Close()
+
+	c.Header("Cache-Control", h.cacheControl)
+	c.Header("ETag", strconv.Quote(info.ETag))
+	c.Header("Content-Type", info.ContentType)
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, path.Base(name), info.ModifiedAt, seeker)
+		return
+	}
+	if c.GetHeader("If-None-Match") == strconv.Quote(info.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, reader, nil)
+}
+
+// parseVariant reads the w, fmt and q query parameters. ok is false when
+// none of them is set and the original should be served.
+func (h *StaticHandler) parseVariant(c *gin.Context) (variant storage.Variant, ok bool, err error) {
+	if value := c.Query("w"); value != "" {
+		variant.Width, err = strconv.Atoi(value)
+		if err != nil || variant.Width < 1 || (h.maxWidth > 0 && variant.Width > h.maxWidth) {
+			return variant, false, invalidVariant("w", fmt.Sprintf("must be between 1 and %d", h.maxWidth))
+		}
+		ok = true
+	}
+	if value := c.Query("fmt"); value != "" {
+		format, valid := models.ParseImageFormat(value)
+		if !valid || !imaging.Supported(format) {
+			return variant, false, invalidVariant("fmt", fmt.Sprintf("must be one of %v", imaging.Formats()))
+		}
+		variant.Format = format
+		ok = true
+	}
+	if value := c.Query("q"); value != "" {
+		variant.Quality, err = strconv.Atoi(value)
+		if err != nil || variant.Quality < 1 || variant.Quality > 100 {
+			return variant, false, invalidVariant("q", "must be between 1 and 100")
+		}
+		ok = true
+	}
+	return variant, ok, nil
+}
+
+// invalidVariant reports an invalid variant query parameter
+func invalidVariant(param, message string) error {
+	return models.ValidationErrors{{Field: param, Message: message, Err: models.ErrInvalidOutputFormat}}
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/ablerefusal/ablerefusal/internal/models"
 	"github.com/ablerefusal/ablerefusal/internal/queue"
@@ -27,30 +29,50 @@ func NewStatusHandler(queue queue.Manager, logger *logrus.Logger) *StatusHandler
 func (h *StatusHandler) GetStatus(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing generation ID"})
+		c.Error(models.InvalidRequest("Missing generation ID"))
 		return
 	}
 
-	status, err := h.queue.GetStatus(id)
+	status, err := h.queue.GetStatus(id, c.GetString("api_key_id"))
 	if err != nil {
-		if err == models.ErrGenerationNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Generation not found"})
-			return
-		}
-		h.logger.WithError(err).Error("Failed to get generation status")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get status"})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, status)
 }
 
-// GetQueue handles GET /api/v1/queue
+// Events handles GET /api/v1/generate/:id/events, streaming status updates
+// as server-sent events until the generation finishes
+func (h *StatusHandler) Events(c *gin.Context) {
+	updates, err := h.queue.Watch(c.Request.Context(), c.Param("id"), c.GetString("api_key_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Streams outlive the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.WithError(err).Warn("Failed to clear write deadline for event stream")
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		status, ok := <-updates
+		if !ok {
+			return false
+		}
+		c.SSEvent("status", status)
+		return !status.Status.IsTerminal()
+	})
+}
+
+// GetQueue handles GET /api/v1/queue, listing the caller's generations
 func (h *StatusHandler) GetQueue(c *gin.Context) {
-	queue, err := h.queue.GetQueue()
+	queue, err := h.queue.GetQueue(c.GetString("api_key_id"))
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get queue")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get queue"})
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookHandler handles webhook delivery log endpoints
+type WebhookHandler struct {
+	webhooks webhook.Manager
+	logger   *logrus.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhooks webhook.Manager, logger *logrus.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// ListDeliveries handles GET /api/v1/webhooks/deliveries
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	filter := webhook.DeliveryFilter{
+		Owner:  c.GetString("api_key_id"),
+		JobID:  c.Query("job_id"),
+		Status: models.WebhookDeliveryStatus(c.Query("status")),
+		Limit:  100,
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			c.Error(models.InvalidRequest("Invalid limit"))
+			return
+		}
+		filter.Limit = n
+	}
+
+	deliveries := h.webhooks.ListDeliveries(filter)
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
+
+// ReplayDelivery handles POST /api/v1/webhooks/deliveries/:id/replay
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	id := c.Param("id")
+
+	delivery, err := h.webhooks.Replay(id, c.GetString("api_key_id"))
+	if err != nil {
+		if errors.Is(err, models.ErrDeliveryNotFound) {
+			c.Error(err)
+			return
+		}
+		c.Error(models.NewError(models.CodeDeliveryNotReplayable, http.StatusConflict, err.Error()).WithDetail("id", id))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
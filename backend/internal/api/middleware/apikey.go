@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the client's API key when it is not sent as a bearer token
+const APIKeyHeader = "X-API-Key"
+
+// publicPaths are served without an API key when auth is enabled. Outputs
+// check signed URLs themselves.
+var publicPaths = []string{"/api/v1/health", "/api/v1/ready", "/api/v1/openapi.json", "/api/v1/docs", "/outputs/"}
+
+// APIKey returns a middleware that identifies the client by the API key in
+// the X-API-Key header or the Authorization bearer token. The key's ID is
+// stored as "api_key_id" for per-key storage quotas and output access. When
+// auth is enabled, requests without one of the configured keys are rejected.
+func APIKey(cfg config.AuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := requestKey(c)
+		if cfg.Enabled && !cfg.ValidKey(key) {
+			if c.Request.Method == http.MethodOptions || isPublic(c.Request.URL.Path) {
+				c.Next()
+				return
+			}
+			c.Error(models.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		if id := models.APIKeyID(key); id != "" {
+			c.Set("api_key_id", id)
+		}
+		c.Next()
+	}
+}
+
+// AdminKey returns a middleware that only lets requests carrying one of the
+// configured admin keys through
+func AdminKey(cfg config.AuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := requestKey(c)
+		if key == "" {
+			c.Error(models.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+		if !cfg.ValidAdminKey(key) {
+			c.Error(fmt.Errorf("%w: an admin key is required", models.ErrForbidden))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestKey returns the API key in the X-API-Key header or the
+// Authorization bearer token
+func requestKey(c *gin.Context) string {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return key
+	}
+	return models.BearerToken(c.GetHeader("Authorization"))
+}
+
+// isPublic reports whether path is served without an API key
+func isPublic(path string) bool {
+	for _, public := range publicPaths {
+		if path == public || (strings.HasSuffix(public, "/") && strings.HasPrefix(path, public)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS applies the server's CORS settings and can be reconfigured while
+// the server runs
+type CORS struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+// NewCORS creates the CORS middleware for the server config
+func NewCORS(cfg config.ServerConfig) *CORS {
+	m := &CORS{}
+	m.Update(cfg)
+	return m
+}
+
+// Update replaces the allowed origins, or turns CORS on or off. The origins
+// must have been validated, cors.New panics on malformed ones.
+func (m *CORS) Update(cfg config.ServerConfig) {
+	handler := gin.HandlerFunc(func(c *gin.Context) { c.Next() })
+	if cfg.EnableCORS {
+		corsConfig := cors.DefaultConfig()
+		corsConfig.AllowOrigins = cfg.CORSOrigins
+		corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+		corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", IdempotencyKeyHeader, APIKeyHeader}
+		corsConfig.AllowCredentials = true
+		handler = cors.New(corsConfig)
+	}
+	m.handler.Store(&handler)
+}
+
+// Handler returns the middleware, which always uses the latest settings
+func (m *CORS) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		(*m.handler.Load())(c)
+	}
+}
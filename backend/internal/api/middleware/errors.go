@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Errors returns a middleware that renders the last error attached with
+// c.Error as a structured JSON response:
+//
+//	{"error": "...", "code": "...", "request_id": "...", "details": {...}, "fields": [...]}
+//
+// Responses already written by the handler are left alone.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		apiErr := models.AsError(c.Errors.Last().Err)
+		c.Set("error_code", string(apiErr.Code))
+
+		body := gin.H{
+			"error":      apiErr.Message,
+			"code":       apiErr.Code,
+			"request_id": c.GetString("request_id"),
+		}
+		if len(apiErr.Details) > 0 {
+			body["details"] = apiErr.Details
+		}
+		if len(apiErr.Fields) > 0 {
+			body["fields"] = apiErr.Fields
+		}
+		c.JSON(apiErr.Status, body)
+	}
+}
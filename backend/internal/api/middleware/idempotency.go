@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ablerefusal/ablerefusal/internal/idempotency"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client's key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayHeader marks responses replayed from the store
+	IdempotentReplayHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+
+	// generationIDHeader names the generation in image responses
+	generationIDHeader = "X-Generation-ID"
+)
+
+// capturingWriter records the response body while writing it through
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to extend
+// the write deadline of a synchronous generation
+func (w *capturingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Idempotency returns a middleware that honours the Idempotency-Key header.
+// Successful responses are stored; a retry with the same key, method, path,
+// query and body gets the original response back, a retry that differs in any
+// of them gets 422.
+// Requests without the header pass through unchanged. Keys are scoped to
+// the caller's API key, so clients cannot see or replay each other's.
+func Idempotency(store idempotency.Store, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.Error(models.InvalidRequest("Idempotency-Key is too long").WithDetail("max_length", maxIdempotencyKeyLength))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Error(models.InvalidRequest("Failed to read request body").Wrap(err))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys without an API key share the empty scope
+		scoped := c.GetString("api_key_id") + ":" + key
+
+		fingerprint := idempotency.Fingerprint(c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery, body)
+		record, err := store.Begin(scoped, fingerprint)
+		if err != nil {
+			code, status := models.CodeIdempotencyKeyReused, http.StatusUnprocessableEntity
+			if errors.Is(err, idempotency.ErrInProgress) {
+				code, status = models.CodeIdempotencyInProgress, http.StatusConflict
+				c.Header("Retry-After", "1")
+			}
+			c.Error(models.NewError(code, status, err.Error()).Wrap(err))
+			c.Abort()
+			return
+		}
+
+		// Replay the stored response
+		if record != nil {
+			logger.WithField("idempotency_key", key).Info("Replaying idempotent response")
+			for name, values := range record.Header {
+				for _, value := range values {
+					c.Writer.Header().Add(name, value)
+				}
+			}
+			c.Header(IdempotentReplayHeader, "true")
+			c.Data(record.StatusCode, record.Header.Get("Content-Type"), record.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// Release the key if the handler fails or panics
+		completed := false
+		defer func() {
+			if !completed {
+				store.Release(scoped)
+			}
+		}()
+
+		c.Next()
+
+		// Only successful responses are remembered, failures may be retried.
+		// Errors are rendered later by the Errors middleware.
+		status := writer.Status()
+		if len(c.Errors) == 0 && status >= 200 && status < 300 {
+			header := http.Header{}
+			header.Set("Content-Type", writer.Header().Get("Content-Type"))
+			body := writer.body.Bytes()
+
+			// Images are not kept for the whole window, retries get the
+			// generation ID to fetch them by instead
+			if !strings.HasPrefix(header.Get("Content-Type"), gin.MIMEJSON) {
+				id := writer.Header().Get(generationIDHeader)
+				if id == "" {
+					return
+				}
+				header.Set("Content-Type", gin.MIMEJSON)
+				header.Set(generationIDHeader, id)
+				body, _ = json.Marshal(gin.H{
+					"id":      id,
+					"message": "The original response was an image, get the generation by its ID",
+				})
+			}
+
+			store.Complete(scoped, status, header, body)
+			completed = true
+		}
+	}
+}
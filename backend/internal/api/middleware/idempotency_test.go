@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/idempotency"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// idempotentRouter serves POST /generate behind the idempotency middleware.
+// The caller's key ID is taken from the X-Key-ID header, and every handled
+// request is counted.
+func idempotentRouter(calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	router := gin.New()
+	router.Use(Errors())
+	router.Use(func(c *gin.Context) {
+		c.Set("api_key_id", c.GetHeader("X-Key-ID"))
+	})
+	router.POST("/generate", Idempotency(idempotency.NewMemoryStore(time.Hour), logger), func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusAccepted, gin.H{"call": *calls})
+	})
+	return router
+}
+
+// post sends a generate request with an idempotency key as keyID
+func post(router *gin.Engine, keyID, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, "retry-1")
+	req.Header.Set("X-Key-ID", keyID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// errorCode returns the code of an error response
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) models.ErrorCode {
+	t.Helper()
+	var body struct {
+		Code models.ErrorCode `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode error response %q: %v", w.Body.String(), err)
+	}
+	return body.Code
+}
+
+func TestIdempotencyReplaysPerAPIKey(t *testing.T) {
+	calls := 0
+	router := idempotentRouter(&calls)
+	body := `{"prompt":"a cat"}`
+
+	first := post(router, "key-1", "/generate", body)
+	if first.Code != http.StatusAccepted || calls != 1 {
+		t.Fatalf("first request = %d after %d calls, want 202 after 1", first.Code, calls)
+	}
+
+	retry := post(router, "key-1", "/generate", body)
+	if retry.Code != http.StatusAccepted || calls != 1 || retry.Header().Get(IdempotentReplayHeader) != "true" {
+		t.Errorf("retry = %d after %d calls, replayed %q, want a replayed 202 after 1 call", retry.Code, calls, retry.Header().Get(IdempotentReplayHeader))
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("replayed body = %s, want %s", retry.Body, first.Body)
+	}
+
+	// The same idempotency key from another API key is a separate request
+	other := post(router, "key-2", "/generate", body)
+	if other.Code != http.StatusAccepted || calls != 2 || other.Header().Get(IdempotentReplayHeader) != "" {
+		t.Errorf("other key = %d after %d calls, want a new 202 after 2", other.Code, calls)
+	}
+}
+
+func TestIdempotencyRejectsChangedRequests(t *testing.T) {
+	calls := 0
+	router := idempotentRouter(&calls)
+	post(router, "key-1", "/generate", `{"prompt":"a cat"}`)
+
+	for _, tt := range []struct {
+		desc   string
+		target string
+		body   string
+	}{
+		{"other body", "/generate", `{"prompt":"a dog"}`},
+		{"other query", "/generate?wait=true", `{"prompt":"a cat"}`},
+	} {
+		w := post(router, "key-1", tt.target, tt.body)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want 422", tt.desc, w.Code)
+		}
+		if code := errorCode(t, w); code != models.CodeIdempotencyKeyReused {
+			t.Errorf("%s: code = %q, want %q", tt.desc, code, models.CodeIdempotencyKeyReused)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
@@ -39,8 +39,13 @@ func Logger(logger *logrus.Logger) gin.HandlerFunc {
 		})
 
 		if len(c.Errors) > 0 {
-			// Log errors
-			entry.Error(c.Errors.String())
+			// Log errors, client errors are not server failures
+			entry = entry.WithField("error_code", c.GetString("error_code"))
+			if c.Writer.Status() >= 500 {
+				entry.Error(c.Errors.String())
+			} else {
+				entry.Warn(c.Errors.String())
+			}
 		} else {
 			// Log info
 			entry.Info("Request processed")
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/ablerefusal/ablerefusal/internal/api/openapi"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ValidateRequest returns a middleware that checks JSON request bodies against
+// the operation's schema in the OpenAPI document. Invalid bodies are rejected
+// with 400 and every invalid field; the handler sees the body unchanged.
+func ValidateRequest(doc *openapi.Document, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := doc.Operation(c.Request.Method, c.FullPath())
+		if op == nil || op.RequestBody == nil {
+			c.Next()
+			return
+		}
+		media, exists := op.RequestBody.Content[gin.MIMEJSON]
+		if !exists || media.Schema == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Error(models.InvalidRequest("Failed to read request body").Wrap(err))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			c.Error(models.InvalidRequest("Request body is not valid JSON: " + err.Error()))
+			c.Abort()
+			return
+		}
+
+		if errs := doc.Validate(media.Schema, value); len(errs) > 0 {
+			logger.WithFields(logrus.Fields{
+				"path":   c.FullPath(),
+				"errors": errs.Error(),
+			}).Info("Rejected invalid request body")
+			c.Error(&models.Error{
+				Code:    models.CodeValidationFailed,
+				Status:  http.StatusBadRequest,
+				Message: "Invalid request body",
+				Fields:  errs,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
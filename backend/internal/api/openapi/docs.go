@@ -0,0 +1,8 @@
+package openapi
+
+import _ "embed"
+
+// DocsPage is an HTML page that renders openapi.json from the same directory with Swagger UI
+//
+//go:embed docs.html
+var DocsPage []byte
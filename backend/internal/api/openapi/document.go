@@ -0,0 +1,141 @@
+// Package openapi describes the REST API as an OpenAPI 3 document. Schemas
+// are generated from the models types so the document cannot drift from the
+// JSON the handlers read and write.
+package openapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is the OpenAPI specification version of the document
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is a base URL the API is served from
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag groups operations in the docs page
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path, keyed by lower-case HTTP method
+type PathItem map[string]*Operation
+
+// Operation describes a single route
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a response
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Operation returns the operation for a gin route, or nil if it is not documented
+func (d *Document) Operation(method, route string) *Operation {
+	item, exists := d.Paths[toPath(route)]
+	if !exists {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+// Undocumented returns the API routes that have no operation in the document
+func (d *Document) Undocumented(routes gin.RoutesInfo) []string {
+	var missing []string
+	for _, route := range routes {
+		if !documented(route.Path) {
+			continue
+		}
+		if d.Operation(route.Method, route.Path) == nil {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Resolve follows a component reference
+func (d *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, componentPrefix)]
+	}
+	return schema
+}
+
+// documented reports whether a route belongs to the documented API surface.
+// Static files and placeholders are left out.
+func documented(route string) bool {
+	for _, prefix := range []string{"/api/", "/sdapi/", "/v1/"} {
+		if strings.HasPrefix(route, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// toPath converts a gin route (/generate/:id) to an OpenAPI path (/generate/{id})
+func toPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const componentPrefix = "#/components/schemas/"
+
+// Schema is an OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+}
+
+// Property returns the schema of an object property, or nil
+func (s *Schema) Property(name string) *Schema {
+	return s.Properties[name]
+}
+
+// Range sets the inclusive bounds of a number
+func (s *Schema) Range(min, max float64) *Schema {
+	s.Minimum, s.Maximum = &min, &max
+	return s
+}
+
+// Min sets the inclusive lower bound of a number
+func (s *Schema) Min(min float64) *Schema {
+	s.Minimum = &min
+	return s
+}
+
+// Length sets the bounds of a string's length
+func (s *Schema) Length(min, max int) *Schema {
+	s.MinLength, s.MaxLength = &min, &max
+	return s
+}
+
+// Values restricts a schema to an enumeration. Named string types are
+// stored as plain strings so they compare equal to decoded JSON.
+func (s *Schema) Values(values ...interface{}) *Schema {
+	s.Enum = make([]interface{}, len(values))
+	for i, value := range values {
+		if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+			value = v.String()
+		}
+		s.Enum[i] = value
+	}
+	return s
+}
+
+// Describe sets the description
+func (s *Schema) Describe(description string) *Schema {
+	s.Description = description
+	return s
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// generator derives schemas from Go types. Named structs become components.
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func newGenerator() *generator {
+	return &generator{schemas: make(map[string]*Schema)}
+}
+
+// schema returns the schema of v's type
+func (g *generator) schema(v interface{}) *Schema {
+	return g.schemaFor(reflect.TypeOf(v))
+}
+
+// component returns the component schema of a named struct, generating it if needed
+func (g *generator) component(v interface{}) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	g.schemaFor(t)
+	return g.schemas[t.Name()]
+}
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schemaFor(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, exists := g.schemas[t.Name()]; !exists {
+			// Register before recursing so self-references terminate
+			g.schemas[t.Name()] = &Schema{}
+			*g.schemas[t.Name()] = *g.object(t)
+		}
+		return &Schema{Ref: componentPrefix + t.Name()}
+	default:
+		// interface{} accepts any value
+		return &Schema{}
+	}
+}
+
+// object builds the schema of a struct from its exported fields and JSON tags
+func (g *generator) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := g.object(field.Type)
+			for key, value := range embedded.Properties {
+				schema.Properties[key] = value
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schemaFor(field.Type)
+	}
+	return schema
+}
@@ -0,0 +1,644 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// APIVersion is the version of the REST API described by the document
+const APIVersion = "0.1.0"
+
+// errorResponse is the error shape of the native API
+type errorResponse struct {
+	Error     string                 `json:"error"`
+	Code      models.ErrorCode       `json:"code"`
+	RequestID string                 `json:"request_id"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Fields    []*models.FieldError   `json:"fields,omitempty"`
+}
+
+// builder assembles the document
+type builder struct {
+	doc *Document
+	gen *generator
+}
+
+// add registers an operation under a gin route
+func (b *builder) add(method, route string, op *Operation) {
+	path := toPath(route)
+	item, exists := b.doc.Paths[path]
+	if !exists {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+
+	// Path parameters are taken from the route
+	for _, segment := range strings.Split(route, "/") {
+		if strings.HasPrefix(segment, ":") {
+			op.Parameters = append([]*Parameter{{
+				Name:     segment[1:],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			}}, op.Parameters...)
+		}
+	}
+	if op.Responses == nil {
+		op.Responses = make(map[string]*Response)
+	}
+
+	(*item)[strings.ToLower(method)] = op
+}
+
+// define registers a struct under the given component name and returns a reference to it
+func (b *builder) define(name string, v interface{}) *Schema {
+	b.gen.schemas[name] = b.gen.object(reflect.TypeOf(v))
+	return &Schema{Ref: componentPrefix + name}
+}
+
+// component returns the component schema generated for a models type
+func (b *builder) component(v interface{}) *Schema {
+	return b.gen.component(v)
+}
+
+func (b *builder) ref(v interface{}) *Schema {
+	return b.gen.schema(v)
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+func jsonResponse(description string, schema *Schema) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+func query(name, description string, schema *Schema) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func str() *Schema     { return &Schema{Type: "string"} }
+func integer() *Schema { return &Schema{Type: "integer"} }
+func object() *Schema  { return &Schema{Type: "object"} }
+
+// Spec builds the OpenAPI document of the REST API
+func Spec() *Document {
+	b := &builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info: Info{
+				Title:       "AbleRefusal API",
+				Description: "Image generation server with a job queue, presets, webhooks and Automatic1111/OpenAI compatible facades.",
+				Version:     APIVersion,
+			},
+			Tags: []Tag{
+				{Name: "generation", Description: "Submit and track generations. With auth enabled each API key only sees its own jobs and history."},
+				{Name: "history", Description: "Finished generations"},
+				{Name: "library", Description: "Collections and tags for organising history"},
+				{Name: "catalogue", Description: "Models, samplers and ControlNets"},
+				{Name: "presets", Description: "Presets and style templates. Creating, changing and deleting them needs one of auth.admin_keys when that list is set."},
+				{Name: "webhooks", Description: "Webhook delivery log"},
+				{Name: "admin", Description: "Storage maintenance. Needs one of auth.admin_keys and is only served when that list is set."},
+				{Name: "system", Description: "Health and API description"},
+				{Name: "automatic1111", Description: "Automatic1111-compatible facade, see the Automatic1111 API for the full schemas"},
+				{Name: "openai", Description: "OpenAI Images API facade, see the OpenAI API reference for the full schemas"},
+			},
+			Paths: make(map[string]*PathItem),
+		},
+		gen: newGenerator(),
+	}
+
+	b.constrainModels()
+	b.nativeAPI()
+	b.compatibilityAPIs()
+
+	b.doc.Components.Schemas = b.gen.schemas
+	return b.doc
+}
+
+// constrainModels adds the static limits of the models to their schemas.
+// Limits that depend on the model profile or the sampler catalogue are
+// checked by the models' Validate methods.
+func (b *builder) constrainModels() {
+	req := b.component(models.GenerationRequest{})
+	req.Required = []string{"prompt"}
+	req.Property("prompt").Length(1, models.MaxPromptLength)
+	req.Property("negative_prompt").Length(0, models.MaxPromptLength)
+	req.Property("width").Min(1).Describe("Image width, limits depend on the model profile")
+	req.Property("height").Min(1).Describe("Image height, limits depend on the model profile")
+	req.Property("steps").Min(1).Describe("Inference steps, the maximum depends on the model profile")
+	req.Property("cfg_scale").Range(1, 30)
+	req.Property("seed").Describe("-1 picks a random seed")
+	req.Property("batch_size").Min(1).Describe("Images per generation, the maximum depends on the model profile")
+	req.Property("sampler").Describe("Sampler name or alias, see GET /api/v1/samplers")
+	req.Property("init_image").Describe("Base64 encoded image for image-to-image")
+	req.Property("strength").Range(0, 1).Describe("Image-to-image denoising strength")
+	req.Property("mask").Describe("Base64 encoded inpainting mask, white areas are repainted. Requires init_image.")
+	req.Property("controlnets").MaxItems = intPtr(models.MaxControlNetUnits)
+	req.Property("prompt_mode").Values("random", "combinatorial").Describe("Dynamic prompt expansion mode")
+	req.Property("prompt_count").Min(0)
+	req.Property("prompt_template").ReadOnly = true
+	req.Property("created_at").ReadOnly = true
+	req.Property("updated_at").ReadOnly = true
+
+	unit := b.component(models.ControlNetUnit{})
+	unit.Required = []string{"type", "image"}
+	unit.Property("weight").Range(0, 2)
+	unit.Property("guidance_start").Range(0, 1)
+	unit.Property("guidance_end").Range(0, 1)
+
+	webhook := b.component(models.WebhookConfig{})
+	webhook.Required = []string{"url"}
+	webhook.Property("url").Format = "uri"
+	webhook.Property("events").Items.Values(models.WebhookEventCompleted, models.WebhookEventFailed, models.WebhookEventCancelled)
+
+	status := b.component(models.GenerationStatus{})
+	status.Property("status").Values(models.StatusQueued, models.StatusProcessing, models.StatusCompleted, models.StatusFailed, models.StatusCancelled)
+
+	// Names are optional in bodies because PUT takes them from the path
+	b.component(models.Preset{}).Property("read_only").ReadOnly = true
+	b.component(models.StyleTemplate{}).Property("read_only").ReadOnly = true
+}
+
+// nativeAPI describes the /api/v1 routes
+func (b *builder) nativeAPI() {
+	errorSchema := b.define("Error", errorResponse{})
+	errorObject := b.gen.schemas["Error"]
+	errorObject.Required = []string{"error", "code", "request_id"}
+	errorObject.Property("code").Describe("Stable, machine-readable error code")
+	errorResp := func(description string) *Response {
+		return jsonResponse(description, errorSchema)
+	}
+	notFound := errorResp("Not found")
+	invalid := errorResp("Invalid request, fields lists every invalid field")
+
+	// System
+	b.add(http.MethodGet, "/api/v1/health", &Operation{
+		OperationID: "health", Summary: "Health check", Tags: []string{"system"},
+		Responses: map[string]*Response{"200": jsonResponse("Server is up", b.define("Health", struct {
+			Status string      `json:"status"`
+			Time   interface{} `json:"time"`
+		}{}))},
+	})
+	b.add(http.MethodGet, "/api/v1/ready", &Operation{
+		OperationID: "ready", Summary: "Readiness and runtime statistics", Tags: []string{"system"},
+		Responses: map[string]*Response{"200": jsonResponse("Server is ready", b.define("Readiness", struct {
+			Status string `json:"status"`
+			System struct {
+				GoVersion   string `json:"go_version"`
+				Goroutines  int    `json:"go_routines"`
+				CPUCount    int    `json:"cpu_count"`
+				MemoryAlloc uint64 `json:"memory_alloc"`
+				MemoryTotal uint64 `json:"memory_total"`
+			} `json:"system"`
+			Services map[string]bool `json:"services"`
+		}{}))},
+	})
+	b.add(http.MethodGet, "/api/v1/openapi.json", &Operation{
+		OperationID: "getOpenAPI", Summary: "This document", Tags: []string{"system"},
+		Responses: map[string]*Response{"200": jsonResponse("OpenAPI document", object())},
+	})
+	b.add(http.MethodGet, "/api/v1/docs", &Operation{
+		OperationID: "getDocs", Summary: "Interactive API documentation", Tags: []string{"system"},
+		Responses: map[string]*Response{"200": {Description: "HTML page", Content: map[string]*MediaType{"text/html": {Schema: str()}}}},
+	})
+
+	// Generation
+	status := b.ref(models.GenerationStatus{})
+	b.add(http.MethodPost, "/api/v1/generate", &Operation{
+		OperationID: "generate",
+		Summary:     "Queue a generation",
+		Description: "Fields left out are filled in from the preset and the server defaults. A dynamic prompt may fan out into several jobs, listed in ids.",
+		Tags:        []string{"generation"},
+		Parameters: []*Parameter{
+			query("wait", "Block until the generation finishes", &Schema{Type: "boolean"}),
+			query("timeout", "Seconds to wait, capped by server.max_wait", &Schema{Type: "number"}),
+			{Name: "Idempotency-Key", In: "header", Description: "Retries with the same key return the original response", Schema: str().Length(1, 255)},
+		},
+		RequestBody: jsonBody(b.ref(models.GenerationRequest{})),
+		Responses: map[string]*Response{
+			"200": {
+				Description: "Finished generation (wait=true). A fanned-out request returns every status.",
+				Content: map[string]*MediaType{
+					"application/json": {Schema: b.define("GenerateResult", struct {
+						ID       string                     `json:"id"`
+						IDs      []string                   `json:"ids"`
+						Statuses []*models.GenerationStatus `json:"statuses"`
+					}{})},
+					"image/png":  {Schema: &Schema{Type: "string", Format: "binary"}},
+					"image/jpeg": {Schema: &Schema{Type: "string", Format: "binary"}},
+					"image/webp": {Schema: &Schema{Type: "string", Format: "binary"}},
+					"image/avif": {Schema: &Schema{Type: "string", Format: "binary"}},
+				},
+			},
+			"202": jsonResponse("Generation queued", b.define("GenerateAccepted", struct {
+				ID       string   `json:"id"`
+				IDs      []string `json:"ids,omitempty"`
+				Status   string   `json:"status"`
+				Position int      `json:"position"`
+				Message  string   `json:"message"`
+			}{})),
+			"400": invalid,
+			"406": errorResp("Accept allows neither JSON nor an available image type (wait=true)"),
+			"409": errorResp("Request with the same Idempotency-Key in progress, or the id is already in use"),
+			"422": errorResp("Idempotency-Key reused with a different body or query"),
+			"503": errorResp("Queue is full"),
+			"507": errorResp("Storage quota or free space floor reached"),
+		},
+	})
+	b.add(http.MethodGet, "/api/v1/generate/:id", &Operation{
+		OperationID: "getGeneration", Summary: "Get the status of a generation", Tags: []string{"generation"},
+		Responses: map[string]*Response{"200": jsonResponse("Generation status", status), "404": notFound},
+	})
+	b.add(http.MethodGet, "/api/v1/generate/:id/events", &Operation{
+		OperationID: "watchGeneration",
+		Summary:     "Stream status updates",
+		Description: "Server-sent events named status, each carrying a GenerationStatus. The stream ends when the generation finishes.",
+		Tags:        []string{"generation"},
+		Responses: map[string]*Response{
+			"200": {Description: "Event stream", Content: map[string]*MediaType{"text/event-stream": {Schema: status}}},
+			"404": notFound,
+		},
+	})
+	b.add(http.MethodPost, "/api/v1/generate/:id/cancel", &Operation{
+		OperationID: "cancelGeneration", Summary: "Cancel a queued or running generation", Tags: []string{"generation"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Generation cancelled", b.define("Cancelled", struct {
+				ID      string `json:"id"`
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			}{})),
+			"404": notFound,
+		},
+	})
+	b.add(http.MethodGet, "/api/v1/queue", &Operation{
+		OperationID: "listQueue", Summary: "List the caller's queued and running generations", Tags: []string{"generation"},
+		Responses: map[string]*Response{"200": jsonResponse("Queue", b.define("Queue", struct {
+			Queue []*models.QueueItem `json:"queue"`
+			Count int                 `json:"count"`
+		}{}))},
+	})
+
+	// History
+	b.add(http.MethodGet, "/api/v1/history", &Operation{
+		OperationID: "listHistory", Summary: "List the caller's finished generations, newest first", Tags: []string{"history"},
+		Parameters: []*Parameter{
+			query("status", "Only entries with this status", str().Values(models.StatusCompleted, models.StatusFailed, models.StatusCancelled)),
+			query("q", "Case-insensitive substring of the prompt", str()),
+			query("model", "Only entries generated with this model", str()),
+			query("tag", "Only entries with this tag", str()),
+			query("favourite", "true for favourites only", &Schema{Type: "boolean"}),
+			query("min_rating", "Only entries rated at least this", integer().Range(1, models.MaxRating)),
+			query("collection", "Only entries in this collection", str()),
+			query("limit", "Maximum number of entries (default 50)", integer().Min(1)),
+			query("offset", "Number of entries to skip", integer().Min(0)),
+		},
+		Responses: map[string]*Response{
+			"200": jsonResponse("History page", b.define("HistoryPage", struct {
+				Entries []*models.HistoryEntry `json:"entries"`
+				Count   int                    `json:"count"`
+				Total   int                    `json:"total"`
+			}{})),
+			"400": invalid,
+		},
+	})
+	b.add(http.MethodGet, "/api/v1/history/:id", &Operation{
+		OperationID: "getHistoryEntry", Summary: "Get a finished generation", Tags: []string{"history"},
+		Responses: map[string]*Response{"200": jsonResponse("History entry", b.ref(models.HistoryEntry{})), "404": notFound},
+	})
+	b.add(http.MethodPut, "/api/v1/history/:id/pin", &Operation{
+		OperationID: "pinHistoryEntry", Summary: "Protect a generation's outputs from cleanup", Tags: []string{"history"},
+		Responses: map[string]*Response{"200": jsonResponse("History entry", b.ref(models.HistoryEntry{})), "404": notFound},
+	})
+	b.add(http.MethodDelete, "/api/v1/history/:id/pin", &Operation{
+		OperationID: "unpinHistoryEntry", Summary: "Let the retention policy apply to a generation's outputs again", Tags: []string{"history"},
+		Responses: map[string]*Response{"200": jsonResponse("History entry", b.ref(models.HistoryEntry{})), "404": notFound},
+	})
+	b.add(http.MethodPut, "/api/v1/history/:id/favourite", &Operation{
+		OperationID: "favouriteHistoryEntry", Summary: "Mark a generation as a favourite, which also protects it from cleanup", Tags: []string{"history"},
+		Responses: map[string]*Response{"200": jsonResponse("History entry", b.ref(models.HistoryEntry{})), "404": notFound},
+	})
+	b.add(http.MethodDelete, "/api/v1/history/:id/favourite", &Operation{
+		OperationID: "unfavouriteHistoryEntry", Summary: "Remove a generation from the favourites", Tags: []string{"history"},
+		Responses: map[string]*Response{"200": jsonResponse("History entry", b.ref(models.HistoryEntry{})), "404": notFound},
+	})
+	b.add(http.MethodPut, "/api/v1/history/:id/rating", &Operation{
+		OperationID: "rateHistoryEntry", Summary: "Rate a generation", Tags: []string{"history"},
+		RequestBody: jsonBody(b.define("RatingBody", models.RatingRequest{})),
+		Responses:   map[string]*Response{"200": jsonResponse("History entry", b.ref(models.HistoryEntry{})), "400": invalid, "404": notFound},
+	})
+	rating := b.gen.schemas["RatingBody"]
+	rating.Required = []string{"rating"}
+	rating.Property("rating").Range(1, models.MaxRating)
+	b.add(http.MethodDelete, "/api/v1/history/:id/rating", &Operation{
+		OperationID: "clearHistoryRating", Summary: "Remove a generation's rating", Tags: []string{"history"},
+		Responses: map[string]*Response{"200": jsonResponse("History entry", b.ref(models.HistoryEntry{})), "404": notFound},
+	})
+	b.add(http.MethodPut, "/api/v1/history/:id/tags", &Operation{
+		OperationID: "setHistoryTags", Summary: "Replace a generation's tags",
+		Description: "Tags are trimmed, lower-cased and deduplicated. Send an empty list to remove every tag.",
+		Tags:        []string{"history"},
+		RequestBody: jsonBody(b.define("TagsBody", models.TagsRequest{})),
+		Responses:   map[string]*Response{"200": jsonResponse("History entry", b.ref(models.HistoryEntry{})), "400": invalid, "404": notFound},
+	})
+	tags := b.gen.schemas["TagsBody"]
+	tags.Required = []string{"tags"}
+	tags.Property("tags").MaxItems = intPtr(models.MaxTagsPerEntry)
+
+	// Tags and collections
+	b.add(http.MethodGet, "/api/v1/tags", &Operation{
+		OperationID: "listTags", Summary: "List tags with the number of entries using them", Tags: []string{"library"},
+		Responses: map[string]*Response{"200": jsonResponse("Tags", b.define("TagList", struct {
+			Tags  []models.TagCount `json:"tags"`
+			Count int               `json:"count"`
+		}{}))},
+	})
+	b.add(http.MethodDelete, "/api/v1/tags/:tag", &Operation{
+		OperationID: "deleteTag", Summary: "Remove a tag from every entry", Tags: []string{"library"},
+		Responses: map[string]*Response{"200": jsonResponse("Tag removed", b.define("TagDeleted", struct {
+			Tag     string `json:"tag"`
+			Removed int    `json:"removed"`
+		}{}))},
+	})
+
+	collection := b.ref(models.Collection{})
+	collectionBody := b.define("CollectionBody", models.CollectionRequest{})
+	body := b.gen.schemas["CollectionBody"]
+	body.Required = []string{"name"}
+	body.Property("name").Length(1, models.MaxCollectionName)
+	b.add(http.MethodGet, "/api/v1/collections", &Operation{
+		OperationID: "listCollections", Summary: "List collections", Tags: []string{"library"},
+		Responses: map[string]*Response{"200": jsonResponse("Collections", b.define("CollectionList", struct {
+			Collections []*models.Collection `json:"collections"`
+			Count       int                  `json:"count"`
+		}{}))},
+	})
+	b.add(http.MethodPost, "/api/v1/collections", &Operation{
+		OperationID: "createCollection", Summary: "Create a collection", Tags: []string{"library"},
+		RequestBody: jsonBody(collectionBody),
+		Responses: map[string]*Response{
+			"201": jsonResponse("Collection created", collection),
+			"400": invalid,
+			"409": errorResp("A collection with this name already exists"),
+		},
+	})
+	b.add(http.MethodGet, "/api/v1/collections/:id", &Operation{
+		OperationID: "getCollection", Summary: "Get a collection", Tags: []string{"library"},
+		Responses: map[string]*Response{"200": jsonResponse("Collection", collection), "404": notFound},
+	})
+	b.add(http.MethodPut, "/api/v1/collections/:id", &Operation{
+		OperationID: "updateCollection", Summary: "Rename a collection or change its description", Tags: []string{"library"},
+		RequestBody: jsonBody(collectionBody),
+		Responses: map[string]*Response{
+			"200": jsonResponse("Collection saved", collection),
+			"400": invalid,
+			"404": notFound,
+			"409": errorResp("A collection with this name already exists"),
+		},
+	})
+	b.add(http.MethodDelete, "/api/v1/collections/:id", &Operation{
+		OperationID: "deleteCollection", Summary: "Delete a collection, its entries stay in history", Tags: []string{"library"},
+		Responses: map[string]*Response{"204": {Description: "Collection deleted"}, "404": notFound},
+	})
+	b.add(http.MethodPut, "/api/v1/collections/:id/entries/:entry_id", &Operation{
+		OperationID: "addCollectionEntry", Summary: "Add a history entry to a collection", Tags: []string{"library"},
+		Responses: map[string]*Response{"200": jsonResponse("Collection", collection), "404": notFound},
+	})
+	b.add(http.MethodDelete, "/api/v1/collections/:id/entries/:entry_id", &Operation{
+		OperationID: "removeCollectionEntry", Summary: "Remove a history entry from a collection", Tags: []string{"library"},
+		Responses: map[string]*Response{"200": jsonResponse("Collection", collection), "404": notFound},
+	})
+
+	// Export and import
+	exportJob := b.ref(models.ExportJob{})
+	b.component(models.ExportJob{}).Property("status").Values(models.ExportQueued, models.ExportRunning, models.ExportCompleted, models.ExportFailed)
+	b.add(http.MethodPost, "/api/v1/exports", &Operation{
+		OperationID: "createExport",
+		Summary:     "Export generations as a ZIP archive",
+		Description: "Builds the archive in the background. Every history entry of the caller's API key matching the filter is exported with its images and a manifest.json and manifest.csv. Send {} to export everything.",
+		Tags:        []string{"history"},
+		RequestBody: jsonBody(b.ref(models.ExportFilter{})),
+		Responses:   map[string]*Response{"202": jsonResponse("Export queued", exportJob), "400": invalid, "507": errorResp("Storage quota reached")},
+	})
+	b.add(http.MethodGet, "/api/v1/exports", &Operation{
+		OperationID: "listExports", Summary: "List the caller's exports since startup, newest first", Tags: []string{"history"},
+		Responses: map[string]*Response{"200": jsonResponse("Exports", b.define("ExportList", struct {
+			Exports []*models.ExportJob `json:"exports"`
+			Count   int                 `json:"count"`
+		}{}))},
+	})
+	b.add(http.MethodGet, "/api/v1/exports/:id", &Operation{
+		OperationID: "getExport", Summary: "Get the progress of an export", Tags: []string{"history"},
+		Responses: map[string]*Response{"200": jsonResponse("Export", exportJob), "404": notFound},
+	})
+	b.add(http.MethodGet, "/api/v1/exports/:id/download", &Operation{
+		OperationID: "downloadExport", Summary: "Download a completed export", Tags: []string{"history"},
+		Responses: map[string]*Response{
+			"200": {Description: "ZIP archive", Content: map[string]*MediaType{"application/zip": {Schema: &Schema{Type: "string", Format: "binary"}}}},
+			"404": notFound,
+			"409": errorResp("Export is not completed"),
+		},
+	})
+	b.add(http.MethodPost, "/api/v1/imports", &Operation{
+		OperationID: "importExport",
+		Summary:     "Restore an export archive into history",
+		Description: "Imported entries belong to the caller's API key and their images are stored under new names. Entries already in the caller's history are skipped along with their images, entries whose ID another key uses get a new ID.",
+		Tags:        []string{"history"},
+		RequestBody: &RequestBody{Required: true, Content: map[string]*MediaType{
+			"application/zip": {Schema: &Schema{Type: "string", Format: "binary"}},
+			"multipart/form-data": {Schema: b.define("ImportForm", struct {
+				Archive string `json:"archive"`
+			}{})},
+		}},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Import report", b.ref(models.ImportReport{})),
+			"400": invalid,
+			"413": errorResp("Archive or image too large"),
+			"507": errorResp("Storage quota reached"),
+		},
+	})
+
+	// Catalogue
+	b.add(http.MethodGet, "/api/v1/models", &Operation{
+		OperationID: "listModels", Summary: "List models", Tags: []string{"catalogue"},
+		Responses: map[string]*Response{"200": jsonResponse("Models", b.define("ModelList", struct {
+			Models []struct {
+				ID          string `json:"id"`
+				Name        string `json:"name"`
+				Type        string `json:"type"`
+				Version     string `json:"version"`
+				Description string `json:"description"`
+				Ready       bool   `json:"ready"`
+			} `json:"models"`
+		}{}))},
+	})
+	b.add(http.MethodGet, "/api/v1/controlnets", &Operation{
+		OperationID: "listControlNets", Summary: "List ControlNet models", Tags: []string{"catalogue"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("ControlNet models", b.define("ControlNetList", struct {
+				ControlNets []models.ControlNetModel `json:"controlnets"`
+				Count       int                      `json:"count"`
+			}{})),
+			"502": errorResp("Inference backend unavailable"),
+		},
+	})
+	b.add(http.MethodGet, "/api/v1/samplers", &Operation{
+		OperationID: "listSamplers", Summary: "List samplers and schedulers", Tags: []string{"catalogue"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Sampler catalogue", b.ref(models.SamplerCatalogue{})),
+			"502": errorResp("Inference backend unavailable"),
+		},
+	})
+
+	// Presets and styles
+	preset := b.ref(models.Preset{})
+	presetErrors := func(responses map[string]*Response) map[string]*Response {
+		responses["400"] = invalid
+		responses["403"] = errorResp("Presets and styles defined in config are read-only, or not an admin key while auth.admin_keys is set")
+		return responses
+	}
+	b.add(http.MethodGet, "/api/v1/presets", &Operation{
+		OperationID: "listPresets", Summary: "List presets", Tags: []string{"presets"},
+		Responses: map[string]*Response{"200": jsonResponse("Presets", b.define("PresetList", struct {
+			Presets []*models.Preset `json:"presets"`
+			Count   int              `json:"count"`
+		}{}))},
+	})
+	b.add(http.MethodPost, "/api/v1/presets", &Operation{
+		OperationID: "createPreset", Summary: "Create a preset", Tags: []string{"presets"},
+		RequestBody: jsonBody(preset),
+		Responses: presetErrors(map[string]*Response{
+			"201": jsonResponse("Preset created", preset),
+			"409": errorResp("Preset already exists"),
+		}),
+	})
+	b.add(http.MethodGet, "/api/v1/presets/:name", &Operation{
+		OperationID: "getPreset", Summary: "Get a preset", Tags: []string{"presets"},
+		Responses: map[string]*Response{"200": jsonResponse("Preset", preset), "404": notFound},
+	})
+	b.add(http.MethodPut, "/api/v1/presets/:name", &Operation{
+		OperationID: "updatePreset", Summary: "Create or replace a preset", Tags: []string{"presets"},
+		Description: "The name in the path wins over the name in the body.",
+		RequestBody: jsonBody(preset),
+		Responses:   presetErrors(map[string]*Response{"200": jsonResponse("Preset saved", preset)}),
+	})
+	b.add(http.MethodDelete, "/api/v1/presets/:name", &Operation{
+		OperationID: "deletePreset", Summary: "Delete a preset", Tags: []string{"presets"},
+		Responses: presetErrors(map[string]*Response{"204": {Description: "Preset deleted"}, "404": notFound}),
+	})
+
+	style := b.ref(models.StyleTemplate{})
+	b.add(http.MethodGet, "/api/v1/styles", &Operation{
+		OperationID: "listStyles", Summary: "List style templates", Tags: []string{"presets"},
+		Responses: map[string]*Response{"200": jsonResponse("Styles", b.define("StyleList", struct {
+			Styles []*models.StyleTemplate `json:"styles"`
+			Count  int                     `json:"count"`
+		}{}))},
+	})
+	b.add(http.MethodPost, "/api/v1/styles", &Operation{
+		OperationID: "createStyle", Summary: "Create a style template", Tags: []string{"presets"},
+		RequestBody: jsonBody(style),
+		Responses: presetErrors(map[string]*Response{
+			"201": jsonResponse("Style created", style),
+			"409": errorResp("Style already exists"),
+		}),
+	})
+	b.add(http.MethodGet, "/api/v1/styles/:name", &Operation{
+		OperationID: "getStyle", Summary: "Get a style template", Tags: []string{"presets"},
+		Responses: map[string]*Response{"200": jsonResponse("Style", style), "404": notFound},
+	})
+	b.add(http.MethodPut, "/api/v1/styles/:name", &Operation{
+		OperationID: "updateStyle", Summary: "Create or replace a style template", Tags: []string{"presets"},
+		Description: "The name in the path wins over the name in the body.",
+		RequestBody: jsonBody(style),
+		Responses:   presetErrors(map[string]*Response{"200": jsonResponse("Style saved", style)}),
+	})
+	b.add(http.MethodDelete, "/api/v1/styles/:name", &Operation{
+		OperationID: "deleteStyle", Summary: "Delete a style template", Tags: []string{"presets"},
+		Responses: presetErrors(map[string]*Response{"204": {Description: "Style deleted"}, "404": notFound}),
+	})
+
+	// Webhooks
+	delivery := b.ref(models.WebhookDelivery{})
+	b.component(models.WebhookDelivery{}).Property("status").Values(models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed)
+	b.add(http.MethodGet, "/api/v1/webhooks/deliveries", &Operation{
+		OperationID: "listWebhookDeliveries", Summary: "List webhook deliveries, newest first", Tags: []string{"webhooks"},
+		Parameters: []*Parameter{
+			query("job_id", "Only deliveries for this generation", str()),
+			query("status", "Only deliveries with this status", str().Values(models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed)),
+			query("limit", "Maximum number of deliveries (default 100)", integer().Min(1)),
+		},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Deliveries", b.define("WebhookDeliveryList", struct {
+				Deliveries []*models.WebhookDelivery `json:"deliveries"`
+				Count      int                       `json:"count"`
+			}{})),
+			"400": invalid,
+		},
+	})
+	b.add(http.MethodPost, "/api/v1/webhooks/deliveries/:id/replay", &Operation{
+		OperationID: "replayWebhookDelivery", Summary: "Send a delivery again", Tags: []string{"webhooks"},
+		Responses: map[string]*Response{
+			"202": jsonResponse("Replay queued", delivery),
+			"404": notFound,
+			"409": errorResp("Delivery cannot be replayed"),
+		},
+	})
+
+	// Storage maintenance
+	b.component(models.CleanupItem{}).Property("reason").Values(models.CleanupExpired, models.CleanupOverSize, models.CleanupTempExpired, models.CleanupOrphaned)
+	b.add(http.MethodGet, "/api/v1/admin/cleanup", &Operation{
+		OperationID: "getCleanupStats", Summary: "Storage cleanup metrics since startup", Tags: []string{"admin"},
+		Responses: map[string]*Response{"200": jsonResponse("Cleanup metrics", b.ref(models.CleanupStats{})), "401": errorResp("Missing API key"), "403": errorResp("Not an admin key")},
+	})
+	b.add(http.MethodPost, "/api/v1/admin/cleanup", &Operation{
+		OperationID: "runCleanup", Summary: "Apply the retention policy now", Tags: []string{"admin"},
+		Parameters: []*Parameter{
+			query("dry_run", "Only report what would be removed", &Schema{Type: "boolean"}),
+		},
+		Responses: map[string]*Response{"200": jsonResponse("Cleanup report", b.ref(models.CleanupReport{})), "400": invalid, "401": errorResp("Missing API key"), "403": errorResp("Not an admin key")},
+	})
+}
+
+// compatibilityAPIs describes the Automatic1111 and OpenAI facades. Their
+// bodies follow the upstream APIs, so only the outline is documented here
+// and they are not validated against this document.
+func (b *builder) compatibilityAPIs() {
+	a1111 := func(method, route, id, summary string, body bool) {
+		op := &Operation{
+			OperationID: id, Summary: summary, Tags: []string{"automatic1111"},
+			Responses: map[string]*Response{"200": jsonResponse("Automatic1111 response", object())},
+		}
+		if body {
+			op.RequestBody = jsonBody(object())
+			op.Responses["422"] = jsonResponse("Validation error", object())
+		}
+		b.add(method, route, op)
+	}
+	a1111(http.MethodPost, "/sdapi/v1/txt2img", "a1111Txt2Img", "Text-to-image", true)
+	a1111(http.MethodPost, "/sdapi/v1/img2img", "a1111Img2Img", "Image-to-image and inpainting", true)
+	a1111(http.MethodGet, "/sdapi/v1/progress", "a1111Progress", "Progress of the running generation", false)
+	a1111(http.MethodPost, "/sdapi/v1/interrupt", "a1111Interrupt", "Cancel the running generation", false)
+	a1111(http.MethodGet, "/sdapi/v1/sd-models", "a1111Models", "List checkpoints", false)
+	a1111(http.MethodGet, "/sdapi/v1/samplers", "a1111Samplers", "List samplers", false)
+
+	images := jsonResponse("Generated images", object())
+	openAIError := jsonResponse("OpenAI error", object())
+	b.add(http.MethodPost, "/v1/images/generations", &Operation{
+		OperationID: "openAIGenerations", Summary: "Create images", Tags: []string{"openai"},
+		RequestBody: jsonBody(object()),
+		Responses:   map[string]*Response{"200": images, "400": openAIError, "404": openAIError},
+	})
+	b.add(http.MethodPost, "/v1/images/edits", &Operation{
+		OperationID: "openAIEdits", Summary: "Edit or inpaint an image", Tags: []string{"openai"},
+		RequestBody: &RequestBody{Required: true, Content: map[string]*MediaType{"multipart/form-data": {Schema: object()}}},
+		Responses:   map[string]*Response{"200": images, "400": openAIError, "404": openAIError},
+	})
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,157 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// Validate checks a decoded JSON value against a schema and reports every
+// violation as a field error. Unknown object properties are allowed.
+func (d *Document) Validate(schema *Schema, value interface{}) models.ValidationErrors {
+	var errs models.ValidationErrors
+	d.validate(schema, "", value, &errs)
+	return errs
+}
+
+func (d *Document) validate(schema *Schema, path string, value interface{}, errs *models.ValidationErrors) {
+	schema = d.Resolve(schema)
+	if schema == nil {
+		return
+	}
+
+	fail := func(format string, args ...interface{}) {
+		field := path
+		if field == "" {
+			field = "body"
+		}
+		*errs = append(*errs, &models.FieldError{
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+			Err:     models.ErrInvalidRequest,
+		})
+	}
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			fail("must not be null")
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range schema.Required {
+			if _, exists := object[name]; !exists {
+				d.validateMissing(join(path, name), errs)
+			}
+		}
+
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, exists := schema.Properties[name]; exists {
+				d.validate(property, join(path, name), object[name], errs)
+			} else if schema.AdditionalProperties != nil {
+				d.validate(schema.AdditionalProperties, join(path, name), object[name], errs)
+			}
+		}
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			fail("must have at most %d items", *schema.MaxItems)
+		}
+		for i, item := range items {
+			d.validate(schema.Items, fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		length := len(s)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			if *schema.MinLength == 1 {
+				fail("cannot be empty")
+			} else {
+				fail("must be at least %d characters", *schema.MinLength)
+			}
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("must be at most %d characters", *schema.MaxLength)
+		}
+
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if schema.Type == "integer" && n != math.Trunc(n) {
+			fail("must be an integer")
+			return
+		}
+		switch {
+		case schema.Minimum != nil && schema.Maximum != nil && (n < *schema.Minimum || n > *schema.Maximum):
+			fail("must be between %g and %g", *schema.Minimum, *schema.Maximum)
+		case schema.Minimum != nil && n < *schema.Minimum:
+			fail("must be at least %g", *schema.Minimum)
+		case schema.Maximum != nil && n > *schema.Maximum:
+			fail("must be at most %g", *schema.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+			return
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		for _, allowed := range schema.Enum {
+			if allowed == value {
+				return
+			}
+		}
+		values := make([]string, len(schema.Enum))
+		for i, allowed := range schema.Enum {
+			values[i] = fmt.Sprintf("%q", allowed)
+		}
+		fail("must be one of %s", strings.Join(values, ", "))
+	}
+}
+
+// validateMissing reports a required property that is not present
+func (d *Document) validateMissing(path string, errs *models.ValidationErrors) {
+	*errs = append(*errs, &models.FieldError{
+		Field:   path,
+		Message: "is required",
+		Err:     models.ErrInvalidRequest,
+	})
+}
+
+// join builds the dotted path of a property
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
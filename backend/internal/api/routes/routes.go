@@ -2,55 +2,118 @@ package routes
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/ablerefusal/ablerefusal/internal/api/handlers"
 	"github.com/ablerefusal/ablerefusal/internal/api/middleware"
+	"github.com/ablerefusal/ablerefusal/internal/api/openapi"
 	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/export"
+	"github.com/ablerefusal/ablerefusal/internal/generation"
+	"github.com/ablerefusal/ablerefusal/internal/history"
+	"github.com/ablerefusal/ablerefusal/internal/idempotency"
+	"github.com/ablerefusal/ablerefusal/internal/inference"
+	"github.com/ablerefusal/ablerefusal/internal/library"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/presets"
 	"github.com/ablerefusal/ablerefusal/internal/queue"
 	"github.com/ablerefusal/ablerefusal/internal/storage"
-	"github.com/gin-contrib/cors"
+	"github.com/ablerefusal/ablerefusal/internal/webhook"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
 // Setup initializes and returns the router with all routes
-func Setup(cfg *config.Config, queueManager queue.Manager, storageManager storage.Manager, logger *logrus.Logger) *gin.Engine {
+func Setup(cfg *config.Config, watcher *config.Watcher, queueManager queue.Manager, storageManager storage.Manager, inferenceEngine inference.Engine, presetManager presets.Manager, webhookManager webhook.Manager, generationManager generation.Manager, historyManager history.Manager, libraryManager library.Manager, exportManager export.Manager, janitor storage.Janitor, logger *logrus.Logger) *gin.Engine {
 	router := gin.New()
 
 	// Add middleware
 	router.Use(gin.Recovery())
 	router.Use(middleware.Logger(logger))
 	router.Use(middleware.RequestID())
+	router.Use(middleware.Errors())
 
-	// Configure CORS
-	if cfg.Server.EnableCORS {
-		corsConfig := cors.DefaultConfig()
-		corsConfig.AllowOrigins = []string{"http://localhost:3000", "http://localhost:1420"}
-		corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-		corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-		corsConfig.AllowCredentials = true
-		router.Use(cors.New(corsConfig))
-	}
+	// Configure CORS, following edits of the config file
+	corsMiddleware := middleware.NewCORS(cfg.Server)
+	watcher.Subscribe(func(change *config.Change) {
+		if change.Changed("server") {
+			corsMiddleware.Update(change.New.Server)
+		}
+	})
+	router.Use(corsMiddleware.Handler())
+
+	// Identify clients, rejecting unknown API keys when auth is enabled
+	router.Use(middleware.APIKey(cfg.Auth))
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler(logger)
-	generationHandler := handlers.NewGenerationHandler(queueManager, logger)
+	generationHandler := handlers.NewGenerationHandler(queueManager, generationManager, storageManager, cfg, logger)
 	statusHandler := handlers.NewStatusHandler(queueManager, logger)
-	// staticHandler := handlers.NewStaticHandler(storageManager, logger) // TODO: Implement when needed
+	controlNetHandler := handlers.NewControlNetHandler(inferenceEngine, logger)
+	samplerHandler := handlers.NewSamplerHandler(inferenceEngine, logger)
+	presetHandler := handlers.NewPresetHandler(presetManager, logger)
+	webhookHandler := handlers.NewWebhookHandler(webhookManager, logger)
+	historyHandler := handlers.NewHistoryHandler(historyManager, libraryManager, storageManager, logger)
+	libraryHandler := handlers.NewLibraryHandler(libraryManager, historyManager, logger)
+	exportHandler := handlers.NewExportHandler(exportManager, cfg.Storage, logger)
+	adminHandler := handlers.NewAdminHandler(janitor, logger)
+	a1111Handler := handlers.NewA1111Handler(queueManager, generationManager, inferenceEngine, storageManager, cfg, logger)
+	openAIHandler := handlers.NewOpenAIHandler(queueManager, generationManager, storageManager, cfg, logger)
+	apiSpec := openapi.Spec()
+	openAPIHandler := handlers.NewOpenAPIHandler(apiSpec, logger)
+	staticHandler := handlers.NewStaticHandler(storageManager, cfg.Storage, cfg.Auth, logger)
+
+	// Idempotency-Key support for generation requests
+	idempotencyStore := idempotency.NewMemoryStore(time.Duration(cfg.Server.IdempotencyWindow) * time.Second)
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
+	v1.Use(middleware.ValidateRequest(apiSpec, logger))
 	{
 		// Health check
 		v1.GET("/health", healthHandler.Health)
 		v1.GET("/ready", healthHandler.Ready)
 
+		// API description
+		v1.GET("/openapi.json", openAPIHandler.Spec)
+		v1.GET("/docs", openAPIHandler.Docs)
+
 		// Generation endpoints
-		v1.POST("/generate", generationHandler.Generate)
+		v1.POST("/generate", middleware.Idempotency(idempotencyStore, logger), generationHandler.Generate)
 		v1.GET("/generate/:id", statusHandler.GetStatus)
+		v1.GET("/generate/:id/events", statusHandler.Events)
 		v1.POST("/generate/:id/cancel", generationHandler.Cancel)
 		v1.GET("/queue", statusHandler.GetQueue)
 
+		// Generation history
+		v1.GET("/history", historyHandler.List)
+		v1.GET("/history/:id", historyHandler.Get)
+		v1.PUT("/history/:id/pin", historyHandler.Pin)
+		v1.DELETE("/history/:id/pin", historyHandler.Unpin)
+		v1.PUT("/history/:id/favourite", historyHandler.Favourite)
+		v1.DELETE("/history/:id/favourite", historyHandler.Unfavourite)
+		v1.PUT("/history/:id/rating", historyHandler.Rate)
+		v1.DELETE("/history/:id/rating", historyHandler.ClearRating)
+		v1.PUT("/history/:id/tags", historyHandler.SetTags)
+
+		// Tags and collections for organising history
+		v1.GET("/tags", libraryHandler.ListTags)
+		v1.DELETE("/tags/:tag", libraryHandler.DeleteTag)
+		v1.GET("/collections", libraryHandler.ListCollections)
+		v1.POST("/collections", libraryHandler.CreateCollection)
+		v1.GET("/collections/:id", libraryHandler.GetCollection)
+		v1.PUT("/collections/:id", libraryHandler.UpdateCollection)
+		v1.DELETE("/collections/:id", libraryHandler.DeleteCollection)
+		v1.PUT("/collections/:id/entries/:entry_id", libraryHandler.AddEntry)
+		v1.DELETE("/collections/:id/entries/:entry_id", libraryHandler.RemoveEntry)
+
+		// Export and import of generations as ZIP archives
+		v1.POST("/exports", exportHandler.Create)
+		v1.GET("/exports", exportHandler.List)
+		v1.GET("/exports/:id", exportHandler.Get)
+		v1.GET("/exports/:id/download", exportHandler.Download)
+		v1.POST("/imports", exportHandler.Import)
+
 		// Model endpoints
 		v1.GET("/models", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{
@@ -82,20 +145,78 @@ func Setup(cfg *config.Config, queueManager queue.Manager, storageManager storag
 				},
 			})
 		})
+
+		// ControlNet endpoints
+		v1.GET("/controlnets", controlNetHandler.List)
+
+		// Sampler endpoints
+		v1.GET("/samplers", samplerHandler.List)
+
+		// Preset and style template endpoints. Presets and styles are shared
+		// by every key, so writes need an admin key when admin keys are set.
+		presetWrites := v1.Group("")
+		if len(cfg.Auth.AdminKeys) > 0 {
+			presetWrites.Use(middleware.AdminKey(cfg.Auth))
+		}
+		v1.GET("/presets", presetHandler.ListPresets)
+		presetWrites.POST("/presets", presetHandler.CreatePreset)
+		v1.GET("/presets/:name", presetHandler.GetPreset)
+		presetWrites.PUT("/presets/:name", presetHandler.UpdatePreset)
+		presetWrites.DELETE("/presets/:name", presetHandler.DeletePreset)
+		v1.GET("/styles", presetHandler.ListStyles)
+		presetWrites.POST("/styles", presetHandler.CreateStyle)
+		v1.GET("/styles/:name", presetHandler.GetStyle)
+		presetWrites.PUT("/styles/:name", presetHandler.UpdateStyle)
+		presetWrites.DELETE("/styles/:name", presetHandler.DeleteStyle)
+
+		// Webhook delivery log
+		v1.GET("/webhooks/deliveries", webhookHandler.ListDeliveries)
+		v1.POST("/webhooks/deliveries/:id/replay", webhookHandler.ReplayDelivery)
+
+		// Storage maintenance, only with admin keys configured
+		if len(cfg.Auth.AdminKeys) > 0 {
+			admin := v1.Group("/admin", middleware.AdminKey(cfg.Auth))
+			admin.GET("/cleanup", adminHandler.CleanupStats)
+			admin.POST("/cleanup", adminHandler.Cleanup)
+		}
+	}
+
+	// Automatic1111-compatible API for existing plugins and scripts
+	sdapi := router.Group("/sdapi/v1")
+	{
+		sdapi.POST("/txt2img", a1111Handler.Txt2Img)
+		sdapi.POST("/img2img", a1111Handler.Img2Img)
+		sdapi.GET("/progress", a1111Handler.Progress)
+		sdapi.POST("/interrupt", a1111Handler.Interrupt)
+		sdapi.GET("/sd-models", a1111Handler.SDModels)
+		sdapi.GET("/samplers", a1111Handler.Samplers)
 	}
 
-	// Static file serving for generated images
-	router.Static("/outputs", cfg.Storage.OutputDir)
+	// OpenAI Images API for apps using the OpenAI SDK
+	openAI := router.Group("/v1/images")
+	{
+		openAI.POST("/generations", openAIHandler.Generations)
+		openAI.POST("/edits", openAIHandler.Edits)
+	}
+
+	// Generated images, served from the storage backend
+	router.GET("/outputs/*filepath", staticHandler.ServeImage)
+	router.HEAD("/outputs/*filepath", staticHandler.ServeImage)
 
 	// WebSocket endpoint for real-time updates (placeholder)
 	router.GET("/ws", func(c *gin.Context) {
-		c.JSON(http.StatusNotImplemented, gin.H{"error": "WebSocket support coming soon"})
+		c.Error(models.NewError(models.CodeNotImplemented, http.StatusNotImplemented, "WebSocket support coming soon"))
 	})
 
 	// Catch-all 404
 	router.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "endpoint not found"})
+		c.Error(models.NewError(models.CodeEndpointNotFound, http.StatusNotFound, "Endpoint not found").WithDetail("path", c.Request.URL.Path))
 	})
 
+	// Keep the OpenAPI document in sync with the registered routes
+	for _, route := range apiSpec.Undocumented(router.Routes()) {
+		logger.WithField("route", route).Warn("Route is missing from the OpenAPI document")
+	}
+
 	return router
 }
\ No newline at end of file
@@ -0,0 +1,242 @@
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/api/openapi"
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// requestBodies maps each route taking a JSON body to the models type its
+// handler decodes. The compatibility facades follow upstream schemas and are
+// documented as plain objects, so they are not listed.
+var requestBodies = map[string]interface{}{
+	"POST /api/v1/generate":          models.GenerationRequest{},
+	"PUT /api/v1/history/:id/rating": models.RatingRequest{},
+	"PUT /api/v1/history/:id/tags":   models.TagsRequest{},
+	"POST /api/v1/collections":       models.CollectionRequest{},
+	"PUT /api/v1/collections/:id":    models.CollectionRequest{},
+	"POST /api/v1/exports":           models.ExportFilter{},
+	"POST /api/v1/presets":           models.Preset{},
+	"PUT /api/v1/presets/:name":      models.Preset{},
+	"POST /api/v1/styles":            models.StyleTemplate{},
+	"PUT /api/v1/styles/:name":       models.StyleTemplate{},
+}
+
+// newRouter sets up the router with every optional route enabled. Handlers
+// are not called, so the managers are left nil.
+func newRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg := &config.Config{}
+	cfg.Auth.AdminKeys = []string{"admin"}
+	return Setup(cfg, config.NewWatcher(cfg, logger), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+}
+
+// servedSpec fetches the OpenAPI document the router serves
+func servedSpec(t *testing.T, router *gin.Engine) *openapi.Document {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /api/v1/openapi.json = %d", w.Code)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode the OpenAPI document: %v", err)
+	}
+	return &doc
+}
+
+func TestSpecCoversRoutes(t *testing.T) {
+	router := newRouter(t)
+	doc := servedSpec(t, router)
+
+	if missing := doc.Undocumented(router.Routes()); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI document: %v", missing)
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+toPath(route.Path)] = true
+	}
+	var stale []string
+	for path, item := range doc.Paths {
+		for method := range *item {
+			if operation := strings.ToUpper(method) + " " + path; !registered[operation] {
+				stale = append(stale, operation)
+			}
+		}
+	}
+	sort.Strings(stale)
+	if len(stale) > 0 {
+		t.Errorf("documented operations without a route: %v", stale)
+	}
+}
+
+func TestSpecRequestBodiesMatchModels(t *testing.T) {
+	router := newRouter(t)
+	doc := servedSpec(t, router)
+
+	for _, route := range router.Routes() {
+		name := route.Method + " " + route.Path
+		operation := doc.Operation(route.Method, route.Path)
+		if operation == nil || operation.RequestBody == nil {
+			if _, listed := requestBodies[name]; listed {
+				t.Errorf("%s: no request body documented", name)
+			}
+			continue
+		}
+		media, ok := operation.RequestBody.Content["application/json"]
+		if !ok {
+			continue
+		}
+
+		body, listed := requestBodies[name]
+		if !listed {
+			if schema := doc.Resolve(media.Schema); schema.Type != "object" || len(schema.Properties) > 0 {
+				t.Errorf("%s: documented request body has no models type in requestBodies", name)
+			}
+			continue
+		}
+		compareSchema(t, doc, name, media.Schema, reflect.TypeOf(body), make(map[reflect.Type]bool))
+	}
+}
+
+// compareSchema reports where a documented schema differs from the JSON
+// encoding of a Go type: missing or extra properties, mismatched types and
+// required fields that do not exist
+func compareSchema(t *testing.T, doc *openapi.Document, path string, schema *openapi.Schema, typ reflect.Type, seen map[reflect.Type]bool) {
+	t.Helper()
+	schema = doc.Resolve(schema)
+	if schema == nil {
+		t.Errorf("%s: unresolved schema reference", path)
+		return
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}), typ == reflect.TypeOf(json.RawMessage{}):
+		return
+	case typ.Kind() == reflect.Interface:
+		return
+	}
+
+	want := jsonType(typ)
+	if schema.Type != want {
+		t.Errorf("%s: documented as %q, %s encodes as %q", path, schema.Type, typ, want)
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		if schema.Items != nil {
+			compareSchema(t, doc, path+"[]", schema.Items, typ.Elem(), seen)
+		}
+	case reflect.Map:
+		if schema.AdditionalProperties != nil {
+			compareSchema(t, doc, path+"{}", schema.AdditionalProperties, typ.Elem(), seen)
+		}
+	case reflect.Struct:
+		if seen[typ] {
+			return
+		}
+		seen[typ] = true
+
+		fields := jsonFields(typ)
+		for name, field := range fields {
+			property, documented := schema.Properties[name]
+			if !documented {
+				t.Errorf("%s: field %q of %s is not documented", path, name, typ)
+				continue
+			}
+			compareSchema(t, doc, path+"."+name, property, field, seen)
+		}
+		for name := range schema.Properties {
+			if _, exists := fields[name]; !exists {
+				t.Errorf("%s: documented property %q does not exist on %s", path, name, typ)
+			}
+		}
+		for _, name := range schema.Required {
+			if _, exists := fields[name]; !exists {
+				t.Errorf("%s: required property %q does not exist on %s", path, name, typ)
+			}
+		}
+	}
+}
+
+// jsonFields returns the JSON names of a struct's encoded fields with their
+// types, flattening embedded structs as encoding/json does
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case name == "-":
+			continue
+		case field.Anonymous && name == "":
+			for embedded, fieldType := range jsonFields(field.Type) {
+				fields[embedded] = fieldType
+			}
+			continue
+		case name == "":
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// jsonType returns the JSON schema type a Go type encodes as
+func jsonType(typ reflect.Type) string {
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// toPath converts a gin route (/generate/:id) to an OpenAPI path (/generate/{id})
+func toPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -1,20 +1,28 @@
 package config
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Server    ServerConfig    `mapstructure:"server"`
+	Auth      AuthConfig      `mapstructure:"auth"`
 	Storage   StorageConfig   `mapstructure:"storage"`
 	Models    ModelsConfig    `mapstructure:"models"`
 	Queue     QueueConfig     `mapstructure:"queue"`
 	Inference InferenceConfig `mapstructure:"inference"`
 	Logging   LoggingConfig   `mapstructure:"logging"`
+	Presets   PresetsConfig   `mapstructure:"presets"`
+	Prompts   PromptsConfig   `mapstructure:"prompts"`
+	Webhooks  WebhooksConfig  `mapstructure:"webhooks"`
+	OpenAI    OpenAIConfig    `mapstructure:"openai"`
+	History   HistoryConfig   `mapstructure:"history"`
+	Library   LibraryConfig   `mapstructure:"library"`
 }
 
 type ServerConfig struct {
@@ -24,19 +32,128 @@ type ServerConfig struct {
 	ReadTimeout  int    `mapstructure:"read_timeout"`
 	WriteTimeout int    `mapstructure:"write_timeout"`
 	EnableCORS   bool   `mapstructure:"enable_cors"`
+	// Origins allowed by CORS, reloaded without a restart
+	CORSOrigins []string `mapstructure:"cors_origins"`
+	// How long Idempotency-Key responses are remembered, in seconds
+	IdempotencyWindow int `mapstructure:"idempotency_window"`
+	// Longest time POST /generate?wait=true blocks, in seconds
+	MaxWait int `mapstructure:"max_wait"`
+	// Port of the gRPC API, 0 disables it
+	GRPCPort int `mapstructure:"grpc_port"`
+}
+
+// AuthConfig controls API keys and access to generated images
+type AuthConfig struct {
+	Enabled bool     `mapstructure:"enabled"`  // Require one of APIKeys on API requests
+	APIKeys []string `mapstructure:"api_keys"`
+	// AdminKeys may use the admin endpoints, which are not served when it
+	// is empty. Admin keys are also accepted as API keys.
+	AdminKeys []string `mapstructure:"admin_keys"`
+	// PublicOutputs keeps /outputs open without signed URLs, for single-user setups
+	PublicOutputs bool `mapstructure:"public_outputs"`
+	// URLSecret keys the HMAC of signed output URLs. It is required when
+	// outputs are signed, so links survive restarts and work across replicas.
+	URLSecret string `mapstructure:"url_secret"`
+	URLExpiry int    `mapstructure:"url_expiry"` // Seconds signed output URLs stay valid
+}
+
+// ValidKey reports whether key is one of the configured API or admin keys
+func (c *AuthConfig) ValidKey(key string) bool {
+	return containsKey(c.APIKeys, key) || containsKey(c.AdminKeys, key)
+}
+
+// ValidAdminKey reports whether key is one of the configured admin keys
+func (c *AuthConfig) ValidAdminKey(key string) bool {
+	return containsKey(c.AdminKeys, key)
+}
+
+// containsKey compares key with every non-empty key in constant time
+func containsKey(keys []string, key string) bool {
+	valid := false
+	for _, allowed := range keys {
+		if allowed != "" && subtle.ConstantTimeCompare([]byte(allowed), []byte(key)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// SignOutputs reports whether output URLs must be signed
+func (c *AuthConfig) SignOutputs() bool {
+	return c.Enabled && !c.PublicOutputs
 }
 
 type StorageConfig struct {
 	OutputDir   string `mapstructure:"output_dir"`
 	ModelsDir   string `mapstructure:"models_dir"`
 	TempDir     string `mapstructure:"temp_dir"`
+	DataDir     string `mapstructure:"data_dir"`
 	MaxFileSize int64  `mapstructure:"max_file_size"`
+	// Backend stores outputs: "local" (OutputDir) or "s3". With s3, OutputDir
+	// only stages files written by the inference service before upload.
+	Backend string `mapstructure:"backend"`
+	// URLMode issues output URLs as "proxy" paths served by this server or
+	// "presign" links straight to the object store
+	URLMode       string   `mapstructure:"url_mode"`
+	PresignExpiry int      `mapstructure:"presign_expiry"` // Lifetime of presigned links in seconds
+	S3            S3Config `mapstructure:"s3"`
+	// Retention controls automatic cleanup of outputs and temp files
+	Retention RetentionConfig `mapstructure:"retention"`
+	// Quota rejects new generations and outputs once storage runs out
+	Quota QuotaConfig `mapstructure:"quota"`
+	// Thumbnails controls reduced copies of outputs and on-the-fly resizes
+	Thumbnails ThumbnailConfig `mapstructure:"thumbnails"`
+}
+
+// S3Config locates an S3-compatible bucket for outputs. Empty credentials
+// fall back to AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+type S3Config struct {
+	Endpoint        string `mapstructure:"endpoint"` // host[:port], e.g. s3.amazonaws.com or localhost:9000
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	Prefix          string `mapstructure:"prefix"` // Key prefix for outputs
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	UseSSL          bool   `mapstructure:"use_ssl"`
+	PathStyle       bool   `mapstructure:"path_style"` // Required by most self-hosted stores
+	CreateBucket    bool   `mapstructure:"create_bucket"`
+}
+
+// RetentionConfig controls the storage janitor. Zero disables a policy.
+type RetentionConfig struct {
+	Interval      int   `mapstructure:"interval"`       // Seconds between scheduled runs, 0 only runs on request
+	DryRun        bool  `mapstructure:"dry_run"`        // Scheduled runs only report what they would remove
+	MaxAge        int   `mapstructure:"max_age"`        // Outputs older than this many seconds are removed
+	MaxTotalSize  int64 `mapstructure:"max_total_size"` // Oldest outputs are removed until the output directory fits, in bytes
+	TempTTL       int   `mapstructure:"temp_ttl"`       // Temp files older than this many seconds are removed
+	RemoveOrphans bool  `mapstructure:"remove_orphans"` // Remove outputs that no history entry references
+	OrphanGrace   int   `mapstructure:"orphan_grace"`   // Seconds a new file may go unreferenced before it counts as orphaned
+}
+
+// QuotaConfig limits storage use. Zero disables a limit.
+type QuotaConfig struct {
+	MaxOutputSize    int64 `mapstructure:"max_output_size"`     // Outputs, in bytes
+	MaxTempSize      int64 `mapstructure:"max_temp_size"`       // Temp directory, in bytes
+	MaxTotalSize     int64 `mapstructure:"max_total_size"`      // Outputs, models and temp files together, in bytes
+	MaxKeyOutputSize int64 `mapstructure:"max_key_output_size"` // Outputs generated with one API key, in bytes
+	MinFreeSpace     int64 `mapstructure:"min_free_space"`      // Free disk space to keep on the storage directories, in bytes
+	StatsRefresh     int   `mapstructure:"stats_refresh"`       // Seconds between rescans of the models and temp directories
+}
+
+// ThumbnailConfig controls thumbnails created for every output and the
+// resized copies served by /outputs?w=
+type ThumbnailConfig struct {
+	Sizes    []int  `mapstructure:"sizes"`     // Thumbnail widths in pixels, empty disables thumbnails
+	Format   string `mapstructure:"format"`    // png, jpeg, webp or avif, jpeg where the format is unavailable
+	Quality  int    `mapstructure:"quality"`   // 1-100
+	MaxWidth int    `mapstructure:"max_width"` // Widest resize /outputs serves
 }
 
 type ModelsConfig struct {
-	DefaultModel string              `mapstructure:"default"`
-	Available    []ModelConfig       `mapstructure:"available"`
-	AutoDownload bool                `mapstructure:"auto_download"`
+	DefaultModel string               `mapstructure:"default"`
+	Available    []ModelConfig        `mapstructure:"available"`
+	AutoDownload bool                 `mapstructure:"auto_download"`
+	Profiles     []ModelProfileConfig `mapstructure:"profiles"`
 }
 
 type ModelConfig struct {
@@ -45,12 +162,64 @@ type ModelConfig struct {
 	Type        string `mapstructure:"type"`
 	Version     string `mapstructure:"version"`
 	Description string `mapstructure:"description"`
+	Profile     string `mapstructure:"profile"`
+}
+
+// ModelProfileConfig holds the parameter limits for a family of models.
+// A profile applies to models that name it explicitly or whose name contains
+// one of the Match substrings.
+type ModelProfileConfig struct {
+	Name              string   `mapstructure:"name"`
+	Match             []string `mapstructure:"match"`
+	MinSize           int      `mapstructure:"min_size"`
+	MaxSize           int      `mapstructure:"max_size"`
+	DimensionMultiple int      `mapstructure:"dimension_multiple"`
+	RecommendedSizes  []string `mapstructure:"recommended_sizes"`
+	MaxSteps          int      `mapstructure:"max_steps"`
+	MaxBatchSize      int      `mapstructure:"max_batch_size"`
+}
+
+// ProfileFor returns the profile that applies to the given model, or nil if none does
+func (c *ModelsConfig) ProfileFor(model string) *ModelProfileConfig {
+	profileName := ""
+	for _, m := range c.Available {
+		if m.Name == model || m.Path == model {
+			profileName = m.Profile
+			break
+		}
+	}
+
+	if profileName != "" {
+		for i := range c.Profiles {
+			if c.Profiles[i].Name == profileName {
+				return &c.Profiles[i]
+			}
+		}
+	}
+
+	lower := strings.ToLower(model)
+	for i := range c.Profiles {
+		for _, match := range c.Profiles[i].Match {
+			if match != "" && strings.Contains(lower, strings.ToLower(match)) {
+				return &c.Profiles[i]
+			}
+		}
+	}
+
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == "default" {
+			return &c.Profiles[i]
+		}
+	}
+
+	return nil
 }
 
 type QueueConfig struct {
 	MaxConcurrent int `mapstructure:"max_concurrent"`
 	MaxQueueSize  int `mapstructure:"max_queue_size"`
 	Timeout       int `mapstructure:"timeout"`
+	Retention     int `mapstructure:"retention"` // Seconds finished jobs stay queryable
 }
 
 type InferenceConfig struct {
@@ -62,6 +231,91 @@ type InferenceConfig struct {
 	PythonServiceURL string `mapstructure:"python_service_url"`
 }
 
+// PresetsConfig holds the presets and style templates defined in config.
+// Presets and styles created through the API are persisted to File.
+type PresetsConfig struct {
+	File    string         `mapstructure:"file"`
+	Presets []PresetConfig `mapstructure:"definitions"`
+	Styles  []StyleConfig  `mapstructure:"styles"`
+}
+
+// PresetConfig is a named bundle of generation parameters, zero values are left unset
+type PresetConfig struct {
+	Name           string   `mapstructure:"name"`
+	Description    string   `mapstructure:"description"`
+	Model          string   `mapstructure:"model"`
+	NegativePrompt string   `mapstructure:"negative_prompt"`
+	Width          int      `mapstructure:"width"`
+	Height         int      `mapstructure:"height"`
+	Steps          int      `mapstructure:"steps"`
+	CFGScale       float32  `mapstructure:"cfg_scale"`
+	Sampler        string   `mapstructure:"sampler"`
+	BatchSize      int      `mapstructure:"batch_size"`
+	Strength       float32  `mapstructure:"strength"`
+	Styles         []string `mapstructure:"styles"`
+}
+
+// StyleConfig is a prompt template with an optional {prompt} placeholder
+type StyleConfig struct {
+	Name           string `mapstructure:"name"`
+	Description    string `mapstructure:"description"`
+	Prompt         string `mapstructure:"prompt"`
+	NegativePrompt string `mapstructure:"negative_prompt"`
+}
+
+// PromptsConfig controls prompt preprocessing
+type PromptsConfig struct {
+	WildcardsDir string `mapstructure:"wildcards_dir"`
+	MaxPrompts   int    `mapstructure:"max_prompts"` // Maximum prompts a single request may expand into
+}
+
+// WebhooksConfig controls webhook delivery
+type WebhooksConfig struct {
+	Timeout        int    `mapstructure:"timeout"`         // Per-attempt timeout in seconds
+	MaxAttempts    int    `mapstructure:"max_attempts"`
+	InitialBackoff int    `mapstructure:"initial_backoff"` // Seconds before the first retry, doubled on each attempt
+	MaxBackoff     int    `mapstructure:"max_backoff"`     // Upper bound on the retry delay in seconds
+	LogFile        string `mapstructure:"log_file"`
+	MaxLogEntries  int    `mapstructure:"max_log_entries"`
+	// AllowPrivate lets webhooks reach loopback, private and link-local
+	// addresses. Off by default so API keys cannot probe internal services.
+	AllowPrivate bool `mapstructure:"allow_private"`
+}
+
+// HistoryConfig controls the record of finished generations
+type HistoryConfig struct {
+	File       string `mapstructure:"file"`
+	MaxEntries int    `mapstructure:"max_entries"` // Oldest unpinned entries are dropped beyond this, 0 keeps everything
+}
+
+// LibraryConfig controls the collections generations are organised in
+type LibraryConfig struct {
+	File string `mapstructure:"file"`
+}
+
+// OpenAIConfig controls the OpenAI Images API compatible endpoints
+type OpenAIConfig struct {
+	// ModelAliases maps OpenAI model names (e.g. "dall-e-3") to configured models
+	ModelAliases map[string]string `mapstructure:"model_aliases"`
+}
+
+// ResolveOpenAIModel maps an OpenAI model name to a configured model. An empty name
+// resolves to the default model. The second result is false for unknown models.
+func (c *Config) ResolveOpenAIModel(name string) (string, bool) {
+	if name == "" {
+		return c.Models.DefaultModel, true
+	}
+	if model, ok := c.OpenAI.ModelAliases[strings.ToLower(name)]; ok {
+		return model, true
+	}
+	for _, model := range c.Models.Available {
+		if model.Name == name {
+			return model.Name, true
+		}
+	}
+	return "", false
+}
+
 type LoggingConfig struct {
 	Level      string `mapstructure:"level"`
 	File       string `mapstructure:"file"`
@@ -70,7 +324,31 @@ type LoggingConfig struct {
 	MaxAge     int    `mapstructure:"max_age"`
 }
 
+// Load reads the configuration, validates it and creates the storage
+// directories
 func Load() (*Config, error) {
+	config, err := Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Ensure directories exist
+	if err := ensureDirectories(config); err != nil {
+		return nil, fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	return config, nil
+}
+
+// Read merges the defaults, the config file and SD_* environment variables
+// without validating the result. A missing config file leaves the defaults
+// in place. Nested keys map to variables with dots replaced by underscores,
+// e.g. SD_QUEUE_MAX_CONCURRENT for queue.max_concurrent.
+func Read() (*Config, error) {
 	// Set default configuration file locations
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -85,21 +363,13 @@ func Load() (*Config, error) {
 	setDefaults()
 
 	// Read environment variables
-	viper.AutomaticEnv()
 	viper.SetEnvPrefix("SD")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
 
 	// Read config file
 	if err := viper.ReadInConfig(); err != nil {
-		// If config file not found, create default one
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			if err := createDefaultConfig(); err != nil {
-				return nil, fmt.Errorf("failed to create default config: %w", err)
-			}
-			// Try reading again
-			if err := viper.ReadInConfig(); err != nil {
-				return nil, fmt.Errorf("failed to read config: %w", err)
-			}
-		} else {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("failed to read config: %w", err)
 		}
 	}
@@ -109,14 +379,15 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	// Ensure directories exist
-	if err := ensureDirectories(&config); err != nil {
-		return nil, fmt.Errorf("failed to create directories: %w", err)
-	}
-
 	return &config, nil
 }
 
+// File returns the config file in use, or an empty string when only
+// defaults and environment variables apply
+func File() string {
+	return viper.ConfigFileUsed()
+}
+
 func setDefaults() {
 	// Server defaults
 	viper.SetDefault("server.host", "localhost")
@@ -125,21 +396,83 @@ func setDefaults() {
 	viper.SetDefault("server.read_timeout", 30)
 	viper.SetDefault("server.write_timeout", 30)
 	viper.SetDefault("server.enable_cors", true)
+	viper.SetDefault("server.cors_origins", []string{"http://localhost:3000", "http://localhost:1420"})
+	viper.SetDefault("server.idempotency_window", 86400) // 24 hours
+	viper.SetDefault("server.max_wait", 120)
+	viper.SetDefault("server.grpc_port", 9090)
+
+	// Auth defaults
+	viper.SetDefault("auth.enabled", false)
+	viper.SetDefault("auth.api_keys", []string{})
+	viper.SetDefault("auth.admin_keys", []string{})
+	viper.SetDefault("auth.public_outputs", false)
+	viper.SetDefault("auth.url_secret", "")
+	viper.SetDefault("auth.url_expiry", 86400)
 
 	// Storage defaults
 	viper.SetDefault("storage.output_dir", "./outputs")
 	viper.SetDefault("storage.models_dir", "./models")
 	viper.SetDefault("storage.temp_dir", "./temp")
+	viper.SetDefault("storage.data_dir", "./data")
 	viper.SetDefault("storage.max_file_size", 10737418240) // 10GB
+	viper.SetDefault("storage.backend", "local")
+	viper.SetDefault("storage.url_mode", "proxy")
+	viper.SetDefault("storage.presign_expiry", 3600)
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("storage.s3.use_ssl", true)
+	viper.SetDefault("storage.s3.path_style", false)
+	viper.SetDefault("storage.s3.create_bucket", false)
+	viper.SetDefault("storage.retention.interval", 3600)
+	viper.SetDefault("storage.retention.dry_run", false)
+	viper.SetDefault("storage.retention.max_age", 0)
+	viper.SetDefault("storage.retention.max_total_size", 0)
+	viper.SetDefault("storage.retention.temp_ttl", 3600)
+	viper.SetDefault("storage.retention.remove_orphans", false)
+	viper.SetDefault("storage.retention.orphan_grace", 3600)
+	viper.SetDefault("storage.quota.max_output_size", 0)
+	viper.SetDefault("storage.quota.max_temp_size", 0)
+	viper.SetDefault("storage.quota.max_total_size", 0)
+	viper.SetDefault("storage.quota.max_key_output_size", 0)
+	viper.SetDefault("storage.quota.min_free_space", 1073741824) // 1GB
+	viper.SetDefault("storage.quota.stats_refresh", 300)
+	viper.SetDefault("storage.thumbnails.sizes", []int{256, 512})
+	viper.SetDefault("storage.thumbnails.format", "webp")
+	viper.SetDefault("storage.thumbnails.quality", 80)
+	viper.SetDefault("storage.thumbnails.max_width", 2048)
 
 	// Models defaults
 	viper.SetDefault("models.default", "sd15")
 	viper.SetDefault("models.auto_download", false)
+	viper.SetDefault("models.available", []map[string]interface{}{
+		{"name": "sd15", "path": "./models/sd15", "type": "pytorch", "version": "1.5", "description": "Stable Diffusion v1.5", "profile": "default"},
+	})
+	viper.SetDefault("models.profiles", []map[string]interface{}{
+		{
+			"name":               "sdxl",
+			"match":              []string{"xl"},
+			"min_size":           512,
+			"max_size":           2048,
+			"dimension_multiple": 8,
+			"recommended_sizes":  []string{"1024x1024", "1152x896", "896x1152", "1216x832", "832x1216"},
+			"max_steps":          150,
+			"max_batch_size":     4,
+		},
+		{
+			"name":               "default",
+			"min_size":           64,
+			"max_size":           2048,
+			"dimension_multiple": 8,
+			"recommended_sizes":  []string{"512x512", "512x768", "768x512"},
+			"max_steps":          150,
+			"max_batch_size":     10,
+		},
+	})
 
 	// Queue defaults
 	viper.SetDefault("queue.max_concurrent", 1)
 	viper.SetDefault("queue.max_queue_size", 100)
 	viper.SetDefault("queue.timeout", 300)
+	viper.SetDefault("queue.retention", 3600)
 
 	// Inference defaults
 	viper.SetDefault("inference.device", "cpu")
@@ -148,6 +481,44 @@ func setDefaults() {
 	viper.SetDefault("inference.memory_limit", 4294967296) // 4GB
 	viper.SetDefault("inference.use_optimized", true)
 
+	// Presets defaults
+	viper.SetDefault("presets.file", "")
+	viper.SetDefault("presets.definitions", []map[string]interface{}{
+		{"name": "fast", "description": "Quick drafts", "steps": 12, "sampler": "DPM++ 2M Karras", "cfg_scale": 6.0},
+		{"name": "sdxl-quality", "description": "High quality SDXL", "model": "stabilityai/stable-diffusion-xl-base-1.0", "width": 1024, "height": 1024, "steps": 40, "sampler": "DPM++ 2M Karras", "cfg_scale": 7.0},
+		{"name": "portrait-768", "description": "Portrait orientation", "width": 512, "height": 768, "steps": 30},
+	})
+	viper.SetDefault("presets.styles", []map[string]interface{}{
+		{"name": "photographic", "prompt": "cinematic photo of {prompt}, 35mm photograph, film, bokeh, highly detailed", "negative_prompt": "drawing, painting, illustration, cartoon"},
+		{"name": "anime", "prompt": "anime artwork of {prompt}, key visual, vibrant", "negative_prompt": "photo, photorealistic, realism"},
+	})
+
+	// Prompts defaults
+	viper.SetDefault("prompts.wildcards_dir", "./wildcards")
+	viper.SetDefault("prompts.max_prompts", 16)
+
+	// Webhooks defaults
+	viper.SetDefault("webhooks.timeout", 10)
+	viper.SetDefault("webhooks.max_attempts", 5)
+	viper.SetDefault("webhooks.initial_backoff", 2)
+	viper.SetDefault("webhooks.max_backoff", 300)
+	viper.SetDefault("webhooks.log_file", "")
+	viper.SetDefault("webhooks.max_log_entries", 1000)
+	viper.SetDefault("webhooks.allow_private", false)
+
+	// History defaults
+	viper.SetDefault("history.file", "")
+	viper.SetDefault("history.max_entries", 10000)
+
+	// Library defaults
+	viper.SetDefault("library.file", "")
+
+	// OpenAI compatibility defaults
+	viper.SetDefault("openai.model_aliases", map[string]string{
+		"dall-e-2": "sd15",
+		"dall-e-3": "sd15",
+	})
+
 	// Logging defaults
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.file", "")
@@ -156,70 +527,12 @@ func setDefaults() {
 	viper.SetDefault("logging.max_age", 7)
 }
 
-func createDefaultConfig() error {
-	configPath := "./backend/config.yaml"
-	
-	defaultConfig := `# Stable Diffusion Platform Configuration
-
-server:
-  host: localhost
-  port: 8080
-  environment: development
-  read_timeout: 30
-  write_timeout: 30
-  enable_cors: true
-
-storage:
-  output_dir: ./outputs
-  models_dir: ./models
-  temp_dir: ./temp
-  max_file_size: 10737418240  # 10GB
-
-models:
-  default: sd15
-  auto_download: false
-  available:
-    - name: sd15
-      path: ./models/sd15
-      type: onnx
-      version: "1.5"
-      description: "Stable Diffusion v1.5 ONNX model"
-
-queue:
-  max_concurrent: 1
-  max_queue_size: 100
-  timeout: 300  # 5 minutes
-
-inference:
-  device: cpu  # cpu or gpu
-  max_batch_size: 1
-  max_resolution: 1024
-  memory_limit: 4294967296  # 4GB
-  use_optimized: true
-
-logging:
-  level: info
-  file: ""  # Empty for stdout only
-  max_size: 100  # MB
-  max_backups: 3
-  max_age: 7  # days
-`
-
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(configPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	// Write default config
-	return os.WriteFile(configPath, []byte(defaultConfig), 0644)
-}
-
 func ensureDirectories(config *Config) error {
 	dirs := []string{
 		config.Storage.OutputDir,
 		config.Storage.ModelsDir,
 		config.Storage.TempDir,
+		config.Storage.DataDir,
 	}
 
 	for _, dir := range dirs {
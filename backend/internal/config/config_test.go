@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// writeConfig writes config.yaml to a temp directory and changes into it,
+// where Read looks first
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	return file
+}
+
+// readConfig reads a config file over the defaults without validating it
+func readConfig(t *testing.T, content string) *Config {
+	t.Helper()
+	writeConfig(t, content)
+	cfg, err := Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return cfg
+}
+
+// invalidKeys returns the keys reported by Validate
+func invalidKeys(t *testing.T, cfg *Config) []string {
+	t.Helper()
+	err := cfg.Validate()
+	if err == nil {
+		return nil
+	}
+	var errs models.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate error = %v, want ValidationErrors", err)
+	}
+	keys := make([]string, len(errs))
+	for i, fieldErr := range errs {
+		keys[i] = fieldErr.Field
+	}
+	return keys
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		desc   string
+		config string
+		want   []string
+	}{
+		{
+			desc:   "defaults",
+			config: "",
+			want:   nil,
+		},
+		{
+			desc: "several invalid keys reported together",
+			config: `
+server:
+  port: 70000
+queue:
+  max_concurrent: 0
+logging:
+  level: loud
+storage:
+  backend: ftp
+`,
+			want: []string{"server.port", "storage.backend", "queue.max_concurrent", "logging.level"},
+		},
+		{
+			desc: "gRPC on the REST port",
+			config: `
+server:
+  port: 8080
+  grpc_port: 8080
+`,
+			want: []string{"server.grpc_port"},
+		},
+		{
+			desc: "signed outputs without a URL secret",
+			config: `
+auth:
+  enabled: true
+  api_keys: ["key-1"]
+`,
+			want: []string{"auth.url_secret"},
+		},
+		{
+			desc: "public outputs need no URL secret",
+			config: `
+auth:
+  enabled: true
+  api_keys: ["key-1"]
+  public_outputs: true
+`,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := invalidKeys(t, readConfig(t, tt.config)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: invalid keys = %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+}
+
+// redactedSecret matches a redacted url_secret, quoted or not
+var redactedSecret = regexp.MustCompile(`url_secret: ['"]?` + regexp.QuoteMeta(Redacted))
+
+func TestRedact(t *testing.T) {
+	content := `
+auth:
+  enabled: true
+  api_keys: ["client-key"]
+  admin_keys: ["admin-key"]
+  url_secret: "signing-secret"  # Keep this comment
+storage:
+  s3:
+    secret_access_key: "s3-secret"
+`
+	cfg := readConfig(t, content)
+
+	printed, err := cfg.Redact().YAML()
+	if err != nil {
+		t.Fatalf("YAML: %v", err)
+	}
+	file, err := RedactFile([]byte(content))
+	if err != nil {
+		t.Fatalf("RedactFile: %v", err)
+	}
+
+	for desc, output := range map[string]string{"effective config": string(printed), "config file": string(file)} {
+		for _, secret := range []string{"client-key", "admin-key", "signing-secret", "s3-secret"} {
+			if strings.Contains(output, secret) {
+				t.Errorf("%s shows %q:\n%s", desc, secret, output)
+			}
+		}
+		if !redactedSecret.MatchString(output) {
+			t.Errorf("%s does not redact url_secret:\n%s", desc, output)
+		}
+	}
+	if !strings.Contains(string(file), "# Keep this comment") {
+		t.Errorf("redacted file lost its comments:\n%s", file)
+	}
+	if cfg.Auth.URLSecret != "signing-secret" {
+		t.Errorf("Redact changed the original config's url_secret to %q", cfg.Auth.URLSecret)
+	}
+}
+
+func TestWatcherReload(t *testing.T) {
+	file := writeConfig(t, "queue:\n  max_concurrent: 1\n")
+	cfg, err := Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	w := NewWatcher(cfg, logger)
+	var changes []*Change
+	w.Subscribe(func(change *Change) { changes = append(changes, change) })
+
+	edit := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		w.reload()
+	}
+
+	// An invalid edit leaves the running config untouched
+	edit("queue:\n  max_concurrent: 0\n")
+	if len(changes) != 0 || w.Current().Queue.MaxConcurrent != 1 {
+		t.Errorf("invalid edit applied: %d changes, max_concurrent = %d", len(changes), w.Current().Queue.MaxConcurrent)
+	}
+
+	// A valid edit applies its reloadable keys only
+	edit("queue:\n  max_concurrent: 3\nserver:\n  port: 9000\n")
+	if len(changes) != 1 || !slices.Equal(changes[0].Keys, []string{"queue.max_concurrent"}) {
+		t.Fatalf("changes = %+v, want one change of queue.max_concurrent", changes)
+	}
+	current := w.Current()
+	if current.Queue.MaxConcurrent != 3 || current.Server.Port != cfg.Server.Port {
+		t.Errorf("current max_concurrent = %d and port = %d, want 3 and %d", current.Queue.MaxConcurrent, current.Server.Port, cfg.Server.Port)
+	}
+}
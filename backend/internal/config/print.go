@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted is shown in place of secrets
+const Redacted = "[redacted]"
+
+// secretKeys are the keys Redact and RedactFile hide
+var secretKeys = []string{"auth.api_keys", "auth.admin_keys", "auth.url_secret", "storage.s3.access_key_id", "storage.s3.secret_access_key"}
+
+// Redact returns a copy of the config with API and admin keys, the URL
+// signing secret and S3 credentials replaced by Redacted
+func (c *Config) Redact() *Config {
+	redacted := *c
+	redacted.Auth.APIKeys = redactAll(c.Auth.APIKeys)
+	redacted.Auth.AdminKeys = redactAll(c.Auth.AdminKeys)
+	redacted.Auth.URLSecret = redact(c.Auth.URLSecret)
+	redacted.Storage.S3.AccessKeyID = redact(c.Storage.S3.AccessKeyID)
+	redacted.Storage.S3.SecretAccessKey = redact(c.Storage.S3.SecretAccessKey)
+	return &redacted
+}
+
+// redactAll returns a copy of secrets with each one hidden
+func redactAll(secrets []string) []string {
+	redacted := slices.Clone(secrets)
+	for i := range redacted {
+		redacted[i] = redact(redacted[i])
+	}
+	return redacted
+}
+
+// redact hides a secret, leaving unset ones visible as unset
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// RedactFile returns a config file with the values of secret keys replaced
+// by Redacted, keeping its layout and comments
+func RedactFile(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+	redactNode(doc.Content[0], "")
+	return marshal(&doc)
+}
+
+// redactNode redacts the secret keys below a mapping node
+func redactNode(node *yaml.Node, prefix string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		value := node.Content[i+1]
+		if !slices.Contains(secretKeys, key) {
+			redactNode(value, key)
+			continue
+		}
+		switch value.Kind {
+		case yaml.ScalarNode:
+			value.Value = redact(value.Value)
+		case yaml.SequenceNode:
+			for _, item := range value.Content {
+				item.Value = redact(item.Value)
+			}
+		}
+	}
+}
+
+// YAML renders the config with the keys of config.yaml, in the order of
+// the Config struct
+func (c *Config) YAML() ([]byte, error) {
+	return marshal(toNode(reflect.ValueOf(*c)))
+}
+
+// marshal encodes a node with the two-space indent of config.yaml
+func marshal(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toNode converts a config value to a YAML node, naming struct fields by
+// their mapstructure tags
+func toNode(v reflect.Value) *yaml.Node {
+	switch v.Kind() {
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Tag.Get("mapstructure")
+			if name == "" {
+				continue
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, toNode(v.Field(i)))
+		}
+		return node
+
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		if v.Type().Elem().Kind() != reflect.Struct {
+			node.Style = yaml.FlowStyle
+		}
+		for i := 0; i < v.Len(); i++ {
+			node.Content = append(node.Content, toNode(v.Index(i)))
+		}
+		return node
+
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, key := range keys {
+			node.Content = append(node.Content, toNode(key), toNode(v.MapIndex(key)))
+		}
+		return node
+
+	default:
+		node := &yaml.Node{}
+		if err := node.Encode(v.Interface()); err != nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(v.Interface())}
+		}
+		return node
+	}
+}
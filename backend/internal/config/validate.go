@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// validator collects the invalid keys of a config
+type validator struct {
+	errs models.ValidationErrors
+}
+
+// add records an invalid key
+func (v *validator) add(key, format string, args ...interface{}) {
+	v.errs = append(v.errs, &models.FieldError{Field: key, Message: fmt.Sprintf(format, args...)})
+}
+
+// atLeast checks that an int setting is at least min
+func (v *validator) atLeast(key string, value, min int64) {
+	if value < min {
+		v.add(key, "must be at least %d", min)
+	}
+}
+
+// between checks that an int setting lies within [min, max]
+func (v *validator) between(key string, value, min, max int64) {
+	if value < min || value > max {
+		v.add(key, "must be between %d and %d", min, max)
+	}
+}
+
+// oneOf checks that a string setting is one of the allowed values
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.add(key, "must be one of %s", strings.Join(allowed, ", "))
+	}
+}
+
+// required checks that a string setting is set
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(key, "is required")
+	}
+}
+
+// Validate checks every setting and returns models.ValidationErrors listing
+// each invalid key, or nil
+func (c *Config) Validate() error {
+	v := &validator{}
+	c.Server.validate(v)
+	c.Auth.validate(v)
+	c.Storage.validate(v)
+	c.Models.validate(v)
+	c.Queue.validate(v)
+	c.Inference.validate(v)
+
+	if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+		v.add("logging.level", "must be one of panic, fatal, error, warn, info, debug, trace")
+	}
+	v.atLeast("prompts.max_prompts", int64(c.Prompts.MaxPrompts), 1)
+	v.atLeast("webhooks.timeout", int64(c.Webhooks.Timeout), 1)
+	v.atLeast("webhooks.max_attempts", int64(c.Webhooks.MaxAttempts), 1)
+	v.atLeast("webhooks.initial_backoff", int64(c.Webhooks.InitialBackoff), 0)
+	v.atLeast("webhooks.max_backoff", int64(c.Webhooks.MaxBackoff), int64(c.Webhooks.InitialBackoff))
+	v.atLeast("webhooks.max_log_entries", int64(c.Webhooks.MaxLogEntries), 0)
+	v.atLeast("history.max_entries", int64(c.History.MaxEntries), 0)
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+func (c *ServerConfig) validate(v *validator) {
+	v.between("server.port", int64(c.Port), 1, 65535)
+	v.between("server.grpc_port", int64(c.GRPCPort), 0, 65535)
+	if c.GRPCPort == c.Port {
+		v.add("server.grpc_port", "must differ from server.port")
+	}
+	v.atLeast("server.read_timeout", int64(c.ReadTimeout), 0)
+	v.atLeast("server.write_timeout", int64(c.WriteTimeout), 0)
+	v.atLeast("server.idempotency_window", int64(c.IdempotencyWindow), 1)
+	v.atLeast("server.max_wait", int64(c.MaxWait), 0)
+
+	if c.EnableCORS && len(c.CORSOrigins) == 0 {
+		v.add("server.cors_origins", "cannot be empty when CORS is enabled")
+	}
+	for _, origin := range c.CORSOrigins {
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			v.add("server.cors_origins", "%q must start with http:// or https://", origin)
+		}
+	}
+}
+
+func (c *AuthConfig) validate(v *validator) {
+	if c.Enabled && !slices.ContainsFunc(c.APIKeys, func(key string) bool { return key != "" }) {
+		v.add("auth.api_keys", "needs at least one key when auth is enabled")
+	}
+	if c.SignOutputs() {
+		v.required("auth.url_secret", c.URLSecret)
+		v.atLeast("auth.url_expiry", int64(c.URLExpiry), 1)
+	}
+}
+
+func (c *StorageConfig) validate(v *validator) {
+	v.required("storage.output_dir", c.OutputDir)
+	v.required("storage.temp_dir", c.TempDir)
+	v.required("storage.data_dir", c.DataDir)
+	v.atLeast("storage.max_file_size", c.MaxFileSize, 0)
+
+	v.oneOf("storage.backend", c.Backend, "local", "s3")
+	if c.Backend == "s3" {
+		v.required("storage.s3.endpoint", c.S3.Endpoint)
+		v.required("storage.s3.bucket", c.S3.Bucket)
+	}
+	v.oneOf("storage.url_mode", c.URLMode, "proxy", "presign")
+	if c.URLMode == "presign" {
+		v.atLeast("storage.presign_expiry", int64(c.PresignExpiry), 1)
+	}
+
+	v.atLeast("storage.retention.interval", int64(c.Retention.Interval), 0)
+	v.atLeast("storage.retention.max_age", int64(c.Retention.MaxAge), 0)
+	v.atLeast("storage.retention.max_total_size", c.Retention.MaxTotalSize, 0)
+	v.atLeast("storage.retention.temp_ttl", int64(c.Retention.TempTTL), 0)
+	v.atLeast("storage.retention.orphan_grace", int64(c.Retention.OrphanGrace), 0)
+
+	v.atLeast("storage.quota.max_output_size", c.Quota.MaxOutputSize, 0)
+	v.atLeast("storage.quota.max_temp_size", c.Quota.MaxTempSize, 0)
+	v.atLeast("storage.quota.max_total_size", c.Quota.MaxTotalSize, 0)
+	v.atLeast("storage.quota.max_key_output_size", c.Quota.MaxKeyOutputSize, 0)
+	v.atLeast("storage.quota.min_free_space", c.Quota.MinFreeSpace, 0)
+	v.atLeast("storage.quota.stats_refresh", int64(c.Quota.StatsRefresh), 1)
+
+	for _, size := range c.Thumbnails.Sizes {
+		if size < 1 {
+			v.add("storage.thumbnails.sizes", "widths must be at least 1")
+			break
+		}
+	}
+	if _, ok := models.ParseImageFormat(c.Thumbnails.Format); !ok {
+		v.add("storage.thumbnails.format", "must be one of png, jpeg, webp, avif")
+	}
+	v.between("storage.thumbnails.quality", int64(c.Thumbnails.Quality), 1, 100)
+	v.atLeast("storage.thumbnails.max_width", int64(c.Thumbnails.MaxWidth), 1)
+}
+
+func (c *ModelsConfig) validate(v *validator) {
+	v.required("models.default", c.DefaultModel)
+
+	names := make(map[string]bool)
+	for i, model := range c.Available {
+		key := fmt.Sprintf("models.available[%d]", i)
+		v.required(key+".name", model.Name)
+		if names[model.Name] {
+			v.add(key+".name", "%q is defined twice", model.Name)
+		}
+		names[model.Name] = true
+		if model.Profile != "" && !slices.ContainsFunc(c.Profiles, func(p ModelProfileConfig) bool { return p.Name == model.Profile }) {
+			v.add(key+".profile", "no profile is named %q", model.Profile)
+		}
+	}
+
+	for i, profile := range c.Profiles {
+		key := fmt.Sprintf("models.profiles[%d]", i)
+		v.required(key+".name", profile.Name)
+		v.atLeast(key+".min_size", int64(profile.MinSize), 1)
+		v.atLeast(key+".max_size", int64(profile.MaxSize), int64(profile.MinSize))
+		v.atLeast(key+".dimension_multiple", int64(profile.DimensionMultiple), 0)
+		v.atLeast(key+".max_steps", int64(profile.MaxSteps), 1)
+		v.atLeast(key+".max_batch_size", int64(profile.MaxBatchSize), 1)
+	}
+}
+
+func (c *QueueConfig) validate(v *validator) {
+	v.atLeast("queue.max_concurrent", int64(c.MaxConcurrent), 1)
+	v.atLeast("queue.max_queue_size", int64(c.MaxQueueSize), 1)
+	v.atLeast("queue.timeout", int64(c.Timeout), 1)
+	v.atLeast("queue.retention", int64(c.Retention), 1)
+}
+
+func (c *InferenceConfig) validate(v *validator) {
+	v.oneOf("inference.device", c.Device, "cpu", "gpu", "mps")
+	v.atLeast("inference.max_batch_size", int64(c.MaxBatchSize), 1)
+	v.atLeast("inference.max_resolution", int64(c.MaxResolution), 1)
+	v.atLeast("inference.memory_limit", c.MemoryLimit, 0)
+	if c.PythonServiceURL != "" {
+		if u, err := url.Parse(c.PythonServiceURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.add("inference.python_service_url", "must be an http:// or https:// URL")
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// reloadable lists the keys applied without a restart. Edits to any other
+// key are logged and wait for the next start.
+var reloadable = []string{
+	"server.enable_cors",
+	"server.cors_origins",
+	"queue.max_concurrent",
+	"queue.timeout",
+	"queue.retention",
+	"logging.level",
+}
+
+// applyReloadable copies the reloadable keys from src into dst
+func applyReloadable(dst, src *Config) {
+	dst.Server.EnableCORS = src.Server.EnableCORS
+	dst.Server.CORSOrigins = slices.Clone(src.Server.CORSOrigins)
+	dst.Queue.MaxConcurrent = src.Queue.MaxConcurrent
+	dst.Queue.Timeout = src.Queue.Timeout
+	dst.Queue.Retention = src.Queue.Retention
+	dst.Logging.Level = src.Logging.Level
+}
+
+// Change is a validated edit of the config file. Old and New are complete
+// configs, New only differs from Old in the reloadable keys.
+type Change struct {
+	Old  *Config
+	New  *Config
+	Keys []string // Changed keys, e.g. "queue.max_concurrent"
+}
+
+// Changed reports whether a key, or any key under a section such as
+// "queue", changed
+func (c *Change) Changed(key string) bool {
+	for _, changed := range c.Keys {
+		if changed == key || strings.HasPrefix(changed, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscriber applies a config change. Subscribers run on the watcher's
+// goroutine, one after the other.
+type Subscriber func(change *Change)
+
+// Watcher reloads the config file when it changes and hands validated
+// changes to subscribers
+type Watcher struct {
+	logger *logrus.Logger
+
+	mu          sync.Mutex
+	current     *Config
+	subscribers []Subscriber
+}
+
+// NewWatcher creates a watcher for the config returned by Load
+func NewWatcher(cfg *Config, logger *logrus.Logger) *Watcher {
+	return &Watcher{
+		logger:  logger,
+		current: cfg,
+	}
+}
+
+// Subscribe registers a subscriber for future changes
+func (w *Watcher) Subscribe(subscriber Subscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, subscriber)
+}
+
+// Current returns the config with every change applied so far
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Start watches the config file read by Load
+func (w *Watcher) Start() {
+	if viper.ConfigFileUsed() == "" {
+		w.logger.Warn("No config file to watch, configuration changes need a restart")
+		return
+	}
+
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		w.reload()
+	})
+	viper.WatchConfig()
+	w.logger.WithField("file", viper.ConfigFileUsed()).Info("Watching config file for changes")
+}
+
+// reload reads the edited file and applies its reloadable keys. Invalid
+// files leave the running config untouched.
+func (w *Watcher) reload() {
+	logger := w.logger.WithField("file", viper.ConfigFileUsed())
+
+	// Viper keeps the previous values when the file does not parse, read
+	// it again to find out
+	if err := viper.ReadInConfig(); err != nil {
+		logger.WithError(err).Error("Rejected config change")
+		return
+	}
+	var edited Config
+	if err := viper.Unmarshal(&edited); err != nil {
+		logger.WithError(err).Error("Rejected config change")
+		return
+	}
+	if err := edited.Validate(); err != nil {
+		logger.WithError(err).Error("Rejected config change")
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var keys, pending []string
+	for _, key := range diff("", reflect.ValueOf(*w.current), reflect.ValueOf(edited)) {
+		if slices.Contains(reloadable, key) {
+			keys = append(keys, key)
+		} else {
+			pending = append(pending, key)
+		}
+	}
+	if len(pending) > 0 {
+		logger.WithField("keys", pending).Warn("Config change needs a restart to take effect")
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	updated := *w.current
+	applyReloadable(&updated, &edited)
+	change := &Change{Old: w.current, New: &updated, Keys: keys}
+	w.current = &updated
+
+	logger.WithField("keys", keys).Info("Applying config change")
+	for _, subscriber := range w.subscribers {
+		subscriber(change)
+	}
+}
+
+// diff returns the mapstructure keys whose values differ between a and b
+func diff(prefix string, a, b reflect.Value) []string {
+	if a.Kind() != reflect.Struct {
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return nil
+		}
+		return []string{prefix}
+	}
+
+	var keys []string
+	for i := 0; i < a.NumField(); i++ {
+		name := a.Type().Field(i).Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		keys = append(keys, diff(name, a.Field(i), b.Field(i))...)
+	}
+	return keys
+}
@@ -0,0 +1,539 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/history"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/storage"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Manager interface for export and import operations
+type Manager interface {
+	Create(filter models.ExportFilter, owner string) (*models.ExportJob, error)
+	Get(id, owner string) (*models.ExportJob, error)
+	List(owner string) []*models.ExportJob
+	Open(ctx context.Context, id, owner string) (io.ReadCloser, *storage.ObjectInfo, error)
+	Import(ctx context.Context, archive io.ReaderAt, size int64, owner string) (*models.ImportReport, error)
+	OutputFiles() map[string]bool
+	Start(ctx context.Context)
+}
+
+// ExportManager implements the Manager interface.
+// Archives are built one at a time and stored as outputs under exports/,
+// so the retention policy and quotas apply to them. Jobs are kept in
+// memory until the server restarts.
+type ExportManager struct {
+	config  config.StorageConfig
+	history history.Manager
+	storage storage.Manager
+	logger  *logrus.Logger
+
+	mu   sync.RWMutex
+	jobs map[string]*job
+	ctx  context.Context
+	slot chan struct{}
+}
+
+// job pairs an export job with the API key it was created with
+type job struct {
+	models.ExportJob
+	owner string
+}
+
+// NewManager creates a new export manager
+func NewManager(cfg config.StorageConfig, history history.Manager, storage storage.Manager, logger *logrus.Logger) Manager {
+	return &ExportManager{
+		config:  cfg,
+		history: history,
+		storage: storage,
+		logger:  logger,
+		jobs:    make(map[string]*job),
+		ctx:     context.Background(),
+		slot:    make(chan struct{}, 1),
+	}
+}
+
+// Start sets the context exports run under; running exports fail when it is cancelled
+func (m *ExportManager) Start(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+}
+
+// Create queues an export of owner's history entries matching filter
+func (m *ExportManager) Create(filter models.ExportFilter, owner string) (*models.ExportJob, error) {
+	if err := m.storage.CheckQuota(owner); err != nil {
+		return nil, err
+	}
+
+	j := &job{
+		ExportJob: models.ExportJob{
+			ID:        uuid.New().String(),
+			Status:    models.ExportQueued,
+			Filter:    filter,
+			CreatedAt: time.Now(),
+		},
+		owner: owner,
+	}
+
+	m.mu.Lock()
+	m.jobs[j.ID] = j
+	ctx := m.ctx
+	snapshot := m.snapshot(j)
+	m.mu.Unlock()
+
+	go m.run(ctx, j)
+	return snapshot, nil
+}
+
+// Get returns an export job of owner
+func (m *ExportManager) Get(id, owner string) (*models.ExportJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, exists := m.jobs[id]
+	if !exists || j.owner != owner {
+		return nil, models.ErrExportNotFound
+	}
+	return m.snapshot(j), nil
+}
+
+// List returns owner's export jobs, newest first
+func (m *ExportManager) List(owner string) []*models.ExportJob {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]*models.ExportJob, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		if j.owner == owner {
+			jobs = append(jobs, m.snapshot(j))
+		}
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].CreatedAt.After(jobs[k].CreatedAt)
+	})
+	return jobs
+}
+
+// Open opens the archive of a completed export of owner
+func (m *ExportManager) Open(ctx context.Context, id, owner string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	exportJob, err := m.Get(id, owner)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exportJob.Status != models.ExportCompleted {
+		return nil, nil, fmt.Errorf("%w: export is %s", models.ErrExportNotReady, exportJob.Status)
+	}
+	return m.storage.OpenOutput(ctx, archiveName(id))
+}
+
+// OutputFiles returns the file names of the archives of known exports, so
+// the storage janitor does not remove them as orphans. It matches the
+// storage.ReferenceFunc signature.
+func (m *ExportManager) OutputFiles() map[string]bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files := make(map[string]bool, len(m.jobs))
+	for id := range m.jobs {
+		files[path.Base(archiveName(id))] = false
+	}
+	return files
+}
+
+// snapshot copies a job with a fresh download URL, caller must hold the lock
+func (m *ExportManager) snapshot(j *job) *models.ExportJob {
+	exportJob := j.ExportJob
+	if exportJob.Status == models.ExportCompleted {
+		exportJob.DownloadURL = m.storage.OutputPath(archiveName(j.ID))
+	}
+	return &exportJob
+}
+
+// update applies fn to a job under the lock
+func (m *ExportManager) update(j *job, fn func(*models.ExportJob)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(&j.ExportJob)
+}
+
+// run builds the archive of a job, one export at a time
+func (m *ExportManager) run(ctx context.Context, j *job) {
+	select {
+	case m.slot <- struct{}{}:
+		defer func() { <-m.slot }()
+	case <-ctx.Done():
+		m.fail(j, ctx.Err())
+		return
+	}
+
+	m.update(j, func(e *models.ExportJob) { e.Status = models.ExportRunning })
+	logger := m.logger.WithField("export_id", j.ID)
+	logger.Info("Export started")
+
+	size, err := m.build(storage.WithOwner(ctx, j.owner), j)
+	if err != nil {
+		logger.WithError(err).Error("Export failed")
+		m.fail(j, err)
+		return
+	}
+
+	now := time.Now()
+	m.update(j, func(e *models.ExportJob) {
+		e.Status = models.ExportCompleted
+		e.Progress = 1
+		e.Size = size
+		e.CompletedAt = &now
+	})
+	logger.WithField("size", size).Info("Export completed")
+}
+
+// fail marks a job failed
+func (m *ExportManager) fail(j *job, err error) {
+	now := time.Now()
+	m.update(j, func(e *models.ExportJob) {
+		e.Status = models.ExportFailed
+		e.Error = err.Error()
+		e.CompletedAt = &now
+	})
+}
+
+// build writes the archive to a temp file and stores it as an output
+func (m *ExportManager) build(ctx context.Context, j *job) (int64, error) {
+	entries, _ := m.history.List(historyFilter(j.Filter, j.owner))
+	m.update(j, func(e *models.ExportJob) { e.Entries = len(entries) })
+
+	tmp, err := os.CreateTemp(m.config.TempDir, ".export-*.zip")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archive := zip.NewWriter(tmp)
+	manifest := models.ExportManifest{Version: models.ExportManifestVersion, ExportedAt: time.Now()}
+	for i, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		entry = portable(entry)
+		for _, name := range entry.OutputFiles() {
+			err := m.addImage(ctx, archive, name, entry.CreatedAt)
+			switch {
+			case errors.Is(err, models.ErrFileNotFound):
+				m.update(j, func(e *models.ExportJob) { e.Missing++ })
+			case err != nil:
+				return 0, fmt.Errorf("failed to archive %s: %w", name, err)
+			default:
+				m.update(j, func(e *models.ExportJob) { e.Images++ })
+			}
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+
+		m.update(j, func(e *models.ExportJob) { e.Progress = float64(i+1) / float64(len(entries)) })
+	}
+
+	if err := writeManifest(archive, &manifest); err != nil {
+		return 0, err
+	}
+	if err := archive.Close(); err != nil {
+		return 0, err
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if err := m.storage.WriteOutput(ctx, archiveName(j.ID), tmp, info.Size(), "application/zip"); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// addImage copies an output into the archive. Images are already
+// compressed, so they are stored as is.
+func (m *ExportManager) addImage(ctx context.Context, archive *zip.Writer, name string, modified time.Time) error {
+	reader, _, err := m.storage.OpenOutput(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	w, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     imagePath(name),
+		Method:   zip.Store,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+// Import restores the entries and images of an export archive into owner's
+// history. Entries already in owner's history are skipped along with their
+// images, entries using another owner's ID are imported under a new ID.
+// Images are stored under new names, so an archive cannot replace existing
+// outputs.
+func (m *ExportManager) Import(ctx context.Context, archive io.ReaderAt, size int64, owner string) (*models.ImportReport, error) {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+	manifest, err := readManifest(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, file := range reader.File {
+		files[file.Name] = file
+	}
+
+	// Point the entries at new output names before they reach history
+	report := &models.ImportReport{}
+	sources := make(map[*models.HistoryEntry]map[string]*zip.File, len(manifest.Entries))
+	for _, entry := range manifest.Entries {
+		if entry == nil {
+			continue
+		}
+		entry.Owner = owner
+		renamed, missing := relocate(entry, files)
+		sources[entry] = renamed
+		for _, name := range missing {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: not in archive", name))
+		}
+	}
+
+	imported := m.history.Import(manifest.Entries)
+	report.Entries = len(imported)
+	report.Skipped = len(manifest.Entries) - len(imported)
+
+	ctx = storage.WithOwner(ctx, owner)
+	for _, entry := range imported {
+		for name, file := range sources[entry] {
+			if err := m.restoreImage(ctx, file, name); err != nil {
+				if errors.Is(err, models.ErrStorageFull) {
+					return report, err
+				}
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			report.Images++
+		}
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"entries": report.Entries,
+		"skipped": report.Skipped,
+		"images":  report.Images,
+		"errors":  len(report.Errors),
+	}).Info("Export archive imported")
+	return report, nil
+}
+
+// restoreImage writes an archived image back to output storage. The size
+// and quota checks use the size the archive declares, so no more than that
+// is read from the member.
+func (m *ExportManager) restoreImage(ctx context.Context, file *zip.File, name string) error {
+	size := int64(file.UncompressedSize64)
+	if size < 0 {
+		return fmt.Errorf("%w: %s has an invalid size", models.ErrInvalidArchive, file.Name)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return m.storage.WriteOutput(ctx, name, io.LimitReader(reader, size), size, mime.TypeByExtension(path.Ext(name)))
+}
+
+// readManifest decodes manifest.json from an archive
+func readManifest(archive *zip.Reader) (*models.ExportManifest, error) {
+	file, err := archive.Open(manifestJSON)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is missing", models.ErrInvalidArchive, manifestJSON)
+	}
+	defer file.Close()
+
+	var manifest models.ExportManifest
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+	if manifest.Version < 1 || manifest.Version > models.ExportManifestVersion {
+		return nil, fmt.Errorf("%w: unsupported manifest version %d", models.ErrInvalidArchive, manifest.Version)
+	}
+	return &manifest, nil
+}
+
+// Names of the manifests in an export archive
+const (
+	manifestJSON = "manifest.json"
+	manifestCSV  = "manifest.csv"
+)
+
+// csvHeader lists the columns of manifest.csv, one row per image
+var csvHeader = []string{"id", "status", "created_at", "model", "prompt", "negative_prompt", "seed", "width", "height", "steps", "cfg_scale", "sampler", "image"}
+
+// writeManifest adds manifest.json and manifest.csv to an archive
+func writeManifest(archive *zip.Writer, manifest *models.ExportManifest) error {
+	w, err := archive.CreateHeader(&zip.FileHeader{Name: manifestJSON, Method: zip.Deflate, Modified: manifest.ExportedAt})
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+
+	w, err = archive.CreateHeader(&zip.FileHeader{Name: manifestCSV, Method: zip.Deflate, Modified: manifest.ExportedAt})
+	if err != nil {
+		return err
+	}
+	rows := csv.NewWriter(w)
+	rows.Write(csvHeader)
+	for _, entry := range manifest.Entries {
+		req := entry.Request
+		row := func(seed int64, width, height int, image string) []string {
+			return []string{
+				entry.ID, string(entry.Status), entry.CreatedAt.Format(time.RFC3339),
+				req.Model, req.Prompt, req.NegPrompt,
+				strconv.FormatInt(seed, 10), strconv.Itoa(width), strconv.Itoa(height),
+				strconv.Itoa(req.Steps), strconv.FormatFloat(float64(req.CFGScale), 'f', -1, 64), req.Sampler, image,
+			}
+		}
+		if len(entry.Results) == 0 {
+			rows.Write(row(req.Seed, req.Width, req.Height, ""))
+		}
+		for _, result := range entry.Results {
+			image := ""
+			if result.ImagePath != "" {
+				image = imagePath(filepath.Base(result.ImagePath))
+			}
+			rows.Write(row(result.Seed, result.Width, result.Height, image))
+		}
+	}
+	rows.Flush()
+	return rows.Error()
+}
+
+// portable returns a copy of the entry with plain output URLs, since signed
+// ones would expire in the archive
+func portable(entry *models.HistoryEntry) *models.HistoryEntry {
+	copied := *entry
+	copied.Results = make([]models.GenerationResult, len(entry.Results))
+	for i, result := range entry.Results {
+		if result.ImagePath != "" {
+			result.ImageURL = "/outputs/" + filepath.Base(result.ImagePath)
+		}
+		thumbnails := make([]models.Thumbnail, len(result.Thumbnails))
+		for k, thumbnail := range result.Thumbnails {
+			thumbnail.ImageURL = "/outputs/" + thumbnail.ImagePath
+			thumbnails[k] = thumbnail
+		}
+		if len(thumbnails) > 0 {
+			result.Thumbnails = thumbnails
+		}
+		copied.Results[i] = result
+	}
+	return &copied
+}
+
+// relocate gives the archived images of an entry new output names and
+// rewrites the entry's paths to them. It returns the archive file of each new
+// name, and the names of images missing from the archive, whose paths are
+// cleared.
+func relocate(entry *models.HistoryEntry, files map[string]*zip.File) (map[string]*zip.File, []string) {
+	renamed := make(map[string]*zip.File)
+	var missing []string
+	rename := func(name string) string {
+		if name == "" {
+			return ""
+		}
+		file, exists := files[imagePath(filepath.Base(name))]
+		if !exists {
+			missing = append(missing, filepath.Base(name))
+			return ""
+		}
+		newName := uuid.New().String() + path.Ext(name)
+		renamed[newName] = file
+		return newName
+	}
+
+	results := make([]models.GenerationResult, len(entry.Results))
+	for i, result := range entry.Results {
+		result.ImageURL = ""
+		if result.ImagePath != "" {
+			if name := rename(result.ImagePath); name != "" {
+				result.ImagePath = filepath.Join(filepath.Dir(result.ImagePath), name)
+				result.ImageURL = "/outputs/" + name
+			} else {
+				result.ImagePath = ""
+			}
+		}
+		thumbnails := make([]models.Thumbnail, 0, len(result.Thumbnails))
+		for _, thumbnail := range result.Thumbnails {
+			if name := rename(thumbnail.ImagePath); name != "" {
+				thumbnail.ImagePath = name
+				thumbnail.ImageURL = "/outputs/" + name
+				thumbnails = append(thumbnails, thumbnail)
+			}
+		}
+		result.Thumbnails = nil
+		if len(thumbnails) > 0 {
+			result.Thumbnails = thumbnails
+		}
+		results[i] = result
+	}
+	entry.Results = results
+	return renamed, missing
+}
+
+// historyFilter converts an export filter into a filter of owner's history
+func historyFilter(filter models.ExportFilter, owner string) history.Filter {
+	hf := history.Filter{Owner: owner, Model: filter.Model, Tag: models.NormalizeTag(filter.Tag)}
+	if len(filter.IDs) > 0 {
+		hf.IDs = filter.IDs
+	}
+	if filter.From != nil {
+		hf.Since = *filter.From
+	}
+	if filter.To != nil {
+		hf.Until = *filter.To
+	}
+	return hf
+}
+
+// archiveName is the output name of an export's archive
+func archiveName(id string) string {
+	return "exports/" + id + ".zip"
+}
+
+// imagePath is where an output is stored in an archive
+func imagePath(name string) string {
+	return "images/" + name
+}
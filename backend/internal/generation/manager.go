@@ -0,0 +1,320 @@
+package generation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/history"
+	"github.com/ablerefusal/ablerefusal/internal/imaging"
+	"github.com/ablerefusal/ablerefusal/internal/inference"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/presets"
+	"github.com/ablerefusal/ablerefusal/internal/prompt"
+	"github.com/ablerefusal/ablerefusal/internal/queue"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Manager interface for submitting generation requests. It is shared by the
+// REST API and the compatibility facades so that every entry point applies
+// the same presets, styles, validation and prompt expansion.
+type Manager interface {
+	BuildRequest(body []byte) (*models.GenerationRequest, error)
+	Submit(req *models.GenerationRequest) ([]*models.GenerationRequest, int, error)
+	Wait(ctx context.Context, reqs []*models.GenerationRequest) ([]*models.GenerationStatus, error)
+}
+
+// GenerationManager implements the Manager interface
+type GenerationManager struct {
+	config    *config.Config
+	queue     queue.Manager
+	inference inference.Engine
+	presets   presets.Manager
+	history   history.Manager
+	prompts   *prompt.Processor
+	logger    *logrus.Logger
+}
+
+// NewManager creates a new generation manager
+func NewManager(cfg *config.Config, queue queue.Manager, inference inference.Engine, presets presets.Manager, history history.Manager, prompts *prompt.Processor, logger *logrus.Logger) Manager {
+	return &GenerationManager{
+		config:    cfg,
+		queue:     queue,
+		inference: inference,
+		presets:   presets,
+		history:   history,
+		prompts:   prompts,
+		logger:    logger,
+	}
+}
+
+// BuildRequest decodes a generation request body. When the body names a
+// preset, its parameters are applied over the defaults and the explicit
+// fields are decoded on top, so anything the client sends wins.
+func (m *GenerationManager) BuildRequest(body []byte) (*models.GenerationRequest, error) {
+	// Create request with defaults
+	req := models.NewGenerationRequest()
+
+	// Decode JSON request (this will override defaults with provided values)
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, err
+	}
+
+	if req.Preset != "" {
+		preset, err := m.presets.GetPreset(req.Preset)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, req.Preset)
+		}
+
+		req = models.NewGenerationRequest()
+		preset.ApplyTo(req)
+		if err := json.Unmarshal(body, req); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+// Submit validates and expands the request and adds the resulting items to
+// the queue. It returns the queued requests, the first of which keeps req.ID,
+// and the queue position of the first.
+func (m *GenerationManager) Submit(req *models.GenerationRequest) ([]*models.GenerationRequest, int, error) {
+	// Apply style templates to the prompts
+	if err := m.presets.ApplyStyles(req); err != nil {
+		return nil, -1, err
+	}
+
+	reqs, err := m.prepare(req)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	position, err := m.enqueueAll(reqs)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return reqs, position, nil
+}
+
+// Wait blocks until every request reaches a terminal state or ctx is done
+func (m *GenerationManager) Wait(ctx context.Context, reqs []*models.GenerationRequest) ([]*models.GenerationStatus, error) {
+	statuses := make([]*models.GenerationStatus, len(reqs))
+	for i, req := range reqs {
+		status, err := m.queue.Wait(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+		statuses[i] = status
+	}
+	return statuses, nil
+}
+
+// prepare fills in defaults, validates the request and expands its dynamic
+// prompt into one request per queue item. The first item keeps req.ID.
+func (m *GenerationManager) prepare(req *models.GenerationRequest) ([]*models.GenerationRequest, error) {
+	// Ensure ID is set. A client-supplied ID must not reuse a finished
+	// generation's, the queue rejects the ones it still tracks.
+	if req.ID == "" {
+		req.ID = uuid.New().String()
+	} else if m.history.Exists(req.ID) {
+		return nil, models.ErrGenerationExists
+	}
+
+	// Ensure model is set
+	if req.Model == "" {
+		req.Model = m.config.Models.DefaultModel
+	}
+
+	// Apply ControlNet defaults
+	for i := range req.ControlNets {
+		req.ControlNets[i].ApplyDefaults()
+	}
+
+	// Fetch the sampler catalogue from the inference backend
+	samplers, err := m.inference.ListSamplers()
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to list samplers, using built-in catalogue")
+		samplers = models.DefaultSamplerCatalogue()
+	}
+
+	// Validate request against the model's profile
+	opts := models.ValidationOptions{
+		Samplers:      samplers,
+		Profile:       m.modelProfile(req.Model),
+		OutputFormats: imaging.Formats(),
+	}
+	if err := req.ValidateWith(opts); err != nil {
+		return nil, err
+	}
+
+	// Normalize sampler aliases to the name the backend expects
+	req.Sampler, _ = samplers.Resolve(req.Sampler)
+
+	// Validate ControlNet units against the models the backend reports
+	if err := m.validateControlNets(req); err != nil {
+		return nil, err
+	}
+
+	// Expand wildcards and dynamic prompts
+	reqs, err := m.expandPrompts(req)
+	if errors.Is(err, models.ErrInvalidPrompt) {
+		return nil, models.ValidationErrors{{Field: "prompt", Message: err.Error(), Err: models.ErrInvalidPrompt}}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Expanded prompts may exceed the length limits
+	if len(reqs) > 1 || reqs[0].PromptTemplate != "" {
+		for _, r := range reqs {
+			if err := r.ValidateWith(opts); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return reqs, nil
+}
+
+// expandPrompts expands the request's prompt template. Each expanded prompt
+// becomes a separate request; the negative prompt is expanded once.
+func (m *GenerationManager) expandPrompts(req *models.GenerationRequest) ([]*models.GenerationRequest, error) {
+	seed := prompt.Seed(req.Seed)
+
+	prompts, err := m.prompts.Expand(req.Prompt, prompt.Mode(req.PromptMode), req.PromptCount, seed)
+	if err != nil {
+		return nil, err
+	}
+	negative, err := m.prompts.ExpandOne(req.NegPrompt, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(prompts) == 1 && prompts[0] == req.Prompt && negative == req.NegPrompt {
+		return []*models.GenerationRequest{req}, nil
+	}
+
+	reqs := make([]*models.GenerationRequest, len(prompts))
+	for i, expanded := range prompts {
+		clone := req.Clone()
+		if i > 0 {
+			clone.ID = uuid.New().String()
+			// Sampled prompts get successive seeds, so repeating a prompt
+			// without alternations still produces different images.
+			// Combinatorial prompts share the seed to compare them.
+			if clone.Seed >= 0 && prompt.Mode(req.PromptMode) != prompt.ModeCombinatorial {
+				clone.Seed = req.Seed + int64(i)
+			}
+		}
+		clone.PromptTemplate = req.Prompt
+		clone.Prompt = expanded
+		clone.NegPrompt = negative
+		reqs[i] = clone
+	}
+
+	return reqs, nil
+}
+
+// enqueueAll adds every request to the queue and returns the position of the
+// first. If any enqueue fails the already queued requests are cancelled.
+func (m *GenerationManager) enqueueAll(reqs []*models.GenerationRequest) (int, error) {
+	first := -1
+	for i, req := range reqs {
+		position, err := m.queue.Enqueue(req)
+		if err != nil {
+			for _, queued := range reqs[:i] {
+				m.queue.Cancel(queued.ID, queued.Owner)
+			}
+			return -1, err
+		}
+		if i == 0 {
+			first = position
+		}
+	}
+	return first, nil
+}
+
+// modelProfile builds the validation profile for a model from config,
+// capped by the global inference limits
+func (m *GenerationManager) modelProfile(model string) *models.ModelProfile {
+	profile := models.DefaultModelProfile()
+
+	if profileCfg := m.config.Models.ProfileFor(model); profileCfg != nil {
+		profile.Name = profileCfg.Name
+		if profileCfg.MinSize > 0 {
+			profile.MinSize = profileCfg.MinSize
+		}
+		if profileCfg.MaxSize > 0 {
+			profile.MaxSize = profileCfg.MaxSize
+		}
+		if profileCfg.DimensionMultiple > 0 {
+			profile.DimensionMultiple = profileCfg.DimensionMultiple
+		}
+		if profileCfg.MaxSteps > 0 {
+			profile.MaxSteps = profileCfg.MaxSteps
+		}
+		if profileCfg.MaxBatchSize > 0 {
+			profile.MaxBatchSize = profileCfg.MaxBatchSize
+		}
+		for _, value := range profileCfg.RecommendedSizes {
+			size, err := models.ParseImageSize(value)
+			if err != nil {
+				m.logger.WithError(err).WithField("profile", profileCfg.Name).Warn("Ignoring recommended size")
+				continue
+			}
+			profile.RecommendedSizes = append(profile.RecommendedSizes, size)
+		}
+	}
+
+	if maxResolution := m.config.Inference.MaxResolution; maxResolution > 0 && maxResolution < profile.MaxSize {
+		profile.MaxSize = maxResolution
+	}
+	if maxBatch := m.config.Inference.MaxBatchSize; maxBatch > 0 && maxBatch < profile.MaxBatchSize {
+		profile.MaxBatchSize = maxBatch
+	}
+
+	return profile
+}
+
+// validateControlNets checks each control unit against the inference backend's
+// catalogue and pins the unit to the resolved control model. Units cannot be
+// checked without the catalogue, so they are rejected while it is unavailable.
+func (m *GenerationManager) validateControlNets(req *models.GenerationRequest) error {
+	if len(req.ControlNets) == 0 {
+		return nil
+	}
+
+	if !m.inference.IsReady() {
+		return controlNetsUnavailable(models.ErrInferenceUnavailable)
+	}
+	available, err := m.inference.ListControlNets()
+	if err != nil {
+		return controlNetsUnavailable(err)
+	}
+
+	for i := range req.ControlNets {
+		unit := &req.ControlNets[i]
+		model, err := models.ResolveControlNet(unit, available)
+		if err != nil {
+			return fmt.Errorf("controlnet %d (%s): %w", i, unit.Type, err)
+		}
+		if !model.SupportsPreprocessor(unit.Preprocessor) {
+			return fmt.Errorf("controlnet %d (%s): unsupported preprocessor %q: %w", i, unit.Type, unit.Preprocessor, models.ErrInvalidControlNet)
+		}
+		unit.Model = model.Name
+	}
+
+	return nil
+}
+
+// controlNetsUnavailable reports that the control model catalogue could not be fetched
+func controlNetsUnavailable(err error) error {
+	return models.NewError(models.CodeInferenceUnavailable, http.StatusServiceUnavailable,
+		"ControlNet models cannot be checked while the inference backend is unavailable").Wrap(err)
+}
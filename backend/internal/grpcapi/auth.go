@@ -0,0 +1,39 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Auth returns server options that reject calls without one of the
+// configured API keys when auth is enabled, matching the REST API
+func Auth(cfg config.AuthConfig) []grpc.ServerOption {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	check := func(ctx context.Context) error {
+		if !cfg.ValidKey(apiKey(ctx)) {
+			return status.Error(codes.Unauthenticated, "missing or invalid API key")
+		}
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
@@ -0,0 +1,194 @@
+package grpcapi
+
+import (
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/pkg/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// requestBody converts a protobuf request into the JSON body accepted by the
+// REST API. Only fields that are set are included, so presets and defaults
+// fill in the rest.
+func requestBody(in *pb.GenerationRequest) map[string]interface{} {
+	body := make(map[string]interface{})
+	setString := func(key, value string) {
+		if value != "" {
+			body[key] = value
+		}
+	}
+
+	setString("id", in.GetId())
+	setString("prompt", in.GetPrompt())
+	setString("negative_prompt", in.GetNegativePrompt())
+	setString("model", in.GetModel())
+	setString("sampler", in.GetSampler())
+	setString("init_image", in.GetInitImage())
+	setString("mask", in.GetMask())
+	setString("preset", in.GetPreset())
+	setString("prompt_mode", in.GetPromptMode())
+	setString("output_format", in.GetOutputFormat())
+
+	if in.Width != nil {
+		body["width"] = in.GetWidth()
+	}
+	if in.Height != nil {
+		body["height"] = in.GetHeight()
+	}
+	if in.Steps != nil {
+		body["steps"] = in.GetSteps()
+	}
+	if in.CfgScale != nil {
+		body["cfg_scale"] = in.GetCfgScale()
+	}
+	if in.Seed != nil {
+		body["seed"] = in.GetSeed()
+	}
+	if in.BatchSize != nil {
+		body["batch_size"] = in.GetBatchSize()
+	}
+	if in.Strength != nil {
+		body["strength"] = in.GetStrength()
+	}
+	if in.OutputQuality != nil {
+		body["output_quality"] = in.GetOutputQuality()
+	}
+	if in.GetPromptCount() != 0 {
+		body["prompt_count"] = in.GetPromptCount()
+	}
+	if len(in.GetStyles()) > 0 {
+		body["styles"] = in.GetStyles()
+	}
+
+	if len(in.GetControlnets()) > 0 {
+		units := make([]models.ControlNetUnit, len(in.GetControlnets()))
+		for i, unit := range in.GetControlnets() {
+			units[i] = models.ControlNetUnit{
+				Type:          unit.GetType(),
+				Model:         unit.GetModel(),
+				Image:         unit.GetImage(),
+				Weight:        unit.Weight,
+				GuidanceStart: unit.GetGuidanceStart(),
+				GuidanceEnd:   unit.GetGuidanceEnd(),
+				Preprocessor:  unit.GetPreprocessor(),
+			}
+		}
+		body["controlnets"] = units
+	}
+
+	if webhook := in.GetWebhook(); webhook != nil {
+		// WebhookConfig.MarshalJSON drops the secret, so pass it as a map
+		body["webhook"] = map[string]interface{}{
+			"url":    webhook.GetUrl(),
+			"secret": webhook.GetSecret(),
+			"events": webhook.GetEvents(),
+		}
+	}
+
+	return body
+}
+
+// toRequest converts a generation request to protobuf. Webhook secrets are not included.
+func toRequest(req *models.GenerationRequest) *pb.GenerationRequest {
+	out := &pb.GenerationRequest{
+		Id:             req.ID,
+		Prompt:         req.Prompt,
+		NegativePrompt: req.NegPrompt,
+		Model:          req.Model,
+		Width:          proto.Int32(int32(req.Width)),
+		Height:         proto.Int32(int32(req.Height)),
+		Steps:          proto.Int32(int32(req.Steps)),
+		CfgScale:       proto.Float32(req.CFGScale),
+		Seed:           proto.Int64(req.Seed),
+		BatchSize:      proto.Int32(int32(req.BatchSize)),
+		Sampler:        req.Sampler,
+		InitImage:      req.InitImage,
+		Mask:           req.Mask,
+		Preset:         req.Preset,
+		Styles:         req.Styles,
+		PromptMode:     req.PromptMode,
+		PromptCount:    int32(req.PromptCount),
+		PromptTemplate: req.PromptTemplate,
+		CreatedAt:      timestamppb.New(req.CreatedAt),
+		OutputFormat:   string(req.OutputFormat),
+	}
+	if req.OutputQuality != 0 {
+		out.OutputQuality = proto.Int32(int32(req.OutputQuality))
+	}
+	if req.InitImage != "" {
+		out.Strength = proto.Float32(req.Strength)
+	}
+	for i := range req.ControlNets {
+		unit := &req.ControlNets[i]
+		out.Controlnets = append(out.Controlnets, &pb.ControlNetUnit{
+			Type:          unit.Type,
+			Model:         unit.Model,
+			Image:         unit.Image,
+			Weight:        unit.Weight,
+			GuidanceStart: proto.Float32(unit.GuidanceStart),
+			GuidanceEnd:   proto.Float32(unit.GuidanceEnd),
+			Preprocessor:  unit.Preprocessor,
+		})
+	}
+	if req.Webhook != nil {
+		out.Webhook = &pb.WebhookConfig{Url: req.Webhook.URL, Events: req.Webhook.Events}
+	}
+	return out
+}
+
+// toStatus converts a generation status to protobuf
+func toStatus(status *models.GenerationStatus) *pb.GenerationStatus {
+	out := &pb.GenerationStatus{
+		Id:          status.ID,
+		State:       toState(status.Status),
+		Progress:    status.Progress,
+		CurrentStep: int32(status.CurrentStep),
+		TotalSteps:  int32(status.TotalSteps),
+		Error:       status.Error,
+		ErrorCode:   string(status.ErrorCode),
+	}
+	if status.StartedAt != nil {
+		out.StartedAt = timestamppb.New(*status.StartedAt)
+	}
+	if status.CompletedAt != nil {
+		out.CompletedAt = timestamppb.New(*status.CompletedAt)
+	}
+	for _, result := range status.Results {
+		converted := &pb.GenerationResult{
+			ImagePath: result.ImagePath,
+			ImageUrl:  result.ImageURL,
+			Seed:      result.Seed,
+			Width:     int32(result.Width),
+			Height:    int32(result.Height),
+			Metadata:  result.Metadata,
+		}
+		for _, thumbnail := range result.Thumbnails {
+			converted.Thumbnails = append(converted.Thumbnails, &pb.Thumbnail{
+				Width:     int32(thumbnail.Width),
+				Height:    int32(thumbnail.Height),
+				ImagePath: thumbnail.ImagePath,
+				ImageUrl:  thumbnail.ImageURL,
+			})
+		}
+		out.Results = append(out.Results, converted)
+	}
+	return out
+}
+
+// toState converts a generation status type to the protobuf enum
+func toState(status models.GenerationStatusType) pb.GenerationState {
+	switch status {
+	case models.StatusQueued:
+		return pb.GenerationState_GENERATION_STATE_QUEUED
+	case models.StatusProcessing:
+		return pb.GenerationState_GENERATION_STATE_PROCESSING
+	case models.StatusCompleted:
+		return pb.GenerationState_GENERATION_STATE_COMPLETED
+	case models.StatusFailed:
+		return pb.GenerationState_GENERATION_STATE_FAILED
+	case models.StatusCancelled:
+		return pb.GenerationState_GENERATION_STATE_CANCELLED
+	default:
+		return pb.GenerationState_GENERATION_STATE_UNSPECIFIED
+	}
+}
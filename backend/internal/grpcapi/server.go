@@ -0,0 +1,197 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ablerefusal/ablerefusal/internal/generation"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/queue"
+	"github.com/ablerefusal/ablerefusal/pkg/pb"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the GenerationService gRPC API on top of the queue
+type Server struct {
+	pb.UnimplementedGenerationServiceServer
+	queue      queue.Manager
+	generation generation.Manager
+	logger     *logrus.Logger
+}
+
+// NewServer creates a new gRPC generation service
+func NewServer(queue queue.Manager, generation generation.Manager, logger *logrus.Logger) *Server {
+	return &Server{
+		queue:      queue,
+		generation: generation,
+		logger:     logger,
+	}
+}
+
+// Register creates a gRPC server with the generation service registered
+func (s *Server) Register(opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	pb.RegisterGenerationServiceServer(server, s)
+	return server
+}
+
+// Generate validates and queues a generation request
+func (s *Server) Generate(ctx context.Context, in *pb.GenerateRequest) (*pb.GenerateResponse, error) {
+	if in.GetRequest() == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+
+	// Decode through the same path as the REST API so presets apply identically
+	body, err := json.Marshal(requestBody(in.GetRequest()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	req, err := s.generation.BuildRequest(body)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	req.Owner = apiKeyID(ctx)
+
+	reqs, position, err := s.generation.Submit(req)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to queue gRPC generation")
+		var validationErrs models.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrs):
+			return nil, status.Error(codes.InvalidArgument, validationErrs.Error())
+		case errors.Is(err, models.ErrQueueFull):
+			return nil, status.Error(codes.ResourceExhausted, "queue is full, please try again later")
+		case errors.Is(err, models.ErrStorageFull):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		default:
+			return nil, status.Error(submitCode(err), err.Error())
+		}
+	}
+
+	ids := make([]string, len(reqs))
+	for i, r := range reqs {
+		ids[i] = r.ID
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"request_id": req.ID,
+		"position":   position,
+		"count":      len(reqs),
+	}).Info("gRPC generation request queued")
+
+	return &pb.GenerateResponse{
+		Id:       req.ID,
+		Ids:      ids,
+		Position: int32(position),
+	}, nil
+}
+
+// GetStatus returns the status of a generation
+func (s *Server) GetStatus(ctx context.Context, in *pb.GetStatusRequest) (*pb.GenerationStatus, error) {
+	genStatus, err := s.queue.GetStatus(in.GetId(), apiKeyID(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toStatus(genStatus), nil
+}
+
+// Cancel cancels a generation
+func (s *Server) Cancel(ctx context.Context, in *pb.CancelRequest) (*pb.CancelResponse, error) {
+	owner := apiKeyID(ctx)
+	if err := s.queue.Cancel(in.GetId(), owner); err != nil {
+		return nil, statusError(err)
+	}
+
+	genStatus, err := s.queue.GetStatus(in.GetId(), owner)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	s.logger.WithField("request_id", in.GetId()).Info("Generation cancelled")
+	return &pb.CancelResponse{Id: in.GetId(), State: toState(genStatus.Status)}, nil
+}
+
+// ListQueue returns the caller's queued and running generations
+func (s *Server) ListQueue(ctx context.Context, in *pb.ListQueueRequest) (*pb.ListQueueResponse, error) {
+	items, err := s.queue.GetQueue(apiKeyID(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &pb.ListQueueResponse{Items: make([]*pb.QueueItem, len(items))}
+	for i, item := range items {
+		resp.Items[i] = &pb.QueueItem{
+			Request:  toRequest(item.Request),
+			Status:   toStatus(item.Status),
+			Position: int32(item.Position),
+		}
+	}
+	return resp, nil
+}
+
+// WatchJob streams status updates until the generation finishes
+func (s *Server) WatchJob(in *pb.WatchJobRequest, stream pb.GenerationService_WatchJobServer) error {
+	updates, err := s.queue.Watch(stream.Context(), in.GetId(), apiKeyID(stream.Context()))
+	if err != nil {
+		return statusError(err)
+	}
+
+	for update := range updates {
+		if err := stream.Send(toStatus(&update)); err != nil {
+			return err
+		}
+	}
+
+	return stream.Context().Err()
+}
+
+// statusError maps queue errors to gRPC status codes
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrGenerationNotFound):
+		return status.Error(codes.NotFound, "generation not found")
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// submitCode maps other submit errors to gRPC codes by their REST status,
+// so unsupported features are Unimplemented rather than InvalidArgument
+func submitCode(err error) codes.Code {
+	switch models.AsError(err).Status {
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return codes.Unavailable
+	case http.StatusInternalServerError:
+		return codes.Internal
+	default:
+		return codes.InvalidArgument
+	}
+}
+
+// apiKeyID returns the ID of the API key in the x-api-key or authorization
+// metadata, matching the REST API
+func apiKeyID(ctx context.Context) string {
+	return models.APIKeyID(apiKey(ctx))
+}
+
+// apiKey returns the API key in the x-api-key or authorization metadata
+func apiKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get("x-api-key"); len(keys) > 0 && keys[0] != "" {
+		return keys[0]
+	}
+	if auth := md.Get("authorization"); len(auth) > 0 {
+		return models.BearerToken(auth[0])
+	}
+	return ""
+}
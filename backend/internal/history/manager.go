@@ -0,0 +1,465 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Manager interface for generation history operations
+type Manager interface {
+	Record(req *models.GenerationRequest, status models.GenerationStatus)
+	List(filter Filter) ([]*models.HistoryEntry, int)
+	Get(id, owner string) (*models.HistoryEntry, error)
+	Exists(id string) bool
+	SetPinned(id, owner string, pinned bool) (*models.HistoryEntry, error)
+	SetFavourite(id, owner string, favourite bool) (*models.HistoryEntry, error)
+	SetRating(id, owner string, rating int) (*models.HistoryEntry, error)
+	SetTags(id, owner string, tags []string) (*models.HistoryEntry, error)
+	Tags(owner string) []models.TagCount
+	DeleteTag(tag, owner string) int
+	Import(entries []*models.HistoryEntry) (imported []*models.HistoryEntry)
+	OutputFiles() map[string]bool
+	Flush()
+}
+
+// Filter selects history entries. Only entries of Owner match, the empty
+// owner selects the entries recorded without an API key.
+type Filter struct {
+	Owner     string
+	Status    models.GenerationStatusType
+	Query     string   // Case-insensitive substring of the prompt
+	IDs       []string // Only these entries, nil for all
+	Model     string
+	Since     time.Time // Created at or after, zero for no bound
+	Until     time.Time // Created before, zero for no bound
+	Tag       string
+	Favourite bool // Only favourites
+	MinRating int
+	Limit     int
+	Offset    int
+}
+
+// matches reports whether the entry passes the filter, query is lowercased
+func (f *Filter) matches(entry *models.HistoryEntry, query string) bool {
+	switch {
+	case entry.Owner != f.Owner:
+		return false
+	case f.Status != "" && entry.Status != f.Status:
+		return false
+	case query != "" && !strings.Contains(strings.ToLower(entry.Request.Prompt), query):
+		return false
+	case f.IDs != nil && !slices.Contains(f.IDs, entry.ID):
+		return false
+	case f.Model != "" && entry.Request.Model != f.Model:
+		return false
+	case !f.Since.IsZero() && entry.CreatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !entry.CreatedAt.Before(f.Until):
+		return false
+	case f.Tag != "" && !entry.HasTag(f.Tag):
+		return false
+	case f.Favourite && !entry.Favourite:
+		return false
+	case f.MinRating > 0 && entry.Rating < f.MinRating:
+		return false
+	}
+	return true
+}
+
+// HistoryManager implements the Manager interface.
+// Entries are kept in memory and persisted as a JSON file under the data
+// directory. The file is written by a background writer, so recording a
+// generation never waits for the disk.
+type HistoryManager struct {
+	config config.HistoryConfig
+	logger *logrus.Logger
+	file   string
+
+	writeMu sync.Mutex    // Serialises writes of the history file
+	dirty   chan struct{} // Wakes the writer after a change
+
+	mu      sync.RWMutex
+	entries map[string]*models.HistoryEntry
+}
+
+// NewManager creates a new history manager
+func NewManager(cfg config.HistoryConfig, storageConfig config.StorageConfig, logger *logrus.Logger) (Manager, error) {
+	file := cfg.File
+	if file == "" {
+		file = filepath.Join(storageConfig.DataDir, "history.json")
+	}
+
+	m := &HistoryManager{
+		config:  cfg,
+		logger:  logger,
+		file:    file,
+		dirty:   make(chan struct{}, 1),
+		entries: make(map[string]*models.HistoryEntry),
+	}
+
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	go m.writer()
+	return m, nil
+}
+
+// Record stores a generation that reached a terminal state.
+// It matches the queue.Listener signature.
+func (m *HistoryManager) Record(req *models.GenerationRequest, status models.GenerationStatus) {
+	if req == nil {
+		return
+	}
+
+	// Input images are large and already known to the client, don't keep them
+	stored := *req
+	stored.InitImage = ""
+	stored.Mask = ""
+	stored.ControlNets = make([]models.ControlNetUnit, len(req.ControlNets))
+	for i, unit := range req.ControlNets {
+		unit.Image = ""
+		stored.ControlNets[i] = unit
+	}
+
+	entry := &models.HistoryEntry{
+		ID:          req.ID,
+		Owner:       req.Owner,
+		Request:     &stored,
+		Status:      status.Status,
+		Results:     status.Results,
+		Error:       status.Error,
+		CreatedAt:   req.CreatedAt,
+		StartedAt:   status.StartedAt,
+		CompletedAt: status.CompletedAt,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[entry.ID] = entry
+	m.prune()
+	m.persist()
+}
+
+// List returns matching entries, newest first, and the total number of matches
+func (m *HistoryManager) List(filter Filter) ([]*models.HistoryEntry, int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	query := strings.ToLower(filter.Query)
+	entries := make([]*models.HistoryEntry, 0)
+	for _, entry := range m.entries {
+		if !filter.matches(entry, query) {
+			continue
+		}
+		snapshot := *entry
+		entries = append(entries, &snapshot)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+
+	total := len(entries)
+	if filter.Offset > 0 {
+		entries = entries[min(filter.Offset, total):]
+	}
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, total
+}
+
+// Get returns a single history entry of owner
+func (m *HistoryManager) Get(id, owner string) (*models.HistoryEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, exists := m.entries[id]
+	if !exists || entry.Owner != owner {
+		return nil, models.ErrHistoryNotFound
+	}
+
+	snapshot := *entry
+	return &snapshot, nil
+}
+
+// Exists reports whether an entry with the ID exists, whoever owns it
+func (m *HistoryManager) Exists(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, exists := m.entries[id]
+	return exists
+}
+
+// Import adds entries restored from an export. Entries already in their
+// owner's history are left unchanged. An entry whose ID belongs to another
+// owner gets a new ID, so imports cannot tell which IDs others use. The
+// added entries are returned.
+func (m *HistoryManager) Import(entries []*models.HistoryEntry) (imported []*models.HistoryEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, entry := range entries {
+		if entry == nil || entry.ID == "" || entry.Request == nil {
+			continue
+		}
+		if existing, exists := m.entries[entry.ID]; exists {
+			if existing.Owner == entry.Owner {
+				continue
+			}
+			entry.ID = uuid.New().String()
+			request := *entry.Request
+			request.ID = entry.ID
+			entry.Request = &request
+		}
+		m.entries[entry.ID] = entry
+		imported = append(imported, entry)
+	}
+	if len(imported) > 0 {
+		m.prune()
+		m.persist()
+	}
+	return imported
+}
+
+// SetPinned pins or unpins an entry. Outputs of pinned entries are kept by
+// the storage janitor.
+func (m *HistoryManager) SetPinned(id, owner string, pinned bool) (*models.HistoryEntry, error) {
+	return m.update(id, owner, func(entry *models.HistoryEntry) {
+		entry.Pinned = pinned
+	})
+}
+
+// SetFavourite marks or unmarks an entry as a favourite. Outputs of
+// favourites are kept by the storage janitor.
+func (m *HistoryManager) SetFavourite(id, owner string, favourite bool) (*models.HistoryEntry, error) {
+	return m.update(id, owner, func(entry *models.HistoryEntry) {
+		entry.Favourite = favourite
+	})
+}
+
+// SetRating rates an entry from 1 to 5 stars, 0 clears the rating
+func (m *HistoryManager) SetRating(id, owner string, rating int) (*models.HistoryEntry, error) {
+	if err := models.ValidateRating(rating); err != nil {
+		return nil, err
+	}
+	return m.update(id, owner, func(entry *models.HistoryEntry) {
+		entry.Rating = rating
+	})
+}
+
+// SetTags replaces the tags of an entry
+func (m *HistoryManager) SetTags(id, owner string, tags []string) (*models.HistoryEntry, error) {
+	tags, err := models.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	return m.update(id, owner, func(entry *models.HistoryEntry) {
+		entry.Tags = tags
+	})
+}
+
+// Tags returns every tag owner uses with its number of entries, most used
+// first
+func (m *HistoryManager) Tags(owner string) []models.TagCount {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, entry := range m.entries {
+		if entry.Owner != owner {
+			continue
+		}
+		for _, tag := range entry.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]models.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, models.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
+
+// DeleteTag removes a tag from every entry of owner and returns how many
+// carried it
+func (m *HistoryManager) DeleteTag(tag, owner string) int {
+	tag = models.NormalizeTag(tag)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for _, entry := range m.entries {
+		if entry.Owner != owner {
+			continue
+		}
+		if i := slices.Index(entry.Tags, tag); i >= 0 {
+			entry.Tags = slices.Delete(slices.Clone(entry.Tags), i, i+1)
+			removed++
+		}
+	}
+	if removed > 0 {
+		m.persist()
+	}
+	return removed
+}
+
+// update applies fn to an entry of owner, persists it and returns a snapshot
+func (m *HistoryManager) update(id, owner string, fn func(*models.HistoryEntry)) (*models.HistoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.entries[id]
+	if !exists || entry.Owner != owner {
+		return nil, models.ErrHistoryNotFound
+	}
+
+	fn(entry)
+	m.persist()
+
+	snapshot := *entry
+	return &snapshot, nil
+}
+
+// OutputFiles returns the output file names referenced by history entries,
+// mapped to whether they are pinned or favourites and so protected from
+// cleanup. It matches the
+// storage.ReferenceFunc signature.
+func (m *HistoryManager) OutputFiles() map[string]bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files := make(map[string]bool)
+	for _, entry := range m.entries {
+		for _, file := range entry.OutputFiles() {
+			files[file] = files[file] || entry.Protected()
+		}
+	}
+	return files
+}
+
+// prune drops the oldest entries beyond the configured limit, caller must
+// hold the lock. Pinned and favourite entries are never dropped and do not
+// count towards the limit, so their outputs stay referenced.
+func (m *HistoryManager) prune() {
+	if m.config.MaxEntries <= 0 || len(m.entries) <= m.config.MaxEntries {
+		return
+	}
+
+	entries := make([]*models.HistoryEntry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		if !entry.Protected() {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) <= m.config.MaxEntries {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	for _, entry := range entries[:len(entries)-m.config.MaxEntries] {
+		delete(m.entries, entry.ID)
+	}
+}
+
+// load reads the history from disk
+func (m *HistoryManager) load() error {
+	data, err := os.ReadFile(m.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []*models.HistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse history: %w", err)
+	}
+
+	for _, entry := range entries {
+		m.entries[entry.ID] = entry
+	}
+	return nil
+}
+
+// persist schedules a write of the history. Changes made while a write is
+// pending are saved by that write.
+func (m *HistoryManager) persist() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// writer writes the history after every change
+func (m *HistoryManager) writer() {
+	for range m.dirty {
+		m.write()
+	}
+}
+
+// Flush writes the history now, for shutdown
+func (m *HistoryManager) Flush() {
+	m.write()
+}
+
+// write saves a snapshot of the history to disk
+func (m *HistoryManager) write() {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	m.mu.RLock()
+	entries := make([]*models.HistoryEntry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	data, err := json.MarshalIndent(entries, "", "  ")
+	m.mu.RUnlock()
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to encode history")
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.file), 0755); err != nil {
+		m.logger.WithError(err).Error("Failed to create history directory")
+		return
+	}
+
+	tmp := m.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		m.logger.WithError(err).Error("Failed to write history")
+		return
+	}
+	if err := os.Rename(tmp, m.file); err != nil {
+		m.logger.WithError(err).Error("Failed to write history")
+	}
+}
@@ -0,0 +1,95 @@
+package history
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// newManager creates a manager keeping maxEntries entries in a temp directory
+func newManager(t *testing.T, maxEntries int) *HistoryManager {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	m, err := NewManager(config.HistoryConfig{MaxEntries: maxEntries}, config.StorageConfig{DataDir: t.TempDir()}, logger)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	// Let the writer finish before the temp directory is removed
+	t.Cleanup(m.Flush)
+	return m.(*HistoryManager)
+}
+
+// record stores a completed generation created at the given minute
+func record(m *HistoryManager, id string, minute int) {
+	created := time.Date(2026, 1, 1, 0, minute, 0, 0, time.UTC)
+	m.Record(&models.GenerationRequest{ID: id, Owner: "key-1", CreatedAt: created},
+		models.GenerationStatus{ID: id, Status: models.StatusCompleted})
+}
+
+func TestPruneKeepsProtectedEntries(t *testing.T) {
+	m := newManager(t, 2)
+
+	record(m, "pinned", 0)
+	record(m, "favourite", 1)
+	if _, err := m.SetPinned("pinned", "key-1", true); err != nil {
+		t.Fatalf("SetPinned: %v", err)
+	}
+	if _, err := m.SetFavourite("favourite", "key-1", true); err != nil {
+		t.Fatalf("SetFavourite: %v", err)
+	}
+	for i, id := range []string{"old", "middle", "new"} {
+		record(m, id, 2+i)
+	}
+
+	// Protected entries survive and don't use up the limit of two
+	for _, id := range []string{"pinned", "favourite", "middle", "new"} {
+		if _, err := m.Get(id, "key-1"); err != nil {
+			t.Errorf("Get(%q) = %v, want the entry kept", id, err)
+		}
+	}
+	if _, err := m.Get("old", "key-1"); err == nil {
+		t.Error("the oldest unprotected entry was not pruned")
+	}
+}
+
+func TestImportScopesExistingIDsToOwner(t *testing.T) {
+	m := newManager(t, 0)
+	record(m, "job-1", 0)
+
+	entry := func(owner string) *models.HistoryEntry {
+		return &models.HistoryEntry{
+			ID:      "job-1",
+			Owner:   owner,
+			Request: &models.GenerationRequest{ID: "job-1", Prompt: "imported"},
+			Status:  models.StatusCompleted,
+		}
+	}
+
+	// Re-importing an owner's own entry is skipped
+	if imported := m.Import([]*models.HistoryEntry{entry("key-1")}); len(imported) != 0 {
+		t.Errorf("import of key-1's own entry added %d entries, want it skipped", len(imported))
+	}
+
+	// Another owner's import of the same ID is added under a new ID, leaving
+	// the existing entry alone
+	imported := m.Import([]*models.HistoryEntry{entry("key-2")})
+	if len(imported) != 1 {
+		t.Fatalf("import by key-2 added %d entries, want 1", len(imported))
+	}
+	id := imported[0].ID
+	if id == "job-1" || imported[0].Request.ID != id {
+		t.Errorf("imported entry ID = %q, request ID = %q, want a new ID on both", id, imported[0].Request.ID)
+	}
+	if _, err := m.Get(id, "key-2"); err != nil {
+		t.Errorf("Get(%q, key-2) = %v, want the imported entry", id, err)
+	}
+	if existing, err := m.Get("job-1", "key-1"); err != nil || existing.Request.Prompt == "imported" {
+		t.Errorf("Get(job-1, key-1) = %v, %v, want the original entry", existing, err)
+	}
+}
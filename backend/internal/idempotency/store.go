@@ -0,0 +1,151 @@
+// Package idempotency remembers the responses of requests sent with an
+// Idempotency-Key header so retries return the original result instead of
+// creating duplicate work.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrKeyMismatch is returned when a key is reused with a different request
+	ErrKeyMismatch = errors.New("idempotency key was already used with a different request")
+	// ErrInProgress is returned when a request with the same key is still being handled
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// Record is a stored response for an idempotency key
+type Record struct {
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	completed   bool
+}
+
+// Store interface for idempotency key bookkeeping
+type Store interface {
+	// Begin reserves a key. If the key already holds a completed response for the
+	// same fingerprint the record is returned for replay.
+	Begin(key, fingerprint string) (*Record, error)
+	// Complete stores the response for a reserved key
+	Complete(key string, statusCode int, header http.Header, body []byte)
+	// Release drops a reservation so the key can be retried
+	Release(key string)
+}
+
+// MemoryStore is an in-memory Store whose records expire after a window
+type MemoryStore struct {
+	window  time.Duration
+	mu      sync.Mutex
+	records map[string]*Record
+	lastGC  time.Time
+}
+
+// NewMemoryStore creates an in-memory store keeping keys for window
+func NewMemoryStore(window time.Duration) *MemoryStore {
+	return &MemoryStore{
+		window:  window,
+		records: make(map[string]*Record),
+		lastGC:  time.Now(),
+	}
+}
+
+// Begin implements Store
+func (s *MemoryStore) Begin(key, fingerprint string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.collect(now)
+
+	if record, exists := s.records[key]; exists && now.Before(record.ExpiresAt) {
+		if record.Fingerprint != fingerprint {
+			return nil, ErrKeyMismatch
+		}
+		if !record.completed {
+			return nil, ErrInProgress
+		}
+		return record, nil
+	}
+
+	s.records[key] = &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.window),
+	}
+	return nil, nil
+}
+
+// Complete implements Store
+func (s *MemoryStore) Complete(key string, statusCode int, header http.Header, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, exists := s.records[key]; exists {
+		record.StatusCode = statusCode
+		record.Header = header.Clone()
+		record.Body = append([]byte(nil), body...)
+		record.completed = true
+	}
+}
+
+// Release implements Store
+func (s *MemoryStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, exists := s.records[key]; exists && !record.completed {
+		delete(s.records, key)
+	}
+}
+
+// collect removes expired records at most once a minute, caller must hold the lock
+func (s *MemoryStore) collect(now time.Time) {
+	if now.Sub(s.lastGC) < time.Minute {
+		return
+	}
+	s.lastGC = now
+
+	for key, record := range s.records {
+		if now.After(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// Fingerprint hashes a request's method, path, query and body. The query
+// and JSON bodies are canonicalised first so parameter order, whitespace and
+// key order do not matter; non-JSON bodies are hashed as-is.
+func Fingerprint(method, path, rawQuery string, body []byte) string {
+	query := rawQuery
+	if values, err := url.ParseQuery(rawQuery); err == nil {
+		query = values.Encode()
+	}
+
+	canonical := body
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		if encoded, err := json.Marshal(decoded); err == nil {
+			canonical = encoded
+		}
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s?%s\n", method, path, query)
+	hash.Write(bytes.TrimSpace(canonical))
+	return hex.EncodeToString(hash.Sum(nil))
+}
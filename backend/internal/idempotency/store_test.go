@@ -0,0 +1,88 @@
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreReplay(t *testing.T) {
+	s := NewMemoryStore(time.Hour)
+
+	if record, err := s.Begin("key", "fp"); record != nil || err != nil {
+		t.Fatalf("first Begin = %v, %v, want a reservation", record, err)
+	}
+	if _, err := s.Begin("key", "fp"); !errors.Is(err, ErrInProgress) {
+		t.Errorf("Begin while in progress = %v, want ErrInProgress", err)
+	}
+
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	s.Complete("key", http.StatusAccepted, header, []byte(`{"id":"job-1"}`))
+
+	record, err := s.Begin("key", "fp")
+	if err != nil || record == nil {
+		t.Fatalf("Begin after Complete = %v, %v, want the record", record, err)
+	}
+	if record.StatusCode != http.StatusAccepted || string(record.Body) != `{"id":"job-1"}` || record.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("record = %d %q %v", record.StatusCode, record.Body, record.Header)
+	}
+
+	if _, err := s.Begin("key", "other"); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("Begin with another fingerprint = %v, want ErrKeyMismatch", err)
+	}
+}
+
+func TestMemoryStoreReleaseAndExpiry(t *testing.T) {
+	s := NewMemoryStore(20 * time.Millisecond)
+
+	// A released key can be used again, even for another request
+	s.Begin("released", "fp")
+	s.Release("released")
+	if record, err := s.Begin("released", "other"); record != nil || err != nil {
+		t.Errorf("Begin after Release = %v, %v, want a new reservation", record, err)
+	}
+
+	// An expired key no longer replays or conflicts
+	s.Begin("expiring", "fp")
+	s.Complete("expiring", http.StatusAccepted, http.Header{}, []byte("{}"))
+	time.Sleep(30 * time.Millisecond)
+	if record, err := s.Begin("expiring", "other"); record != nil || err != nil {
+		t.Errorf("Begin after expiry = %v, %v, want a new reservation", record, err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint(http.MethodPost, "/api/v1/generate", "wait=true&timeout=10", []byte(`{"prompt":"a cat","steps":20}`))
+
+	same := []struct {
+		desc            string
+		method, path, q string
+		body            string
+	}{
+		{"reordered JSON keys and whitespace", http.MethodPost, "/api/v1/generate", "wait=true&timeout=10", "{ \"steps\": 20, \"prompt\": \"a cat\" }\n"},
+		{"reordered query", http.MethodPost, "/api/v1/generate", "timeout=10&wait=true", `{"prompt":"a cat","steps":20}`},
+	}
+	for _, tt := range same {
+		if got := Fingerprint(tt.method, tt.path, tt.q, []byte(tt.body)); got != base {
+			t.Errorf("%s: fingerprint differs", tt.desc)
+		}
+	}
+
+	different := []struct {
+		desc            string
+		method, path, q string
+		body            string
+	}{
+		{"other body", http.MethodPost, "/api/v1/generate", "wait=true&timeout=10", `{"prompt":"a dog","steps":20}`},
+		{"other query", http.MethodPost, "/api/v1/generate", "wait=false&timeout=10", `{"prompt":"a cat","steps":20}`},
+		{"no query", http.MethodPost, "/api/v1/generate", "", `{"prompt":"a cat","steps":20}`},
+		{"other path", http.MethodPost, "/api/v1/exports", "wait=true&timeout=10", `{"prompt":"a cat","steps":20}`},
+		{"other method", http.MethodPut, "/api/v1/generate", "wait=true&timeout=10", `{"prompt":"a cat","steps":20}`},
+	}
+	for _, tt := range different {
+		if got := Fingerprint(tt.method, tt.path, tt.q, []byte(tt.body)); got == base {
+			t.Errorf("%s: fingerprint matches", tt.desc)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// AVIF is encoded and decoded with avifenc and avifdec from libavif, and only
+// offered when both are on the PATH
+func init() {
+	encodeTool, encodeErr := exec.LookPath("avifenc")
+	decodeTool, decodeErr := exec.LookPath("avifdec")
+	if encodeErr != nil || decodeErr != nil {
+		return
+	}
+
+	encoders[models.FormatAVIF] = func(w io.Writer, img image.Image, quality int) error {
+		return encodeAVIF(encodeTool, w, img, quality)
+	}
+	decoders[models.FormatAVIF] = func(r io.Reader) (image.Image, error) {
+		return decodeAVIF(decodeTool, r)
+	}
+}
+
+// encodeAVIF converts img through a PNG in a scratch directory
+func encodeAVIF(tool string, w io.Writer, img image.Image, quality int) error {
+	dir, err := os.MkdirTemp("", "avif-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	input, output := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.avif")
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	if err := os.WriteFile(input, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	if err := run(tool, "-q", strconv.Itoa(quality), "-s", "6", input, output); err != nil {
+		return err
+	}
+	return copyFile(w, output)
+}
+
+// decodeAVIF converts the image to PNG in a scratch directory
+func decodeAVIF(tool string, r io.Reader) (image.Image, error) {
+	dir, err := os.MkdirTemp("", "avif-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input, output := filepath.Join(dir, "in.avif"), filepath.Join(dir, "out.png")
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(input, data, 0600); err != nil {
+		return nil, err
+	}
+	if err := run(tool, input, output); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(output)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return png.Decode(file)
+}
+
+// run executes a libavif tool, returning its output on failure
+func run(tool string, args ...string) error {
+	if out, err := exec.Command(tool, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", filepath.Base(tool), err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// copyFile writes the file at name to w
+func copyFile(w io.Writer, name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
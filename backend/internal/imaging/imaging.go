@@ -0,0 +1,144 @@
+// Package imaging encodes, decodes and resizes generated images.
+package imaging
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register the WebP decoder
+)
+
+// ErrUnsupportedFormat is returned for formats this build cannot encode or decode
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// encoder writes img at a quality from 1 to 100
+type encoder func(w io.Writer, img image.Image, quality int) error
+
+// decoder reads an image in a format the image package cannot decode
+type decoder func(r io.Reader) (image.Image, error)
+
+var (
+	encoders = map[models.ImageFormat]encoder{
+		models.FormatPNG:  encodePNG,
+		models.FormatJPEG: encodeJPEG,
+	}
+	decoders = map[models.ImageFormat]decoder{}
+)
+
+// defaultQuality is used when no quality is requested
+var defaultQuality = map[models.ImageFormat]int{
+	models.FormatJPEG: 90,
+	models.FormatWebP: 85,
+	models.FormatAVIF: 60,
+}
+
+// Formats returns the formats this build can encode
+func Formats() []models.ImageFormat {
+	formats := make([]models.ImageFormat, 0, len(encoders))
+	for format := range encoders {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i] < formats[j] })
+	return formats
+}
+
+// Supported reports whether format can be encoded
+func Supported(format models.ImageFormat) bool {
+	_, ok := encoders[format]
+	return ok
+}
+
+// Encode writes img in format. A quality of 0 uses the format's default;
+// PNG is lossless and ignores it.
+func Encode(w io.Writer, img image.Image, format models.ImageFormat, quality int) error {
+	encode, ok := encoders[format]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	if quality <= 0 {
+		quality = defaultQuality[format]
+	}
+	return encode(w, img, min(quality, 100))
+}
+
+// Decode reads an image and reports its format
+func Decode(r io.Reader) (image.Image, models.ImageFormat, error) {
+	buffered := bufio.NewReader(r)
+	header, _ := buffered.Peek(12)
+	if isAVIF(header) {
+		decode, ok := decoders[models.FormatAVIF]
+		if !ok {
+			return nil, "", fmt.Errorf("%w: avif", ErrUnsupportedFormat)
+		}
+		img, err := decode(buffered)
+		return img, models.FormatAVIF, err
+	}
+
+	img, name, err := image.Decode(buffered)
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, "", ErrUnsupportedFormat
+		}
+		return nil, "", err
+	}
+	format, _ := models.ParseImageFormat(name)
+	return img, format, nil
+}
+
+// Resize scales img down to width, keeping its aspect ratio. Images that are
+// not wider than width are returned unchanged.
+func Resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if width <= 0 || bounds.Dx() <= width {
+		return img
+	}
+	height := max(1, (bounds.Dy()*width+bounds.Dx()/2)/bounds.Dx())
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+	return resized
+}
+
+// ContentType returns the MIME type of format
+func ContentType(format models.ImageFormat) string {
+	return "image/" + string(format)
+}
+
+// Extension returns the file extension of format, including the dot
+func Extension(format models.ImageFormat) string {
+	if format == models.FormatJPEG {
+		return ".jpg"
+	}
+	return "." + string(format)
+}
+
+// FormatOf returns the format implied by a file name's extension
+func FormatOf(name string) (models.ImageFormat, bool) {
+	return models.ParseImageFormat(strings.ToLower(strings.TrimPrefix(path.Ext(name), ".")))
+}
+
+func encodePNG(w io.Writer, img image.Image, quality int) error {
+	return png.Encode(w, img)
+}
+
+func encodeJPEG(w io.Writer, img image.Image, quality int) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+// isAVIF reports whether header starts an AVIF file
+func isAVIF(header []byte) bool {
+	if len(header) < 12 || string(header[4:8]) != "ftyp" {
+		return false
+	}
+	brand := string(header[8:12])
+	return brand == "avif" || brand == "avis"
+}
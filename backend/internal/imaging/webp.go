@@ -0,0 +1,20 @@
+//go:build cgo
+
+package imaging
+
+import (
+	"image"
+	"io"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/chai2010/webp"
+)
+
+// WebP encoding uses the bundled libwebp and needs cgo
+func init() {
+	encoders[models.FormatWebP] = encodeWebP
+}
+
+func encodeWebP(w io.Writer, img image.Image, quality int) error {
+	return webp.Encode(w, img, &webp.Options{Quality: float32(quality)})
+}
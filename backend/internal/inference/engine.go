@@ -17,6 +17,8 @@ type Engine interface {
 	LoadModel(modelPath string) error
 	Generate(ctx context.Context, req *models.GenerationRequest, progressCallback func(float64, int)) ([]*models.GenerationResult, error)
 	GetLoadedModels() []string
+	ListControlNets() ([]models.ControlNetModel, error)
+	ListSamplers() (*models.SamplerCatalogue, error)
 	IsReady() bool
 }
 
@@ -74,6 +76,11 @@ func (e *InferenceEngine) LoadModel(modelPath string) error {
 func (e *InferenceEngine) Generate(ctx context.Context, req *models.GenerationRequest, progressCallback func(float64, int)) ([]*models.GenerationResult, error) {
 	e.logger.WithField("request_id", req.ID).Info("Starting generation")
 
+	// The mock cannot condition, so never return unconditioned images
+	if len(req.ControlNets) > 0 {
+		return nil, models.ErrControlNetUnsupported
+	}
+
 	// TODO: Implement actual ONNX inference
 	// For now, this is a mock implementation
 
@@ -81,7 +88,7 @@ func (e *InferenceEngine) Generate(ctx context.Context, req *models.GenerationRe
 	for step := 1; step <= req.Steps; step++ {
 		select {
 		case <-ctx.Done():
-			return nil, fmt.Errorf("generation cancelled")
+			return nil, contextError(ctx)
 		default:
 			// Simulate processing time
 			time.Sleep(100 * time.Millisecond)
@@ -133,6 +140,17 @@ func (e *InferenceEngine) GetLoadedModels() []string {
 	return models
 }
 
+// ListControlNets returns the available ControlNet models
+func (e *InferenceEngine) ListControlNets() ([]models.ControlNetModel, error) {
+	// TODO: Report ControlNet models once ONNX is integrated
+	return []models.ControlNetModel{}, nil
+}
+
+// ListSamplers returns the built-in sampler catalogue
+func (e *InferenceEngine) ListSamplers() (*models.SamplerCatalogue, error) {
+	return models.DefaultSamplerCatalogue(), nil
+}
+
 // IsReady returns whether the engine is ready for inference
 func (e *InferenceEngine) IsReady() bool {
 	return e.ready
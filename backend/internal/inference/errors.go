@@ -0,0 +1,39 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// pythonErrorCodes maps the error codes reported by the Python service for
+// failed jobs to model errors. Unknown codes become models.ErrInferenceFailed.
+var pythonErrorCodes = map[string]error{
+	"out_of_memory":        models.ErrOutOfMemory,
+	"model_not_loaded":     models.ErrModelNotLoaded,
+	"pipeline_unavailable": models.ErrPipelineUnavailable,
+	"invalid_input":        models.ErrInvalidInput,
+}
+
+// contextError returns the error for a generation stopped by ctx
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return models.ErrGenerationTimeout
+	}
+	return models.ErrGenerationCancelled
+}
+
+// jobError converts a failed Python job into a model error that keeps the
+// service's message
+func jobError(status *PythonJobStatus) error {
+	sentinel, exists := pythonErrorCodes[status.ErrorCode]
+	if !exists {
+		sentinel = models.ErrInferenceFailed
+	}
+	if status.Error == "" {
+		return sentinel
+	}
+	return fmt.Errorf("%w: %s", sentinel, status.Error)
+}
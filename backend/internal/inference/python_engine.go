@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/ablerefusal/ablerefusal/internal/config"
 	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/prompt"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -23,8 +25,16 @@ type PythonEngine struct {
 	baseURL       string
 	httpClient    *http.Client
 	ready         bool
+
+	// Cached sampler catalogue
+	samplerMu       sync.Mutex
+	samplers        *models.SamplerCatalogue
+	samplersFetched time.Time
 }
 
+// samplerCacheTTL is how long the sampler catalogue is cached before being refetched
+const samplerCacheTTL = 5 * time.Minute
+
 // PythonGenerateRequest represents the request to Python service
 type PythonGenerateRequest struct {
 	Prompt         string                   `json:"prompt"`
@@ -40,9 +50,26 @@ type PythonGenerateRequest struct {
 	Loras          []map[string]interface{} `json:"loras,omitempty"`
 	EnableLCM      bool                     `json:"enable_lcm"`
 	ClipSkip       int                      `json:"clip_skip"`
+	// Attention weighted segments, set when the prompt uses (word:1.3) syntax
+	PromptWeights         []prompt.Segment `json:"prompt_weights,omitempty"`
+	NegativePromptWeights []prompt.Segment `json:"negative_prompt_weights,omitempty"`
 	// Image-to-image parameters
 	InitImage string  `json:"init_image,omitempty"`
 	Strength  float32 `json:"strength,omitempty"`
+	Mask      string  `json:"mask,omitempty"`
+	// ControlNet conditioning
+	ControlNets []PythonControlNetUnit `json:"controlnets,omitempty"`
+}
+
+// PythonControlNetUnit represents a ControlNet unit in the Python request
+type PythonControlNetUnit struct {
+	Type          string  `json:"type"`
+	Model         string  `json:"model,omitempty"`
+	Image         string  `json:"image"`
+	Weight        float32 `json:"weight"`
+	GuidanceStart float32 `json:"guidance_start"`
+	GuidanceEnd   float32 `json:"guidance_end"`
+	Preprocessor  string  `json:"preprocessor,omitempty"`
 }
 
 // PythonGenerateResponse represents the response from Python service
@@ -62,6 +89,7 @@ type PythonJobStatus struct {
 	Message     string    `json:"message,omitempty"`
 	Results     []string  `json:"results,omitempty"`
 	Error       string    `json:"error,omitempty"`
+	ErrorCode   string    `json:"error_code,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	CompletedAt time.Time `json:"completed_at,omitempty"`
 }
@@ -177,6 +205,33 @@ func (e *PythonEngine) Generate(ctx context.Context, req *models.GenerationReque
 		ClipSkip:       1,
 		InitImage:      req.InitImage,
 		Strength:       req.Strength,
+		Mask:           req.Mask,
+	}
+
+	// Diffusers reads prompts as plain text, so attention syntax is sent as
+	// weighted segments for the service to encode
+	var err error
+	if pythonReq.Prompt, pythonReq.PromptWeights, err = attention(req.Prompt); err != nil {
+		return nil, err
+	}
+	if pythonReq.NegativePrompt, pythonReq.NegativePromptWeights, err = attention(req.NegPrompt); err != nil {
+		return nil, err
+	}
+
+	for _, unit := range req.ControlNets {
+		weight := float32(1.0)
+		if unit.Weight != nil {
+			weight = *unit.Weight
+		}
+		pythonReq.ControlNets = append(pythonReq.ControlNets, PythonControlNetUnit{
+			Type:          unit.Type,
+			Model:         unit.Model,
+			Image:         unit.Image,
+			Weight:        weight,
+			GuidanceStart: unit.GuidanceStart,
+			GuidanceEnd:   unit.GuidanceEnd,
+			Preprocessor:  unit.Preprocessor,
+		})
 	}
 
 	// Parse extra parameters if present
@@ -212,11 +267,11 @@ func (e *PythonEngine) Generate(ctx context.Context, req *models.GenerationReque
 	// Parse response
 	var genResp PythonGenerateResponse
 	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
-		return nil, fmt.Errorf("failed to parse generation response: %w", err)
+		return nil, fmt.Errorf("%w: failed to parse generation response: %v", models.ErrInferenceFailed, err)
 	}
 
 	if genResp.Status != "accepted" {
-		return nil, fmt.Errorf("generation not accepted: %s", genResp.Message)
+		return nil, fmt.Errorf("%w: %s", models.ErrInferenceRejected, genResp.Message)
 	}
 
 	// Poll for job completion
@@ -227,11 +282,11 @@ func (e *PythonEngine) Generate(ctx context.Context, req *models.GenerationReque
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, fmt.Errorf("generation cancelled")
+			return nil, contextError(ctx)
 		case <-ticker.C:
 			status, err := e.getJobStatus(jobID)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("%w: %v", models.ErrInferenceUnavailable, err)
 			}
 
 			// Update progress
@@ -244,7 +299,7 @@ func (e *PythonEngine) Generate(ctx context.Context, req *models.GenerationReque
 			case "completed":
 				return e.processResults(req, status)
 			case "failed":
-				return nil, fmt.Errorf("generation failed: %s", status.Error)
+				return nil, jobError(status)
 			}
 		}
 	}
@@ -294,6 +349,20 @@ func (e *PythonEngine) processResults(req *models.GenerationRequest, status *Pyt
 	return results, nil
 }
 
+// attention splits a prompt into its plain text and, when it uses attention
+// syntax, its weighted segments
+func attention(text string) (string, []prompt.Segment, error) {
+	segments, err := prompt.ParseAttention(text)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", models.ErrInvalidPrompt, err)
+	}
+	var weights []prompt.Segment
+	if prompt.Weighted(segments) {
+		weights = segments
+	}
+	return prompt.Text(segments), weights, nil
+}
+
 // mockGenerate provides fallback mock generation
 func (e *PythonEngine) mockGenerate(ctx context.Context, req *models.GenerationRequest, progressCallback func(float64, int)) ([]*models.GenerationResult, error) {
 	// Similar to ONNX engine's mock generation
@@ -304,7 +373,7 @@ func (e *PythonEngine) mockGenerate(ctx context.Context, req *models.GenerationR
 		for step := 1; step <= req.Steps; step++ {
 			select {
 			case <-ctx.Done():
-				return nil, fmt.Errorf("generation cancelled")
+				return nil, contextError(ctx)
 			default:
 				time.Sleep(50 * time.Millisecond)
 				
@@ -365,6 +434,112 @@ func (e *PythonEngine) GetLoadedModels() []string {
 	return modelsResp.Loaded
 }
 
+// ListControlNets returns the ControlNet models reported by the Python service
+func (e *PythonEngine) ListControlNets() ([]models.ControlNetModel, error) {
+	if !e.ready {
+		return []models.ControlNetModel{}, nil
+	}
+
+	resp, err := e.httpClient.Get(e.baseURL + "/controlnets")
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get controlnets: %v", models.ErrInferenceUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w: failed to get controlnets: %s", models.ErrInferenceUnavailable, string(body))
+	}
+
+	var controlNetsResp struct {
+		ControlNets []models.ControlNetModel `json:"controlnets"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&controlNetsResp); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse controlnets response: %v", models.ErrInferenceFailed, err)
+	}
+
+	return controlNetsResp.ControlNets, nil
+}
+
+// ListSamplers returns the sampler catalogue reported by the Python service.
+// The catalogue is cached for samplerCacheTTL; if the service cannot be reached
+// the last fetched catalogue (or the built-in one) is returned.
+func (e *PythonEngine) ListSamplers() (*models.SamplerCatalogue, error) {
+	if !e.ready {
+		return models.DefaultSamplerCatalogue(), nil
+	}
+
+	e.samplerMu.Lock()
+	defer e.samplerMu.Unlock()
+
+	if e.samplers != nil && time.Since(e.samplersFetched) < samplerCacheTTL {
+		return e.samplers, nil
+	}
+
+	catalogue, err := e.fetchSamplers()
+	if err != nil {
+		e.logger.WithError(err).Warn("Failed to fetch sampler catalogue, using cached catalogue")
+		if e.samplers != nil {
+			return e.samplers, nil
+		}
+		return models.DefaultSamplerCatalogue(), nil
+	}
+
+	e.samplers = catalogue
+	e.samplersFetched = time.Now()
+	return catalogue, nil
+}
+
+// fetchSamplers fetches the sampler catalogue from the Python service
+func (e *PythonEngine) fetchSamplers() (*models.SamplerCatalogue, error) {
+	resp, err := e.httpClient.Get(e.baseURL + "/samplers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get samplers: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get samplers: %s", string(body))
+	}
+
+	var samplersResp struct {
+		Samplers    []string            `json:"samplers"`
+		LCMSamplers []string            `json:"lcm_samplers"`
+		Schedulers  map[string]string   `json:"schedulers"`
+		Aliases     map[string][]string `json:"aliases"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&samplersResp); err != nil {
+		return nil, fmt.Errorf("failed to parse samplers response: %w", err)
+	}
+
+	if len(samplersResp.Samplers) == 0 {
+		return nil, fmt.Errorf("python service reported no samplers")
+	}
+
+	catalogue := &models.SamplerCatalogue{}
+	for _, name := range samplersResp.Samplers {
+		catalogue.Samplers = append(catalogue.Samplers, models.SamplerInfo{
+			Name:      name,
+			Aliases:   samplersResp.Aliases[name],
+			Scheduler: samplersResp.Schedulers[name],
+		})
+	}
+	for _, name := range samplersResp.LCMSamplers {
+		catalogue.Samplers = append(catalogue.Samplers, models.SamplerInfo{
+			Name:      name,
+			Aliases:   samplersResp.Aliases[name],
+			Scheduler: samplersResp.Schedulers[name],
+			LCM:       true,
+		})
+	}
+	catalogue.Finalize()
+
+	return catalogue, nil
+}
+
 // IsReady returns whether the engine is ready for inference
 func (e *PythonEngine) IsReady() bool {
 	return e.ready
@@ -0,0 +1,270 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Manager interface for collection operations. Tags, ratings and
+// favourites are kept on the history entries themselves. Collections belong
+// to the API key that created them, other keys see them as not found.
+type Manager interface {
+	List(owner string) []*models.Collection
+	Get(id, owner string) (*models.Collection, error)
+	Create(name, description, owner string) (*models.Collection, error)
+	Update(id, owner, name, description string) (*models.Collection, error)
+	Delete(id, owner string) error
+	AddEntry(id, owner, entryID string) (*models.Collection, error)
+	RemoveEntry(id, owner, entryID string) (*models.Collection, error)
+}
+
+// LibraryManager implements the Manager interface.
+// Collections are persisted as a JSON file under the data directory.
+type LibraryManager struct {
+	logger *logrus.Logger
+	file   string
+
+	mu          sync.RWMutex
+	collections map[string]*models.Collection
+}
+
+// NewManager creates a new library manager
+func NewManager(cfg config.LibraryConfig, storageConfig config.StorageConfig, logger *logrus.Logger) (Manager, error) {
+	file := cfg.File
+	if file == "" {
+		file = filepath.Join(storageConfig.DataDir, "library.json")
+	}
+
+	m := &LibraryManager{
+		logger:      logger,
+		file:        file,
+		collections: make(map[string]*models.Collection),
+	}
+
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// List returns owner's collections ordered by name
+func (m *LibraryManager) List(owner string) []*models.Collection {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	collections := make([]*models.Collection, 0, len(m.collections))
+	for _, collection := range m.collections {
+		if collection.Owner == owner {
+			collections = append(collections, snapshot(collection))
+		}
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return strings.ToLower(collections[i].Name) < strings.ToLower(collections[j].Name)
+	})
+	return collections
+}
+
+// Get returns a single collection of owner
+func (m *LibraryManager) Get(id, owner string) (*models.Collection, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	collection, exists := m.collections[id]
+	if !exists || collection.Owner != owner {
+		return nil, models.ErrCollectionNotFound
+	}
+	return snapshot(collection), nil
+}
+
+// Create adds an empty collection for owner. Names are unique per owner,
+// ignoring case.
+func (m *LibraryManager) Create(name, description, owner string) (*models.Collection, error) {
+	now := time.Now()
+	collection := &models.Collection{
+		ID:          uuid.New().String(),
+		Owner:       owner,
+		Name:        strings.TrimSpace(name),
+		Description: description,
+		EntryIDs:    []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := collection.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.nameTaken(owner, collection.Name, "") {
+		return nil, models.ErrCollectionExists
+	}
+	m.collections[collection.ID] = collection
+	if err := m.save(); err != nil {
+		delete(m.collections, collection.ID)
+		return nil, err
+	}
+	return snapshot(collection), nil
+}
+
+// Update renames a collection of owner and replaces its description
+func (m *LibraryManager) Update(id, owner, name, description string) (*models.Collection, error) {
+	name = strings.TrimSpace(name)
+	if err := (&models.Collection{Name: name}).Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.nameTaken(owner, name, id) {
+		return nil, models.ErrCollectionExists
+	}
+	return m.update(id, owner, func(collection *models.Collection) {
+		collection.Name = name
+		collection.Description = description
+	})
+}
+
+// Delete removes a collection of owner. Its entries stay in history.
+func (m *LibraryManager) Delete(id, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	collection, exists := m.collections[id]
+	if !exists || collection.Owner != owner {
+		return models.ErrCollectionNotFound
+	}
+	delete(m.collections, id)
+	if err := m.save(); err != nil {
+		m.collections[id] = collection
+		return err
+	}
+	return nil
+}
+
+// AddEntry adds a history entry to a collection of owner. Adding an entry
+// twice has no effect.
+func (m *LibraryManager) AddEntry(id, owner, entryID string) (*models.Collection, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.update(id, owner, func(collection *models.Collection) {
+		if !slices.Contains(collection.EntryIDs, entryID) {
+			collection.EntryIDs = append(collection.EntryIDs, entryID)
+		}
+	})
+}
+
+// RemoveEntry removes a history entry from a collection of owner
+func (m *LibraryManager) RemoveEntry(id, owner, entryID string) (*models.Collection, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.update(id, owner, func(collection *models.Collection) {
+		collection.EntryIDs = slices.DeleteFunc(collection.EntryIDs, func(e string) bool { return e == entryID })
+	})
+}
+
+// update applies fn to a copy of a collection of owner and saves it, caller
+// must hold the lock
+func (m *LibraryManager) update(id, owner string, fn func(*models.Collection)) (*models.Collection, error) {
+	current, exists := m.collections[id]
+	if !exists || current.Owner != owner {
+		return nil, models.ErrCollectionNotFound
+	}
+
+	updated := snapshot(current)
+	fn(updated)
+	updated.UpdatedAt = time.Now()
+
+	m.collections[id] = updated
+	if err := m.save(); err != nil {
+		m.collections[id] = current
+		return nil, err
+	}
+	return snapshot(updated), nil
+}
+
+// nameTaken reports whether another collection of owner than except uses
+// name, caller must hold the lock
+func (m *LibraryManager) nameTaken(owner, name, except string) bool {
+	for id, collection := range m.collections {
+		if id != except && collection.Owner == owner && strings.EqualFold(collection.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the collections from disk
+func (m *LibraryManager) load() error {
+	data, err := os.ReadFile(m.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read library: %w", err)
+	}
+
+	var collections []*models.Collection
+	if err := json.Unmarshal(data, &collections); err != nil {
+		return fmt.Errorf("failed to parse library: %w", err)
+	}
+
+	for _, collection := range collections {
+		m.collections[collection.ID] = collection
+	}
+	m.logger.WithField("collections", len(collections)).Info("Loaded library")
+	return nil
+}
+
+// save writes the collections to disk, caller must hold the lock
+func (m *LibraryManager) save() error {
+	collections := make([]*models.Collection, 0, len(m.collections))
+	for _, collection := range m.collections {
+		collections = append(collections, collection)
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].CreatedAt.Before(collections[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(collections, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.file), 0755); err != nil {
+		return fmt.Errorf("failed to create library directory: %w", err)
+	}
+
+	// Write atomically so a crash never leaves a truncated file
+	tmp := m.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to save library: %w", err)
+	}
+	return os.Rename(tmp, m.file)
+}
+
+// snapshot copies a collection so callers cannot change the stored one
+func snapshot(collection *models.Collection) *models.Collection {
+	copied := *collection
+	copied.EntryIDs = slices.Clone(collection.EntryIDs)
+	if copied.EntryIDs == nil {
+		copied.EntryIDs = []string{}
+	}
+	return &copied
+}
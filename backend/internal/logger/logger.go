@@ -67,6 +67,16 @@ func NewWithConfig(level string, file string) (*logrus.Logger, error) {
 	return logger, nil
 }
 
+// SetLevel changes the level of a running logger
+func SetLevel(logger *logrus.Logger, level string) error {
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logger.SetLevel(logLevel)
+	return nil
+}
+
 // WithRequestID adds request ID to logger context
 func WithRequestID(logger *logrus.Logger, requestID string) *logrus.Entry {
 	return logger.WithField("request_id", requestID)
@@ -0,0 +1,224 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable error identifier. Clients should
+// branch on the code, messages may change.
+type ErrorCode string
+
+const (
+	// Request errors
+	CodeInvalidRequest   ErrorCode = "invalid_request"
+	CodeValidationFailed ErrorCode = "validation_failed"
+	CodeEndpointNotFound ErrorCode = "endpoint_not_found"
+	CodeNotImplemented   ErrorCode = "not_implemented"
+	CodeInternal         ErrorCode = "internal_error"
+
+	// Idempotency errors
+	CodeIdempotencyKeyReused  ErrorCode = "idempotency_key_reused"
+	CodeIdempotencyInProgress ErrorCode = "idempotency_in_progress"
+
+	// Generation and queue errors
+	CodeQueueFull           ErrorCode = "queue_full"
+	CodeGenerationNotFound  ErrorCode = "generation_not_found"
+	CodeGenerationExists    ErrorCode = "generation_exists"
+	CodeGenerationTimeout   ErrorCode = "generation_timeout"
+	CodeGenerationCancelled ErrorCode = "generation_cancelled"
+	CodeImageNotFound       ErrorCode = "image_not_found"
+
+	// Inference errors
+	CodeInferenceUnavailable ErrorCode = "inference_unavailable"
+	CodeInferenceRejected    ErrorCode = "inference_rejected"
+	CodeInferenceFailed      ErrorCode = "inference_failed"
+	CodeOutOfMemory          ErrorCode = "out_of_memory"
+	CodeModelNotFound        ErrorCode = "model_not_found"
+	CodeModelNotLoaded       ErrorCode = "model_not_loaded"
+	CodePipelineUnavailable  ErrorCode = "pipeline_unavailable"
+	CodeInvalidInput         ErrorCode = "invalid_input"
+	CodeControlNetNotFound   ErrorCode = "controlnet_not_found"
+
+	// Resource errors
+	CodePresetNotFound        ErrorCode = "preset_not_found"
+	CodePresetExists          ErrorCode = "preset_exists"
+	CodeStyleNotFound         ErrorCode = "style_not_found"
+	CodeStyleExists           ErrorCode = "style_exists"
+	CodePresetReadOnly        ErrorCode = "preset_read_only"
+	CodeDeliveryNotFound      ErrorCode = "delivery_not_found"
+	CodeDeliveryNotReplayable ErrorCode = "delivery_not_replayable"
+	CodeHistoryNotFound       ErrorCode = "history_not_found"
+	CodeFileNotFound          ErrorCode = "file_not_found"
+	CodeExportNotFound        ErrorCode = "export_not_found"
+	CodeExportNotReady        ErrorCode = "export_not_ready"
+	CodeCollectionNotFound    ErrorCode = "collection_not_found"
+	CodeCollectionExists      ErrorCode = "collection_exists"
+
+	// Storage errors
+	CodeStorageUnavailable ErrorCode = "storage_unavailable"
+	CodeStorageFull        ErrorCode = "storage_full"
+	CodeFileTooLarge       ErrorCode = "file_too_large"
+
+	// Access errors
+	CodeUnauthorized ErrorCode = "unauthorized"
+	CodeForbidden    ErrorCode = "forbidden"
+)
+
+// Error is an error returned to API clients with a code and HTTP status
+type Error struct {
+	Code    ErrorCode
+	Status  int
+	Message string
+	Details map[string]interface{}
+	Fields  ValidationErrors
+	Err     error
+}
+
+// NewError creates an API error
+func NewError(code ErrorCode, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil && e.Err.Error() != e.Message {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetail returns a copy of the error with an extra detail
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	copied := *e
+	copied.Details = make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		copied.Details[k] = v
+	}
+	copied.Details[key] = value
+	return &copied
+}
+
+// WithStatus returns a copy of the error with a different HTTP status
+func (e *Error) WithStatus(status int) *Error {
+	copied := *e
+	copied.Status = status
+	return &copied
+}
+
+// Wrap returns a copy of the error caused by err
+func (e *Error) Wrap(err error) *Error {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+// errorMapping maps a sentinel error to its API representation
+type errorMapping struct {
+	err     error
+	code    ErrorCode
+	status  int
+	message string // Empty uses the sentinel's message
+}
+
+// errorMappings is checked in order with errors.Is
+var errorMappings = []errorMapping{
+	{ErrEmptyPrompt, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidPrompt, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidDimensions, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidSteps, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidCFGScale, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidBatchSize, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidControlNet, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidSampler, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidStrength, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidWebhook, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidMask, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidOutputFormat, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrInvalidRequest, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrQueueFull, CodeQueueFull, http.StatusServiceUnavailable, "Queue is full, please try again later"},
+	{ErrGenerationNotFound, CodeGenerationNotFound, http.StatusNotFound, "Generation not found"},
+	{ErrGenerationExists, CodeGenerationExists, http.StatusConflict, "Generation ID is already in use"},
+	{ErrGenerationTimeout, CodeGenerationTimeout, http.StatusGatewayTimeout, "Generation timed out"},
+	{ErrGenerationCancelled, CodeGenerationCancelled, http.StatusConflict, "Generation was cancelled"},
+	{ErrInferenceUnavailable, CodeInferenceUnavailable, http.StatusBadGateway, ""},
+	{ErrInferenceRejected, CodeInferenceRejected, http.StatusBadGateway, ""},
+	{ErrOutOfMemory, CodeOutOfMemory, http.StatusServiceUnavailable, ""},
+	{ErrModelNotLoaded, CodeModelNotLoaded, http.StatusServiceUnavailable, ""},
+	{ErrPipelineUnavailable, CodePipelineUnavailable, http.StatusUnprocessableEntity, ""},
+	{ErrInvalidInput, CodeInvalidInput, http.StatusBadRequest, ""},
+	{ErrInferenceFailed, CodeInferenceFailed, http.StatusBadGateway, ""},
+	{ErrModelNotFound, CodeModelNotFound, http.StatusNotFound, ""},
+	{ErrModelLoadFailed, CodeInferenceFailed, http.StatusBadGateway, ""},
+	{ErrControlNetNotFound, CodeControlNetNotFound, http.StatusBadRequest, ""},
+	{ErrControlNetUnsupported, CodeNotImplemented, http.StatusNotImplemented, ""},
+	{ErrPresetNotFound, CodePresetNotFound, http.StatusNotFound, ""},
+	{ErrStyleNotFound, CodeStyleNotFound, http.StatusNotFound, ""},
+	{ErrPresetExists, CodePresetExists, http.StatusConflict, "Preset already exists"},
+	{ErrStyleExists, CodeStyleExists, http.StatusConflict, "Style already exists"},
+	{ErrPresetReadOnly, CodePresetReadOnly, http.StatusForbidden, ""},
+	{ErrInvalidPreset, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrDeliveryNotFound, CodeDeliveryNotFound, http.StatusNotFound, "Delivery not found"},
+	{ErrHistoryNotFound, CodeHistoryNotFound, http.StatusNotFound, "History entry not found"},
+	{ErrFileNotFound, CodeFileNotFound, http.StatusNotFound, ""},
+	{ErrExportNotFound, CodeExportNotFound, http.StatusNotFound, "Export not found"},
+	{ErrExportNotReady, CodeExportNotReady, http.StatusConflict, ""},
+	{ErrInvalidArchive, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrCollectionNotFound, CodeCollectionNotFound, http.StatusNotFound, "Collection not found"},
+	{ErrCollectionExists, CodeCollectionExists, http.StatusConflict, ""},
+	{ErrInvalidFileName, CodeInvalidRequest, http.StatusBadRequest, ""},
+	{ErrStorageUnavailable, CodeStorageUnavailable, http.StatusBadGateway, "Storage backend unavailable"},
+	{ErrStorageFull, CodeStorageFull, http.StatusInsufficientStorage, ""},
+	{ErrFileTooLarge, CodeFileTooLarge, http.StatusRequestEntityTooLarge, ""},
+	{ErrUnauthorized, CodeUnauthorized, http.StatusUnauthorized, "Missing or invalid API key"},
+	{ErrForbidden, CodeForbidden, http.StatusForbidden, ""},
+}
+
+// AsError converts any error into an API error. Errors that are not known
+// become internal errors so their text is not leaked to clients.
+func AsError(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var validationErrs ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return &Error{
+			Code:    CodeValidationFailed,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request",
+			Fields:  validationErrs,
+			Err:     err,
+		}
+	}
+
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.err) {
+			message := mapping.message
+			if message == "" {
+				message = err.Error()
+			}
+			return &Error{Code: mapping.code, Status: mapping.status, Message: message, Err: err}
+		}
+	}
+
+	return &Error{Code: CodeInternal, Status: http.StatusInternalServerError, Message: "Internal server error", Err: err}
+}
+
+// CodeOf returns the error code of err, or an empty code for nil
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	return AsError(err).Code
+}
+
+// InvalidRequest creates an error for a malformed request
+func InvalidRequest(message string) *Error {
+	return NewError(CodeInvalidRequest, http.StatusBadRequest, message)
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// CleanupReason explains why the janitor removed a file
+type CleanupReason string
+
+const (
+	CleanupExpired     CleanupReason = "expired"      // Older than the maximum age
+	CleanupOverSize    CleanupReason = "over_size"    // Removed to bring outputs under the size limit
+	CleanupTempExpired CleanupReason = "temp_expired" // Temp file older than its TTL
+	CleanupOrphaned    CleanupReason = "orphaned"     // No history entry references the file
+)
+
+// CleanupItem is a file removed, or that would be removed, by a cleanup run
+type CleanupItem struct {
+	Path       string        `json:"path"`
+	Size       int64         `json:"size"`
+	ModifiedAt time.Time     `json:"modified_at"`
+	Reason     CleanupReason `json:"reason"`
+}
+
+// CleanupReport describes a single cleanup run
+type CleanupReport struct {
+	DryRun         bool          `json:"dry_run"`
+	StartedAt      time.Time     `json:"started_at"`
+	CompletedAt    time.Time     `json:"completed_at"`
+	Items          []CleanupItem `json:"items"`
+	FilesRemoved   int           `json:"files_removed"`
+	BytesReclaimed int64         `json:"bytes_reclaimed"`
+	Errors         []string      `json:"errors,omitempty"`
+}
+
+// CleanupStats are the janitor's cumulative metrics since startup.
+// Dry runs are counted in Runs but reclaim nothing.
+type CleanupStats struct {
+	Runs           int                   `json:"runs"`
+	FilesRemoved   int                   `json:"files_removed"`
+	BytesReclaimed int64                 `json:"bytes_reclaimed"`
+	ByReason       map[CleanupReason]int `json:"files_removed_by_reason"`
+	LastRun        *CleanupReport        `json:"last_run,omitempty"`
+}
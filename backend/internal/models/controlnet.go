@@ -0,0 +1,83 @@
+package models
+
+import "strings"
+
+// ControlNetUnit represents a single ControlNet conditioning input
+type ControlNetUnit struct {
+	Type          string   `json:"type"`                   // Control type (e.g. canny, depth, openpose)
+	Model         string   `json:"model,omitempty"`        // Specific control model, defaults to the first one matching Type
+	Image         string   `json:"image"`                  // Base64 encoded conditioning image
+	Weight        *float32 `json:"weight,omitempty"`       // Conditioning scale (0.0-2.0), 1.0 when unset, 0 disables the unit
+	GuidanceStart float32  `json:"guidance_start"`         // Fraction of steps before conditioning starts (0.0-1.0)
+	GuidanceEnd   float32  `json:"guidance_end"`           // Fraction of steps after which conditioning stops (0.0-1.0)
+	Preprocessor  string   `json:"preprocessor,omitempty"` // Preprocessor to run on Image, empty or "none" to use it as-is
+}
+
+// ControlNetModel describes a control model reported by the inference backend
+type ControlNetModel struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	BaseModel     string   `json:"base_model,omitempty"`
+	Preprocessors []string `json:"preprocessors,omitempty"`
+}
+
+// MaxControlNetUnits is the maximum number of control units per request
+const MaxControlNetUnits = 4
+
+// ApplyDefaults fills in unset weight and guidance values
+func (u *ControlNetUnit) ApplyDefaults() {
+	if u.Weight == nil {
+		weight := float32(1.0)
+		u.Weight = &weight
+	}
+	if u.GuidanceEnd == 0 {
+		u.GuidanceEnd = 1.0
+	}
+}
+
+// Validate validates the control unit parameters
+func (u *ControlNetUnit) Validate() error {
+	if u.Type == "" || !IsBase64Image(u.Image) {
+		return ErrInvalidControlNet
+	}
+	if u.Weight != nil && (*u.Weight < 0 || *u.Weight > 2) {
+		return ErrInvalidControlNet
+	}
+	if u.GuidanceStart < 0 || u.GuidanceEnd > 1 || u.GuidanceStart >= u.GuidanceEnd {
+		return ErrInvalidControlNet
+	}
+	return nil
+}
+
+// ResolveControlNet finds the control model for a unit in the given catalogue
+func ResolveControlNet(unit *ControlNetUnit, available []ControlNetModel) (*ControlNetModel, error) {
+	for i := range available {
+		model := &available[i]
+		if unit.Model != "" {
+			if model.Name == unit.Model {
+				if !strings.EqualFold(model.Type, unit.Type) {
+					return nil, ErrInvalidControlNet
+				}
+				return model, nil
+			}
+			continue
+		}
+		if strings.EqualFold(model.Type, unit.Type) {
+			return model, nil
+		}
+	}
+	return nil, ErrControlNetNotFound
+}
+
+// SupportsPreprocessor reports whether the model accepts the given preprocessor
+func (m *ControlNetModel) SupportsPreprocessor(name string) bool {
+	if name == "" || name == "none" || len(m.Preprocessors) == 0 {
+		return true
+	}
+	for _, p := range m.Preprocessors {
+		if strings.EqualFold(p, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,21 +5,72 @@ import "errors"
 var (
 	// Validation errors
 	ErrEmptyPrompt       = errors.New("prompt cannot be empty")
+	ErrInvalidPrompt     = errors.New("invalid prompt")
 	ErrInvalidDimensions = errors.New("invalid image dimensions")
 	ErrInvalidSteps      = errors.New("invalid number of steps")
 	ErrInvalidCFGScale   = errors.New("invalid CFG scale")
 	ErrInvalidBatchSize  = errors.New("invalid batch size")
+	ErrInvalidControlNet = errors.New("invalid controlnet unit")
+	ErrInvalidSampler    = errors.New("invalid sampler")
+	ErrInvalidStrength   = errors.New("invalid denoising strength")
+	ErrInvalidWebhook    = errors.New("invalid webhook")
+	ErrInvalidMask       = errors.New("invalid inpainting mask")
+	ErrInvalidOutputFormat = errors.New("invalid output format")
+	ErrInvalidRequest    = errors.New("invalid request body")
 	
 	// Queue errors
 	ErrQueueFull         = errors.New("generation queue is full")
 	ErrGenerationNotFound = errors.New("generation not found")
+	ErrGenerationExists  = errors.New("generation ID is already in use")
 	ErrGenerationTimeout = errors.New("generation timeout")
+	ErrGenerationCancelled = errors.New("generation cancelled")
+
+	// Inference errors, reported by the inference backend for a failed generation
+	ErrInferenceUnavailable = errors.New("inference backend unavailable")
+	ErrInferenceRejected    = errors.New("inference backend rejected the request")
+	ErrInferenceFailed      = errors.New("inference failed")
+	ErrOutOfMemory          = errors.New("inference backend ran out of memory")
+	ErrModelNotLoaded       = errors.New("model not loaded")
+	ErrPipelineUnavailable  = errors.New("pipeline not available for this model")
+	ErrInvalidInput         = errors.New("invalid input image")
 	
 	// Model errors
 	ErrModelNotFound     = errors.New("model not found")
 	ErrModelLoadFailed   = errors.New("failed to load model")
+	ErrControlNetNotFound = errors.New("controlnet model not found")
+	ErrControlNetUnsupported = errors.New("controlnet conditioning is not supported by the inference backend")
 	
+	// Preset errors
+	ErrPresetNotFound    = errors.New("preset not found")
+	ErrStyleNotFound     = errors.New("style not found")
+	ErrPresetExists      = errors.New("preset already exists")
+	ErrStyleExists       = errors.New("style already exists")
+	ErrPresetReadOnly    = errors.New("presets and styles defined in config cannot be modified")
+	ErrInvalidPreset     = errors.New("invalid preset")
+
+	// Webhook errors
+	ErrDeliveryNotFound  = errors.New("webhook delivery not found")
+
+	// History errors
+	ErrHistoryNotFound   = errors.New("history entry not found")
+
 	// Storage errors
 	ErrStorageFull       = errors.New("storage is full")
+	ErrFileTooLarge      = errors.New("file is too large")
 	ErrFileNotFound      = errors.New("file not found")
+	ErrInvalidFileName   = errors.New("invalid file name")
+	ErrStorageUnavailable = errors.New("storage backend unavailable")
+
+	// Library errors
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrCollectionExists   = errors.New("a collection with this name already exists")
+
+	// Export errors
+	ErrExportNotFound = errors.New("export not found")
+	ErrExportNotReady = errors.New("export is not ready")
+	ErrInvalidArchive = errors.New("invalid export archive")
+
+	// Access errors
+	ErrUnauthorized = errors.New("missing or invalid API key")
+	ErrForbidden    = errors.New("access denied")
 )
\ No newline at end of file
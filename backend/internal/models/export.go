@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// ExportStatus is the state of an export job
+type ExportStatus string
+
+const (
+	ExportQueued    ExportStatus = "queued"
+	ExportRunning   ExportStatus = "running"
+	ExportCompleted ExportStatus = "completed"
+	ExportFailed    ExportStatus = "failed"
+)
+
+// ExportManifestVersion is the manifest format written by this server
+const ExportManifestVersion = 1
+
+// ExportFilter selects the history entries to export. Empty fields match
+// every entry.
+type ExportFilter struct {
+	IDs   []string   `json:"ids,omitempty"`
+	From  *time.Time `json:"from,omitempty"` // Created at or after
+	To    *time.Time `json:"to,omitempty"`   // Created before
+	Model string     `json:"model,omitempty"`
+	Tag   string     `json:"tag,omitempty"`
+}
+
+// ExportJob tracks an archive being built in the background
+type ExportJob struct {
+	ID          string       `json:"id"`
+	Status      ExportStatus `json:"status"`
+	Filter      ExportFilter `json:"filter"`
+	Progress    float64      `json:"progress"` // 0-1
+	Entries     int          `json:"entries"`
+	Images      int          `json:"images"`
+	Missing     int          `json:"missing,omitempty"` // Images already removed from storage
+	Size        int64        `json:"size,omitempty"`
+	DownloadURL string       `json:"download_url,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+}
+
+// ExportManifest is stored as manifest.json in an export archive. Images are
+// stored under images/ by their output names.
+type ExportManifest struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Entries    []*HistoryEntry `json:"entries"`
+}
+
+// ImportReport describes an archive restored into history
+type ImportReport struct {
+	Entries int      `json:"entries"`
+	Skipped int      `json:"skipped"` // Entries already in the importing key's history
+	Images  int      `json:"images"`
+	Errors  []string `json:"errors,omitempty"`
+}
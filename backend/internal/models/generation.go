@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,20 +11,37 @@ import (
 // GenerationRequest represents a request to generate an image
 type GenerationRequest struct {
 	ID          string                 `json:"id"`
-	Prompt      string                 `json:"prompt" binding:"required,min=1,max=1000"`
+	Prompt      string                 `json:"prompt"`
 	NegPrompt   string                 `json:"negative_prompt"`
 	Model       string                 `json:"model"`
-	Width       int                    `json:"width" binding:"min=64,max=2048"`
-	Height      int                    `json:"height" binding:"min=64,max=2048"`
-	Steps       int                    `json:"steps" binding:"min=1,max=150"`
-	CFGScale    float32                `json:"cfg_scale" binding:"min=1,max=30"`
+	Width       int                    `json:"width"`
+	Height      int                    `json:"height"`
+	Steps       int                    `json:"steps"`
+	CFGScale    float32                `json:"cfg_scale"`
 	Seed        int64                  `json:"seed"`
-	BatchSize   int                    `json:"batch_size" binding:"min=1,max=10"`
+	BatchSize   int                    `json:"batch_size"`
 	Sampler     string                 `json:"sampler"`
 	// Image-to-image parameters
 	InitImage   string                 `json:"init_image,omitempty"`  // Base64 encoded image
 	Strength    float32                `json:"strength,omitempty"`    // Denoising strength (0.0-1.0)
+	Mask        string                 `json:"mask,omitempty"`        // Base64 encoded inpainting mask, white areas are repainted
+	// ControlNet conditioning inputs
+	ControlNets []ControlNetUnit       `json:"controlnets,omitempty"`
+	// Named preset and style templates applied beneath the explicit fields
+	Preset      string                 `json:"preset,omitempty"`
+	Styles      []string               `json:"styles,omitempty"`
+	// Dynamic prompt expansion ("random" or "combinatorial") and number of prompts to generate
+	PromptMode     string              `json:"prompt_mode,omitempty"`
+	PromptCount    int                 `json:"prompt_count,omitempty"`
+	PromptTemplate string              `json:"prompt_template,omitempty"` // Original prompt before expansion, set by the server
+	// Format the images are stored in, PNG by default, and its quality (1-100)
+	OutputFormat   ImageFormat         `json:"output_format,omitempty"`
+	OutputQuality  int                 `json:"output_quality,omitempty"`
+	// Callback on completion, failure or cancellation
+	Webhook     *WebhookConfig         `json:"webhook,omitempty"`
 	ExtraParams map[string]interface{} `json:"extra_params,omitempty"`
+	// API key ID of the client, set by the server for storage quotas
+	Owner       string                 `json:"-"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
 }
@@ -44,6 +63,54 @@ func NewGenerationRequest() *GenerationRequest {
 	}
 }
 
+// Clone returns a copy of the request that shares no slices, maps or
+// pointers with it
+func (r *GenerationRequest) Clone() *GenerationRequest {
+	clone := *r
+	if r.ControlNets != nil {
+		clone.ControlNets = make([]ControlNetUnit, len(r.ControlNets))
+		for i, unit := range r.ControlNets {
+			if unit.Weight != nil {
+				weight := *unit.Weight
+				unit.Weight = &weight
+			}
+			clone.ControlNets[i] = unit
+		}
+	}
+	if r.Styles != nil {
+		clone.Styles = append([]string(nil), r.Styles...)
+	}
+	if r.Webhook != nil {
+		webhook := *r.Webhook
+		webhook.Events = append([]string(nil), r.Webhook.Events...)
+		clone.Webhook = &webhook
+	}
+	if r.ExtraParams != nil {
+		clone.ExtraParams = copyValue(r.ExtraParams).(map[string]interface{})
+	}
+	return &clone
+}
+
+// copyValue deep-copies the maps and slices of a decoded JSON value
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
 // GenerationStatus represents the current status of a generation
 type GenerationStatus struct {
 	ID          string               `json:"id"`
@@ -53,6 +120,7 @@ type GenerationStatus struct {
 	TotalSteps  int                  `json:"total_steps"`
 	Results     []GenerationResult   `json:"results,omitempty"`
 	Error       string               `json:"error,omitempty"`
+	ErrorCode   ErrorCode            `json:"error_code,omitempty"`
 	StartedAt   *time.Time           `json:"started_at,omitempty"`
 	CompletedAt *time.Time           `json:"completed_at,omitempty"`
 }
@@ -76,6 +144,7 @@ type GenerationResult struct {
 	Width     int               `json:"width"`
 	Height    int               `json:"height"`
 	Metadata  map[string]string `json:"metadata"`
+	Thumbnails []Thumbnail      `json:"thumbnails,omitempty"`
 }
 
 // QueueItem represents an item in the generation queue
@@ -85,25 +154,101 @@ type QueueItem struct {
 	Position int                `json:"position"`
 }
 
-// Validate validates the generation request
+// MaxPromptLength is the maximum length of a prompt or negative prompt
+const MaxPromptLength = 1000
+
+// ValidationOptions carries backend-specific data used during validation
+type ValidationOptions struct {
+	// Samplers is the sampler catalogue to check against, nil uses the built-in catalogue
+	Samplers *SamplerCatalogue
+	// Profile holds the limits of the requested model, nil uses DefaultModelProfile
+	Profile *ModelProfile
+	// OutputFormats are the formats the server can encode, nil allows every known format
+	OutputFormats []ImageFormat
+}
+
+// Validate validates the generation request against the built-in defaults
 func (r *GenerationRequest) Validate() error {
-	if r.Prompt == "" {
-		return ErrEmptyPrompt
+	return r.ValidateWith(ValidationOptions{})
+}
+
+// ValidateWith validates the generation request using the given options.
+// All violated fields are reported as ValidationErrors.
+func (r *GenerationRequest) ValidateWith(opts ValidationOptions) error {
+	profile := opts.Profile
+	if profile == nil {
+		profile = DefaultModelProfile()
 	}
-	if r.Width < 64 || r.Width > 2048 {
-		return ErrInvalidDimensions
+	samplers := opts.Samplers
+	if samplers == nil {
+		samplers = DefaultSamplerCatalogue()
+	}
+
+	var errs ValidationErrors
+
+	if strings.TrimSpace(r.Prompt) == "" {
+		errs.add("prompt", ErrEmptyPrompt, "cannot be empty")
+	} else if len(r.Prompt) > MaxPromptLength {
+		errs.add("prompt", ErrInvalidPrompt, "must be at most %d characters", MaxPromptLength)
 	}
-	if r.Height < 64 || r.Height > 2048 {
-		return ErrInvalidDimensions
+	if len(r.NegPrompt) > MaxPromptLength {
+		errs.add("negative_prompt", ErrInvalidPrompt, "must be at most %d characters", MaxPromptLength)
 	}
-	if r.Steps < 1 || r.Steps > 150 {
-		return ErrInvalidSteps
+	errs.validateDimension("width", r.Width, profile)
+	errs.validateDimension("height", r.Height, profile)
+	if r.Steps < 1 || r.Steps > profile.MaxSteps {
+		errs.add("steps", ErrInvalidSteps, "must be between 1 and %d", profile.MaxSteps)
 	}
 	if r.CFGScale < 1 || r.CFGScale > 30 {
-		return ErrInvalidCFGScale
+		errs.add("cfg_scale", ErrInvalidCFGScale, "must be between 1 and 30")
+	}
+	if r.BatchSize < 1 || r.BatchSize > profile.MaxBatchSize {
+		errs.add("batch_size", ErrInvalidBatchSize, "must be between 1 and %d", profile.MaxBatchSize)
+	}
+	if _, err := samplers.Resolve(r.Sampler); err != nil {
+		errs.add("sampler", err, "%s", err.Error())
+	}
+	if r.PromptMode != "" && r.PromptMode != "random" && r.PromptMode != "combinatorial" {
+		errs.add("prompt_mode", ErrInvalidPrompt, "must be \"random\" or \"combinatorial\"")
+	}
+	if r.PromptCount < 0 {
+		errs.add("prompt_count", ErrInvalidPrompt, "cannot be negative")
+	}
+	if r.OutputFormat != "" && !hasFormat(opts.OutputFormats, r.OutputFormat) {
+		errs.add("output_format", ErrInvalidOutputFormat, "must be one of %s", formatList(opts.OutputFormats))
+	}
+	if r.OutputQuality < 0 || r.OutputQuality > 100 {
+		errs.add("output_quality", ErrInvalidOutputFormat, "must be between 1 and 100")
+	}
+	if r.Webhook != nil {
+		if err := r.Webhook.Validate(); err != nil {
+			errs.add("webhook", err, "requires an http(s) url and events from completed, failed, cancelled")
+		}
 	}
-	if r.BatchSize < 1 || r.BatchSize > 10 {
-		return ErrInvalidBatchSize
+	// Images must be inline data, the inference service never reads paths
+	if r.InitImage != "" && !IsBase64Image(r.InitImage) {
+		errs.add("init_image", ErrInvalidInput, "must be a base64 encoded image")
+	}
+	if r.Mask != "" && !IsBase64Image(r.Mask) {
+		errs.add("mask", ErrInvalidMask, "must be a base64 encoded image")
+	}
+	if r.InitImage != "" && (r.Strength < 0 || r.Strength > 1) {
+		errs.add("strength", ErrInvalidStrength, "must be between 0 and 1")
+	}
+	if r.Mask != "" && r.InitImage == "" {
+		errs.add("mask", ErrInvalidMask, "requires init_image")
+	}
+	if len(r.ControlNets) > MaxControlNetUnits {
+		errs.add("controlnets", ErrInvalidControlNet, "at most %d units are allowed", MaxControlNetUnits)
+	}
+	for i := range r.ControlNets {
+		if err := r.ControlNets[i].Validate(); err != nil {
+			errs.add(fmt.Sprintf("controlnets[%d]", i), err, "requires type and a base64 image, weight 0-2 and 0 <= guidance_start < guidance_end <= 1")
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
 	}
 	return nil
-}
\ No newline at end of file
+}
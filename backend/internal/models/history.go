@@ -0,0 +1,52 @@
+package models
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// HistoryEntry records a generation that reached a terminal state
+type HistoryEntry struct {
+	ID          string               `json:"id"`
+	Owner       string               `json:"owner,omitempty"` // ID of the API key that submitted the generation
+	Request     *GenerationRequest   `json:"request"`
+	Status      GenerationStatusType `json:"status"`
+	Results     []GenerationResult   `json:"results,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	StartedAt   *time.Time           `json:"started_at,omitempty"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+	Pinned      bool                 `json:"pinned"`           // Pinned outputs are never removed by the storage janitor
+	Favourite   bool                 `json:"favourite"`        // Favourite outputs are kept like pinned ones
+	Rating      int                  `json:"rating,omitempty"` // 1-5 stars, 0 when unrated
+	Tags        []string             `json:"tags,omitempty"`
+}
+
+// Protected reports whether the storage janitor must keep the entry's outputs
+func (e *HistoryEntry) Protected() bool {
+	return e.Pinned || e.Favourite
+}
+
+// HasTag reports whether the entry carries tag
+func (e *HistoryEntry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// OutputFiles returns the base names of the entry's output images and their thumbnails
+func (e *HistoryEntry) OutputFiles() []string {
+	files := make([]string, 0, len(e.Results))
+	for _, result := range e.Results {
+		if result.ImagePath != "" {
+			files = append(files, filepath.Base(result.ImagePath))
+		}
+		for _, thumbnail := range result.Thumbnails {
+			files = append(files, filepath.Base(thumbnail.ImagePath))
+		}
+	}
+	return files
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/base64"
+	"io"
+	"strings"
+)
+
+// ImageFormat is an encoding outputs can be stored and served in
+type ImageFormat string
+
+const (
+	FormatPNG  ImageFormat = "png"
+	FormatJPEG ImageFormat = "jpeg"
+	FormatWebP ImageFormat = "webp"
+	FormatAVIF ImageFormat = "avif"
+)
+
+// ImageFormats lists every known output format
+var ImageFormats = []ImageFormat{FormatPNG, FormatJPEG, FormatWebP, FormatAVIF}
+
+// ParseImageFormat returns the format named s, accepting "jpg" for JPEG
+func ParseImageFormat(s string) (ImageFormat, bool) {
+	if s == "jpg" {
+		return FormatJPEG, true
+	}
+	for _, format := range ImageFormats {
+		if string(format) == s {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// UnmarshalText normalises the format name, unknown names are kept for validation
+func (f *ImageFormat) UnmarshalText(text []byte) error {
+	name := strings.ToLower(string(text))
+	if format, ok := ParseImageFormat(name); ok {
+		*f = format
+	} else {
+		*f = ImageFormat(name)
+	}
+	return nil
+}
+
+// hasFormat reports whether format is in formats, nil means every known format
+func hasFormat(formats []ImageFormat, format ImageFormat) bool {
+	if formats == nil {
+		formats = ImageFormats
+	}
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// formatList joins formats for error messages, nil means every known format
+func formatList(formats []ImageFormat) string {
+	if formats == nil {
+		formats = ImageFormats
+	}
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Thumbnail is a reduced copy of a generated image
+type Thumbnail struct {
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	ImagePath string `json:"image_path"`
+	ImageURL  string `json:"image_url"`
+}
+
+// IsBase64Image reports whether s is base64 image data, bare or as a
+// data:image/ URL. Request images must be inline data, never file paths.
+func IsBase64Image(s string) bool {
+	if strings.HasPrefix(s, "data:") {
+		header, data, found := strings.Cut(s, ",")
+		if !found || !strings.HasPrefix(header, "data:image/") || !strings.HasSuffix(header, ";base64") {
+			return false
+		}
+		s = data
+	}
+	if s == "" {
+		return false
+	}
+	_, err := io.Copy(io.Discard, base64.NewDecoder(base64.StdEncoding, strings.NewReader(s)))
+	return err == nil
+}
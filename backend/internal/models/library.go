@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Library limits
+const (
+	MaxRating         = 5
+	MaxTagLength      = 64
+	MaxTagsPerEntry   = 50
+	MaxCollectionName = 100
+)
+
+// Collection is a user-defined group of history entries
+type Collection struct {
+	ID          string    `json:"id"`
+	Owner       string    `json:"owner,omitempty"` // ID of the API key that created the collection
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	EntryIDs    []string  `json:"entry_ids"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CollectionRequest is the editable part of a collection, sent to create or update one
+type CollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// RatingRequest sets the rating of a history entry
+type RatingRequest struct {
+	Rating int `json:"rating"`
+}
+
+// TagsRequest replaces the tags of a history entry
+type TagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// Validate checks the user-editable fields of a collection
+func (c *Collection) Validate() error {
+	var errs ValidationErrors
+	name := strings.TrimSpace(c.Name)
+	if name == "" || len(name) > MaxCollectionName {
+		errs.add("name", ErrInvalidRequest, "must be 1 to %d characters", MaxCollectionName)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// TagCount is a tag and the number of history entries carrying it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTag lowercases and trims a tag
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalises, deduplicates and sorts tags, and checks their
+// length and number
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || len(tag) > MaxTagLength {
+			return nil, ValidationErrors{{Field: "tags", Message: fmt.Sprintf("tags must be 1 to %d characters", MaxTagLength), Err: ErrInvalidRequest}}
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > MaxTagsPerEntry {
+		return nil, ValidationErrors{{Field: "tags", Message: fmt.Sprintf("at most %d tags per entry", MaxTagsPerEntry), Err: ErrInvalidRequest}}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// ValidateRating checks a star rating, 0 clears it
+func ValidateRating(rating int) error {
+	if rating < 0 || rating > MaxRating {
+		return ValidationErrors{{Field: "rating", Message: fmt.Sprintf("must be between 1 and %d, or 0 to clear", MaxRating), Err: ErrInvalidRequest}}
+	}
+	return nil
+}
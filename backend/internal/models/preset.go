@@ -0,0 +1,91 @@
+package models
+
+import "strings"
+
+// Preset is a named bundle of generation parameters.
+// Nil fields are left unchanged when the preset is applied.
+type Preset struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Model       *string  `json:"model,omitempty"`
+	NegPrompt   *string  `json:"negative_prompt,omitempty"`
+	Width       *int     `json:"width,omitempty"`
+	Height      *int     `json:"height,omitempty"`
+	Steps       *int     `json:"steps,omitempty"`
+	CFGScale    *float32 `json:"cfg_scale,omitempty"`
+	Sampler     *string  `json:"sampler,omitempty"`
+	BatchSize   *int     `json:"batch_size,omitempty"`
+	Strength    *float32 `json:"strength,omitempty"`
+	Styles      []string `json:"styles,omitempty"`
+	ReadOnly    bool     `json:"read_only"`
+}
+
+// StyleTemplate is a named prompt template.
+// Prompt may contain a {prompt} placeholder; without one the template is appended.
+type StyleTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Prompt      string `json:"prompt"`
+	NegPrompt   string `json:"negative_prompt,omitempty"`
+	ReadOnly    bool   `json:"read_only"`
+}
+
+// StylePlaceholder is replaced by the user prompt when a style is applied
+const StylePlaceholder = "{prompt}"
+
+// ApplyTo copies the preset's parameters onto the request
+func (p *Preset) ApplyTo(req *GenerationRequest) {
+	if p.Model != nil {
+		req.Model = *p.Model
+	}
+	if p.NegPrompt != nil {
+		req.NegPrompt = *p.NegPrompt
+	}
+	if p.Width != nil {
+		req.Width = *p.Width
+	}
+	if p.Height != nil {
+		req.Height = *p.Height
+	}
+	if p.Steps != nil {
+		req.Steps = *p.Steps
+	}
+	if p.CFGScale != nil {
+		req.CFGScale = *p.CFGScale
+	}
+	if p.Sampler != nil {
+		req.Sampler = *p.Sampler
+	}
+	if p.BatchSize != nil {
+		req.BatchSize = *p.BatchSize
+	}
+	if p.Strength != nil {
+		req.Strength = *p.Strength
+	}
+	if len(req.Styles) == 0 && len(p.Styles) > 0 {
+		req.Styles = append([]string(nil), p.Styles...)
+	}
+}
+
+// ApplyTo wraps the request prompt in the template and appends the negative prompt
+func (s *StyleTemplate) ApplyTo(req *GenerationRequest) {
+	if s.Prompt != "" {
+		if strings.Contains(s.Prompt, StylePlaceholder) {
+			req.Prompt = strings.ReplaceAll(s.Prompt, StylePlaceholder, req.Prompt)
+		} else {
+			req.Prompt = joinPrompt(req.Prompt, s.Prompt)
+		}
+	}
+	if s.NegPrompt != "" {
+		req.NegPrompt = joinPrompt(req.NegPrompt, s.NegPrompt)
+	}
+}
+
+// joinPrompt joins two comma-separated prompt fragments
+func joinPrompt(base, extra string) string {
+	base = strings.TrimSpace(base)
+	if base == "" {
+		return strings.TrimSpace(extra)
+	}
+	return base + ", " + strings.TrimSpace(extra)
+}
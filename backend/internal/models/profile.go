@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImageSize represents an image resolution
+type ImageSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// String formats the size as WIDTHxHEIGHT
+func (s ImageSize) String() string {
+	return fmt.Sprintf("%dx%d", s.Width, s.Height)
+}
+
+// ParseImageSize parses a WIDTHxHEIGHT string
+func ParseImageSize(value string) (ImageSize, error) {
+	parts := strings.SplitN(strings.ToLower(strings.TrimSpace(value)), "x", 2)
+	if len(parts) != 2 {
+		return ImageSize{}, fmt.Errorf("invalid image size %q", value)
+	}
+	width, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return ImageSize{}, fmt.Errorf("invalid image size %q", value)
+	}
+	height, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ImageSize{}, fmt.Errorf("invalid image size %q", value)
+	}
+	return ImageSize{Width: width, Height: height}, nil
+}
+
+// ModelProfile describes the parameter limits of a model family
+type ModelProfile struct {
+	Name              string      `json:"name"`
+	MinSize           int         `json:"min_size"`
+	MaxSize           int         `json:"max_size"`
+	DimensionMultiple int         `json:"dimension_multiple"`
+	RecommendedSizes  []ImageSize `json:"recommended_sizes,omitempty"`
+	MaxSteps          int         `json:"max_steps"`
+	MaxBatchSize      int         `json:"max_batch_size"`
+}
+
+// DefaultModelProfile returns the limits used when no model profile applies
+func DefaultModelProfile() *ModelProfile {
+	return &ModelProfile{
+		Name:              "default",
+		MinSize:           64,
+		MaxSize:           2048,
+		DimensionMultiple: 8,
+		MaxSteps:          150,
+		MaxBatchSize:      10,
+	}
+}
+
+// recommendedSizes formats the recommended sizes for error messages
+func (p *ModelProfile) recommendedSizes() string {
+	if len(p.RecommendedSizes) == 0 {
+		return ""
+	}
+	sizes := make([]string, len(p.RecommendedSizes))
+	for i, size := range p.RecommendedSizes {
+		sizes[i] = size.String()
+	}
+	return fmt.Sprintf(" (recommended for %s: %s)", p.Name, strings.Join(sizes, ", "))
+}
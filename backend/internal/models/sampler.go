@@ -0,0 +1,167 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SamplerInfo describes a sampler offered by the inference backend
+type SamplerInfo struct {
+	Name      string   `json:"name"`
+	Aliases   []string `json:"aliases,omitempty"`
+	Scheduler string   `json:"scheduler,omitempty"` // Underlying scheduler implementation
+	LCM       bool     `json:"lcm,omitempty"`       // Only usable with LCM models or LoRAs
+}
+
+// SamplerCatalogue is the set of samplers and schedulers the inference backend accepts
+type SamplerCatalogue struct {
+	Samplers   []SamplerInfo `json:"samplers"`
+	Schedulers []string      `json:"schedulers"`
+}
+
+// SamplerError is returned when a request names a sampler that is not in the catalogue
+type SamplerError struct {
+	Name       string
+	Suggestion string
+}
+
+func (e *SamplerError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown sampler %q, did you mean %q?", e.Name, e.Suggestion)
+	}
+	return fmt.Sprintf("unknown sampler %q", e.Name)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidSampler)
+func (e *SamplerError) Unwrap() error {
+	return ErrInvalidSampler
+}
+
+// samplerAliases maps common alternative spellings to canonical sampler names
+var samplerAliases = map[string][]string{
+	"Euler a":             {"euler_ancestral", "k_euler_a", "k_euler_ancestral"},
+	"Euler":               {"k_euler"},
+	"LMS":                 {"k_lms"},
+	"LMS Karras":          {"k_lms_ka"},
+	"DPM++ 2M Karras":     {"dpmpp_2m_ka", "k_dpmpp_2m_ka"},
+	"DPM++ 2M SDE Karras": {"dpmpp_2m_sde_ka"},
+	"DPM++ SDE Karras":    {"dpmpp_sde_ka"},
+	"UniPC":               {"unipc_multistep"},
+	"PNDM":                {"plms"},
+}
+
+// DefaultSamplerCatalogue returns the built-in catalogue used when the backend cannot be queried
+func DefaultSamplerCatalogue() *SamplerCatalogue {
+	catalogue := &SamplerCatalogue{
+		Samplers: []SamplerInfo{
+			{Name: "DPM++ 2M Karras", Scheduler: "DPMSolverMultistepScheduler"},
+			{Name: "DPM++ 2M SDE Karras", Scheduler: "DPMSolverMultistepScheduler"},
+			{Name: "DPM++ SDE Karras", Scheduler: "DPMSolverMultistepScheduler"},
+			{Name: "Euler a", Scheduler: "EulerAncestralDiscreteScheduler"},
+			{Name: "Euler", Scheduler: "EulerDiscreteScheduler"},
+			{Name: "LMS", Scheduler: "LMSDiscreteScheduler"},
+			{Name: "LMS Karras", Scheduler: "LMSDiscreteScheduler"},
+			{Name: "DDIM", Scheduler: "DDIMScheduler"},
+			{Name: "PNDM", Scheduler: "PNDMScheduler"},
+			{Name: "UniPC", Scheduler: "UniPCMultistepScheduler"},
+			{Name: "LCM", Scheduler: "LCMScheduler", LCM: true},
+		},
+	}
+	catalogue.Finalize()
+	return catalogue
+}
+
+// Finalize fills in well-known aliases and the scheduler list
+func (c *SamplerCatalogue) Finalize() {
+	seen := make(map[string]bool)
+	for _, scheduler := range c.Schedulers {
+		seen[scheduler] = true
+	}
+
+	for i := range c.Samplers {
+		sampler := &c.Samplers[i]
+		for _, alias := range samplerAliases[sampler.Name] {
+			if !containsFold(sampler.Aliases, alias) {
+				sampler.Aliases = append(sampler.Aliases, alias)
+			}
+		}
+		if sampler.Scheduler != "" && !seen[sampler.Scheduler] {
+			seen[sampler.Scheduler] = true
+			c.Schedulers = append(c.Schedulers, sampler.Scheduler)
+		}
+	}
+}
+
+// Resolve returns the canonical sampler name for a name or alias
+func (c *SamplerCatalogue) Resolve(name string) (string, error) {
+	key := normalizeSamplerName(name)
+	for _, sampler := range c.Samplers {
+		if normalizeSamplerName(sampler.Name) == key {
+			return sampler.Name, nil
+		}
+		for _, alias := range sampler.Aliases {
+			if normalizeSamplerName(alias) == key {
+				return sampler.Name, nil
+			}
+		}
+	}
+
+	return "", &SamplerError{Name: name, Suggestion: c.suggest(key)}
+}
+
+// suggest returns the canonical name closest to the normalized key
+func (c *SamplerCatalogue) suggest(key string) string {
+	best := ""
+	bestDistance := len(key)/2 + 2
+	for _, sampler := range c.Samplers {
+		candidates := append([]string{sampler.Name}, sampler.Aliases...)
+		for _, candidate := range candidates {
+			distance := levenshtein(key, normalizeSamplerName(candidate))
+			if distance < bestDistance {
+				best = sampler.Name
+				bestDistance = distance
+			}
+		}
+	}
+	return best
+}
+
+// normalizeSamplerName folds case and punctuation so "DPM++ 2M Karras" matches "dpmpp_2m_karras"
+func normalizeSamplerName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, "++", "pp")
+	name = strings.NewReplacer(" ", "_", "-", "_", ".", "_").Replace(name)
+	return strings.Trim(name, "_")
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a single invalid request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Err     error  `json:"-"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap returns the underlying sentinel error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors collects every field error found while validating a request
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Error()
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Unwrap allows errors.Is to match any of the field errors
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fieldErr := range e {
+		errs[i] = fieldErr
+	}
+	return errs
+}
+
+// add records a field error
+func (e *ValidationErrors) add(field string, err error, format string, args ...interface{}) {
+	*e = append(*e, &FieldError{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+		Err:     err,
+	})
+}
+
+// validateDimension checks a width or height against the profile
+func (e *ValidationErrors) validateDimension(field string, value int, profile *ModelProfile) {
+	switch {
+	case value < profile.MinSize || value > profile.MaxSize:
+		e.add(field, ErrInvalidDimensions, "must be between %d and %d%s", profile.MinSize, profile.MaxSize, profile.recommendedSizes())
+	case profile.DimensionMultiple > 1 && value%profile.DimensionMultiple != 0:
+		e.add(field, ErrInvalidDimensions, "must be a multiple of %d%s", profile.DimensionMultiple, profile.recommendedSizes())
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// Webhook events, named after the terminal status that triggers them
+const (
+	WebhookEventCompleted = "completed"
+	WebhookEventFailed    = "failed"
+	WebhookEventCancelled = "cancelled"
+)
+
+// WebhookConfig requests a callback when a generation finishes
+type WebhookConfig struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"` // HMAC-SHA256 signing key
+	Events []string `json:"events,omitempty"` // Events to deliver, empty for all
+}
+
+// MarshalJSON omits the secret so it is never echoed back by the API
+func (w WebhookConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events,omitempty"`
+	}{w.URL, w.Events})
+}
+
+// Validate validates the webhook configuration
+func (w *WebhookConfig) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhook
+	}
+	for _, event := range w.Events {
+		if event != WebhookEventCompleted && event != WebhookEventFailed && event != WebhookEventCancelled {
+			return ErrInvalidWebhook
+		}
+	}
+	return nil
+}
+
+// Wants reports whether the webhook subscribes to the event
+func (w *WebhookConfig) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliveryDelivered WebhookDeliveryStatus = "delivered"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery records an attempt to deliver a webhook event
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	JobID          string                `json:"job_id"`
+	Event          string                `json:"event"`
+	URL            string                `json:"url"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhook URLs
+type WebhookPayload struct {
+	Event     string            `json:"event"`
+	JobID     string            `json:"job_id"`
+	Status    *GenerationStatus `json:"status"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// IsTerminal reports whether the status is final
+func (s GenerationStatusType) IsTerminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
@@ -0,0 +1,423 @@
+package presets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Manager interface for preset and style template operations
+type Manager interface {
+	ListPresets() []*models.Preset
+	GetPreset(name string) (*models.Preset, error)
+	CreatePreset(preset *models.Preset) error
+	SavePreset(preset *models.Preset) error
+	DeletePreset(name string) error
+	ListStyles() []*models.StyleTemplate
+	GetStyle(name string) (*models.StyleTemplate, error)
+	CreateStyle(style *models.StyleTemplate) error
+	SaveStyle(style *models.StyleTemplate) error
+	DeleteStyle(name string) error
+	ApplyStyles(req *models.GenerationRequest) error
+}
+
+// PresetManager implements the Manager interface.
+// Presets from config are read-only; presets created through the API are
+// persisted to a JSON file.
+type PresetManager struct {
+	presets map[string]*models.Preset
+	styles  map[string]*models.StyleTemplate
+	file    string
+	mu      sync.RWMutex
+	logger  *logrus.Logger
+}
+
+// persistedState is the on-disk format of user-created presets and styles
+type persistedState struct {
+	Presets []*models.Preset        `json:"presets"`
+	Styles  []*models.StyleTemplate `json:"styles"`
+}
+
+// NewManager creates a new preset manager
+func NewManager(cfg config.PresetsConfig, storageConfig config.StorageConfig, logger *logrus.Logger) (Manager, error) {
+	file := cfg.File
+	if file == "" {
+		file = filepath.Join(storageConfig.DataDir, "presets.json")
+	}
+
+	m := &PresetManager{
+		presets: make(map[string]*models.Preset),
+		styles:  make(map[string]*models.StyleTemplate),
+		file:    file,
+		logger:  logger,
+	}
+
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	// Config definitions take precedence over persisted ones
+	for _, p := range cfg.Presets {
+		preset := presetFromConfig(p)
+		if err := validatePreset(preset); err != nil {
+			return nil, fmt.Errorf("invalid preset %q in config: %w", p.Name, err)
+		}
+		m.presets[preset.Name] = preset
+	}
+	for _, s := range cfg.Styles {
+		style := &models.StyleTemplate{
+			Name:        s.Name,
+			Description: s.Description,
+			Prompt:      s.Prompt,
+			NegPrompt:   s.NegativePrompt,
+			ReadOnly:    true,
+		}
+		if err := validateStyle(style); err != nil {
+			return nil, fmt.Errorf("invalid style %q in config: %w", s.Name, err)
+		}
+		m.styles[style.Name] = style
+	}
+
+	// Config presets may reference styles from config or the presets file
+	for _, p := range cfg.Presets {
+		for _, style := range p.Styles {
+			if _, exists := m.styles[style]; !exists {
+				return nil, fmt.Errorf("invalid preset %q in config: %w: %s", p.Name, models.ErrStyleNotFound, style)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// ListPresets returns all presets sorted by name
+func (m *PresetManager) ListPresets() []*models.Preset {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	presets := make([]*models.Preset, 0, len(m.presets))
+	for _, preset := range m.presets {
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets
+}
+
+// GetPreset returns a preset by name
+func (m *PresetManager) GetPreset(name string) (*models.Preset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	preset, exists := m.presets[name]
+	if !exists {
+		return nil, models.ErrPresetNotFound
+	}
+	return preset, nil
+}
+
+// CreatePreset creates a user-defined preset, failing if the name is taken
+func (m *PresetManager) CreatePreset(preset *models.Preset) error {
+	return m.putPreset(preset, false)
+}
+
+// SavePreset creates or replaces a user-defined preset
+func (m *PresetManager) SavePreset(preset *models.Preset) error {
+	return m.putPreset(preset, true)
+}
+
+// putPreset stores a user-defined preset. The check and the insert happen
+// under the write lock, and the previous entry is restored if saving fails.
+func (m *PresetManager) putPreset(preset *models.Preset, replace bool) error {
+	if err := validatePreset(preset); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.presets[preset.Name]
+	if exists && !replace {
+		return models.ErrPresetExists
+	}
+	if exists && existing.ReadOnly {
+		return models.ErrPresetReadOnly
+	}
+	for _, style := range preset.Styles {
+		if _, exists := m.styles[style]; !exists {
+			return fmt.Errorf("%w: %s", models.ErrStyleNotFound, style)
+		}
+	}
+
+	preset.ReadOnly = false
+	m.presets[preset.Name] = preset
+	if err := m.save(); err != nil {
+		if exists {
+			m.presets[preset.Name] = existing
+		} else {
+			delete(m.presets, preset.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeletePreset removes a user-defined preset
+func (m *PresetManager) DeletePreset(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	preset, exists := m.presets[name]
+	if !exists {
+		return models.ErrPresetNotFound
+	}
+	if preset.ReadOnly {
+		return models.ErrPresetReadOnly
+	}
+
+	delete(m.presets, name)
+	if err := m.save(); err != nil {
+		m.presets[name] = preset
+		return err
+	}
+	return nil
+}
+
+// ListStyles returns all style templates sorted by name
+func (m *PresetManager) ListStyles() []*models.StyleTemplate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	styles := make([]*models.StyleTemplate, 0, len(m.styles))
+	for _, style := range m.styles {
+		styles = append(styles, style)
+	}
+	sort.Slice(styles, func(i, j int) bool { return styles[i].Name < styles[j].Name })
+	return styles
+}
+
+// GetStyle returns a style template by name
+func (m *PresetManager) GetStyle(name string) (*models.StyleTemplate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	style, exists := m.styles[name]
+	if !exists {
+		return nil, models.ErrStyleNotFound
+	}
+	return style, nil
+}
+
+// CreateStyle creates a user-defined style template, failing if the name is taken
+func (m *PresetManager) CreateStyle(style *models.StyleTemplate) error {
+	return m.putStyle(style, false)
+}
+
+// SaveStyle creates or replaces a user-defined style template
+func (m *PresetManager) SaveStyle(style *models.StyleTemplate) error {
+	return m.putStyle(style, true)
+}
+
+// putStyle stores a user-defined style template, like putPreset
+func (m *PresetManager) putStyle(style *models.StyleTemplate, replace bool) error {
+	if err := validateStyle(style); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.styles[style.Name]
+	if exists && !replace {
+		return models.ErrStyleExists
+	}
+	if exists && existing.ReadOnly {
+		return models.ErrPresetReadOnly
+	}
+
+	style.ReadOnly = false
+	m.styles[style.Name] = style
+	if err := m.save(); err != nil {
+		if exists {
+			m.styles[style.Name] = existing
+		} else {
+			delete(m.styles, style.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteStyle removes a user-defined style template
+func (m *PresetManager) DeleteStyle(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	style, exists := m.styles[name]
+	if !exists {
+		return models.ErrStyleNotFound
+	}
+	if style.ReadOnly {
+		return models.ErrPresetReadOnly
+	}
+
+	delete(m.styles, name)
+	if err := m.save(); err != nil {
+		m.styles[name] = style
+		return err
+	}
+	return nil
+}
+
+// ApplyStyles applies the request's style templates to its prompts in order
+func (m *PresetManager) ApplyStyles(req *models.GenerationRequest) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, name := range req.Styles {
+		style, exists := m.styles[name]
+		if !exists {
+			return fmt.Errorf("%w: %s", models.ErrStyleNotFound, name)
+		}
+		style.ApplyTo(req)
+	}
+	return nil
+}
+
+// load reads user-defined presets and styles from disk
+func (m *PresetManager) load() error {
+	data, err := os.ReadFile(m.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read presets file: %w", err)
+	}
+
+	var state persistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse presets file: %w", err)
+	}
+
+	for _, preset := range state.Presets {
+		preset.ReadOnly = false
+		m.presets[preset.Name] = preset
+	}
+	for _, style := range state.Styles {
+		style.ReadOnly = false
+		m.styles[style.Name] = style
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"presets": len(state.Presets),
+		"styles":  len(state.Styles),
+	}).Info("Loaded user presets")
+
+	return nil
+}
+
+// save writes user-defined presets and styles to disk, caller must hold the lock
+func (m *PresetManager) save() error {
+	state := persistedState{
+		Presets: make([]*models.Preset, 0),
+		Styles:  make([]*models.StyleTemplate, 0),
+	}
+	for _, preset := range m.presets {
+		if !preset.ReadOnly {
+			state.Presets = append(state.Presets, preset)
+		}
+	}
+	for _, style := range m.styles {
+		if !style.ReadOnly {
+			state.Styles = append(state.Styles, style)
+		}
+	}
+	sort.Slice(state.Presets, func(i, j int) bool { return state.Presets[i].Name < state.Presets[j].Name })
+	sort.Slice(state.Styles, func(i, j int) bool { return state.Styles[i].Name < state.Styles[j].Name })
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.file), 0755); err != nil {
+		return fmt.Errorf("failed to create presets directory: %w", err)
+	}
+
+	// Write atomically so a crash never leaves a truncated file
+	tmp := m.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to save presets: %w", err)
+	}
+	return os.Rename(tmp, m.file)
+}
+
+// presetFromConfig converts a config preset, treating zero values as unset
+func presetFromConfig(p config.PresetConfig) *models.Preset {
+	preset := &models.Preset{
+		Name:        p.Name,
+		Description: p.Description,
+		Styles:      p.Styles,
+		ReadOnly:    true,
+	}
+	if p.Model != "" {
+		preset.Model = &p.Model
+	}
+	if p.NegativePrompt != "" {
+		preset.NegPrompt = &p.NegativePrompt
+	}
+	if p.Width > 0 {
+		preset.Width = &p.Width
+	}
+	if p.Height > 0 {
+		preset.Height = &p.Height
+	}
+	if p.Steps > 0 {
+		preset.Steps = &p.Steps
+	}
+	if p.CFGScale > 0 {
+		preset.CFGScale = &p.CFGScale
+	}
+	if p.Sampler != "" {
+		preset.Sampler = &p.Sampler
+	}
+	if p.BatchSize > 0 {
+		preset.BatchSize = &p.BatchSize
+	}
+	if p.Strength > 0 {
+		preset.Strength = &p.Strength
+	}
+	return preset
+}
+
+// validName reports whether a preset or style name is usable in URLs
+func validName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	return !strings.ContainsAny(name, "/\\ ?#")
+}
+
+func validatePreset(preset *models.Preset) error {
+	if !validName(preset.Name) {
+		return fmt.Errorf("%w: name must be 1-64 characters without spaces or slashes", models.ErrInvalidPreset)
+	}
+	return nil
+}
+
+func validateStyle(style *models.StyleTemplate) error {
+	if !validName(style.Name) {
+		return fmt.Errorf("%w: name must be 1-64 characters without spaces or slashes", models.ErrInvalidPreset)
+	}
+	if style.Prompt == "" && style.NegPrompt == "" {
+		return fmt.Errorf("%w: style must define a prompt or negative prompt", models.ErrInvalidPreset)
+	}
+	return nil
+}
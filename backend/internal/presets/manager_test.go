@@ -0,0 +1,104 @@
+package presets
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// newManager creates a manager persisting to a temp directory
+func newManager(t *testing.T, cfg config.PresetsConfig) (*PresetManager, error) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	m, err := NewManager(cfg, config.StorageConfig{DataDir: t.TempDir()}, logger)
+	if err != nil {
+		return nil, err
+	}
+	return m.(*PresetManager), nil
+}
+
+func TestCreatePresetRejectsExistingName(t *testing.T) {
+	m, err := newManager(t, config.PresetsConfig{})
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	if err := m.CreatePreset(&models.Preset{Name: "portrait"}); err != nil {
+		t.Fatalf("CreatePreset: %v", err)
+	}
+	if err := m.CreatePreset(&models.Preset{Name: "portrait"}); !errors.Is(err, models.ErrPresetExists) {
+		t.Errorf("second CreatePreset error = %v, want ErrPresetExists", err)
+	}
+
+	style := &models.StyleTemplate{Name: "film", Prompt: "{prompt}, film grain"}
+	if err := m.CreateStyle(style); err != nil {
+		t.Fatalf("CreateStyle: %v", err)
+	}
+	if err := m.CreateStyle(style); !errors.Is(err, models.ErrStyleExists) {
+		t.Errorf("second CreateStyle error = %v, want ErrStyleExists", err)
+	}
+}
+
+func TestFailedSaveRestoresPreviousEntries(t *testing.T) {
+	m, err := newManager(t, config.PresetsConfig{})
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	steps := 20
+	if err := m.SavePreset(&models.Preset{Name: "portrait", Steps: &steps}); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+
+	// A regular file in place of the directory makes every save fail
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.file = filepath.Join(blocker, "presets.json")
+
+	otherSteps := 40
+	if err := m.SavePreset(&models.Preset{Name: "portrait", Steps: &otherSteps}); err == nil {
+		t.Fatal("SavePreset succeeded, want a save error")
+	}
+	if preset, _ := m.GetPreset("portrait"); *preset.Steps != steps {
+		t.Errorf("steps after failed update = %d, want %d", *preset.Steps, steps)
+	}
+
+	if err := m.CreatePreset(&models.Preset{Name: "landscape"}); err == nil {
+		t.Fatal("CreatePreset succeeded, want a save error")
+	}
+	if _, err := m.GetPreset("landscape"); !errors.Is(err, models.ErrPresetNotFound) {
+		t.Errorf("GetPreset after failed create error = %v, want ErrPresetNotFound", err)
+	}
+
+	if err := m.DeletePreset("portrait"); err == nil {
+		t.Fatal("DeletePreset succeeded, want a save error")
+	}
+	if _, err := m.GetPreset("portrait"); err != nil {
+		t.Errorf("GetPreset after failed delete: %v", err)
+	}
+}
+
+func TestConfigPresetStylesMustExist(t *testing.T) {
+	cfg := config.PresetsConfig{
+		Presets: []config.PresetConfig{{Name: "portrait", Styles: []string{"flim"}}},
+		Styles:  []config.StyleConfig{{Name: "film", Prompt: "{prompt}, film grain"}},
+	}
+	if _, err := newManager(t, cfg); !errors.Is(err, models.ErrStyleNotFound) {
+		t.Errorf("NewManager error = %v, want ErrStyleNotFound", err)
+	}
+
+	cfg.Presets[0].Styles = []string{"film"}
+	if _, err := newManager(t, cfg); err != nil {
+		t.Errorf("NewManager: %v", err)
+	}
+}
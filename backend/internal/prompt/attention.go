@@ -0,0 +1,142 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Segment is a run of prompt text with its attention weight
+type Segment struct {
+	Text   string  `json:"text"`
+	Weight float64 `json:"weight"`
+}
+
+const (
+	// emphasisMultiplier is applied for each level of (parentheses)
+	emphasisMultiplier = 1.1
+	// deemphasisMultiplier is applied for each level of [brackets]
+	deemphasisMultiplier = 1 / 1.1
+	// maxWeight bounds explicit weights, larger ones distort the embeddings
+	// into noise
+	maxWeight = 3.0
+)
+
+// attentionToken splits a prompt into escapes, brackets, explicit weights and text
+var attentionToken = regexp.MustCompile(`\\\(|\\\)|\\\[|\\]|\\\\|\\|\(|\[|:\s*([+-]?[.\d]+(?:[eE][+-]?\d+)?)\s*\)|\)|]|[^\\()\[\]:]+|:`)
+
+// ParseAttention parses attention syntax into weighted segments:
+//
+//	(word)      increases attention by 1.1
+//	((word))    increases attention by 1.21
+//	[word]      decreases attention by 1.1
+//	(word:1.3)  sets attention to 1.3
+//	\( \) \[ \] literal brackets
+//
+// Unmatched brackets are kept as literal text, as in the Automatic1111 web
+// UI, so prompts like "smile :)" are accepted. Only malformed explicit
+// weights and ones outside 0 to 3 are reported as an error.
+func ParseAttention(text string) ([]Segment, error) {
+	var segments []Segment
+	var roundStack, squareStack []int
+
+	// Opening brackets are added as literal segments and cleared once
+	// closed, so unclosed ones remain in the text
+	open := func(stack *[]int, token string) {
+		segments = append(segments, Segment{Text: token, Weight: 1})
+		*stack = append(*stack, len(segments)-1)
+	}
+	closeRange := func(stack *[]int, multiplier float64) {
+		start := (*stack)[len(*stack)-1]
+		*stack = (*stack)[:len(*stack)-1]
+		segments[start].Text = ""
+		for i := start + 1; i < len(segments); i++ {
+			segments[i].Weight *= multiplier
+		}
+	}
+	literal := func(token string) {
+		segments = append(segments, Segment{Text: token, Weight: 1})
+	}
+
+	for _, match := range attentionToken.FindAllStringSubmatchIndex(text, -1) {
+		token := text[match[0]:match[1]]
+
+		switch {
+		case len(token) == 2 && token[0] == '\\':
+			literal(token[1:])
+		case token == "(":
+			open(&roundStack, token)
+		case token == "[":
+			open(&squareStack, token)
+		case match[2] >= 0:
+			// Explicit weight, e.g. ":1.3)"
+			if len(roundStack) == 0 {
+				literal(token)
+				continue
+			}
+			weight, err := strconv.ParseFloat(text[match[2]:match[3]], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid weight %q", ErrSyntax, text[match[2]:match[3]])
+			}
+			if weight < 0 || weight > maxWeight {
+				return nil, fmt.Errorf("%w: weight %s is outside 0 to %g", ErrSyntax, text[match[2]:match[3]], maxWeight)
+			}
+			closeRange(&roundStack, weight)
+		case token == ")":
+			if len(roundStack) == 0 {
+				literal(token)
+				continue
+			}
+			closeRange(&roundStack, emphasisMultiplier)
+		case token == "]":
+			if len(squareStack) == 0 {
+				literal(token)
+				continue
+			}
+			closeRange(&squareStack, deemphasisMultiplier)
+		default:
+			literal(token)
+		}
+	}
+
+	return mergeSegments(segments), nil
+}
+
+// Weighted reports whether any segment has an attention weight other than 1
+func Weighted(segments []Segment) bool {
+	for _, segment := range segments {
+		if segment.Weight != 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Text joins the segments back into a prompt without attention syntax
+func Text(segments []Segment) string {
+	var text strings.Builder
+	for _, segment := range segments {
+		text.WriteString(segment.Text)
+	}
+	return text.String()
+}
+
+// mergeSegments joins adjacent segments with equal weights and drops empty ones
+func mergeSegments(segments []Segment) []Segment {
+	var merged []Segment
+	for _, segment := range segments {
+		if segment.Text == "" {
+			continue
+		}
+		if len(merged) > 0 && merged[len(merged)-1].Weight == segment.Weight {
+			merged[len(merged)-1].Text += segment.Text
+			continue
+		}
+		merged = append(merged, segment)
+	}
+	if len(merged) == 0 {
+		return []Segment{{Text: "", Weight: 1}}
+	}
+	return merged
+}
@@ -0,0 +1,84 @@
+package prompt
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+func TestParseAttention(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Segment
+	}{
+		{"a cat", []Segment{{"a cat", 1}}},
+		{"a (cat)", []Segment{{"a ", 1}, {"cat", 1.1}}},
+		{"a ((cat))", []Segment{{"a ", 1}, {"cat", 1.21}}},
+		{"a [cat]", []Segment{{"a ", 1}, {"cat", 1 / 1.1}}},
+		{"a (cat:1.3), dog", []Segment{{"a ", 1}, {"cat", 1.3}, {", dog", 1}}},
+		{"(a (cat:1.5))", []Segment{{"a ", 1.1}, {"cat", 1.65}}},
+		{`a \(cat\)`, []Segment{{"a (cat)", 1}}},
+		{"smile :)", []Segment{{"smile :)", 1}}},
+		{"a (cat", []Segment{{"a (cat", 1}}},
+		{"", []Segment{{"", 1}}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAttention(tt.input)
+		if err != nil {
+			t.Errorf("ParseAttention(%q) error: %v", tt.input, err)
+			continue
+		}
+		if !equalSegments(got, tt.want) {
+			t.Errorf("ParseAttention(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseAttentionInvalidWeight(t *testing.T) {
+	for _, input := range []string{"a (cat:1.2.3)", "a (cat:1e9)", "a (cat:-0.5)", "a (cat:3.01)"} {
+		_, err := ParseAttention(input)
+		if !errors.Is(err, ErrSyntax) || !errors.Is(err, models.ErrInvalidPrompt) {
+			t.Errorf("ParseAttention(%q) error = %v, want ErrSyntax", input, err)
+		}
+	}
+
+	// The bounds themselves are accepted
+	for _, input := range []string{"a (cat:0)", "a (cat:3)", "a (cat:2e-1)"} {
+		if _, err := ParseAttention(input); err != nil {
+			t.Errorf("ParseAttention(%q) error: %v", input, err)
+		}
+	}
+}
+
+func TestWeightedAndText(t *testing.T) {
+	plain, _ := ParseAttention(`a \(cat\)`)
+	if Weighted(plain) {
+		t.Error("Weighted reports escaped brackets as weighted")
+	}
+	if got := Text(plain); got != "a (cat)" {
+		t.Errorf("Text = %q, want %q", got, "a (cat)")
+	}
+
+	weighted, _ := ParseAttention("a (cat:1.3)")
+	if !Weighted(weighted) {
+		t.Error("Weighted = false for an explicit weight")
+	}
+	if got := Text(weighted); got != "a cat" {
+		t.Errorf("Text = %q, want %q", got, "a cat")
+	}
+}
+
+func equalSegments(a, b []Segment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Text != b[i].Text || math.Abs(a[i].Weight-b[i].Weight) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
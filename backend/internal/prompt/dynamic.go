@@ -0,0 +1,242 @@
+package prompt
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// node is an element of a parsed dynamic prompt
+type node interface{}
+
+// literalNode is plain prompt text
+type literalNode string
+
+// choiceNode is a {a|b|c} alternation, each option is a sequence of nodes
+type choiceNode [][]node
+
+// wildcardNode is a __name__ reference
+type wildcardNode string
+
+// maxNestingDepth limits wildcard recursion and brace nesting
+const maxNestingDepth = 8
+
+// parser parses {a|b} alternations and __wildcard__ references
+type parser struct {
+	input string
+	pos   int
+	depth int
+}
+
+// parseTemplate parses a dynamic prompt into a node sequence
+func parseTemplate(input string, depth int) ([]node, error) {
+	if depth > maxNestingDepth {
+		return nil, fmt.Errorf("%w: nesting deeper than %d levels", ErrSyntax, maxNestingDepth)
+	}
+
+	p := &parser{input: input, depth: depth}
+	seq, err := p.parseSequence(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrSyntax, p.input[p.pos], p.pos)
+	}
+	return seq, nil
+}
+
+// parseSequence parses nodes until the end of input or, inside braces, a '|'
+// or '}'. Outside braces both are literal text, so plain prompts using them
+// are accepted.
+func (p *parser) parseSequence(inChoice bool) ([]node, error) {
+	var seq []node
+	var literal strings.Builder
+
+	flush := func() {
+		if literal.Len() > 0 {
+			seq = append(seq, literalNode(literal.String()))
+			literal.Reset()
+		}
+	}
+
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input) && strings.IndexByte("{}|", p.input[p.pos+1]) >= 0:
+			literal.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+		case c == '{':
+			flush()
+			choice, err := p.parseChoice()
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, choice)
+		case (c == '}' || c == '|') && inChoice:
+			flush()
+			return seq, nil
+		case strings.HasPrefix(p.input[p.pos:], "__"):
+			end := strings.Index(p.input[p.pos+2:], "__")
+			if end <= 0 {
+				literal.WriteString("__")
+				p.pos += 2
+				continue
+			}
+			flush()
+			seq = append(seq, wildcardNode(p.input[p.pos+2:p.pos+2+end]))
+			p.pos += end + 4
+		default:
+			literal.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	if inChoice {
+		return nil, fmt.Errorf("%w: unclosed '{'", ErrSyntax)
+	}
+	flush()
+	return seq, nil
+}
+
+// parseChoice parses a {a|b|c} alternation starting at '{'
+func (p *parser) parseChoice() (choiceNode, error) {
+	p.depth++
+	if p.depth > maxNestingDepth {
+		return nil, fmt.Errorf("%w: nesting deeper than %d levels", ErrSyntax, maxNestingDepth)
+	}
+	defer func() { p.depth-- }()
+
+	p.pos++ // skip '{'
+	var choice choiceNode
+	for {
+		option, err := p.parseSequence(true)
+		if err != nil {
+			return nil, err
+		}
+		choice = append(choice, option)
+
+		c := p.input[p.pos]
+		p.pos++
+		if c == '}' {
+			return choice, nil
+		}
+	}
+}
+
+// expander expands parsed templates
+type expander struct {
+	wildcards *Wildcards
+	limit     int
+	depth     int
+}
+
+// resolveWildcard turns a wildcard into a choice of its parsed options
+func (e *expander) resolveWildcard(name wildcardNode) (choiceNode, error) {
+	if e.depth >= maxNestingDepth {
+		return nil, fmt.Errorf("%w: wildcard __%s__ nested too deeply", ErrSyntax, name)
+	}
+
+	options, err := e.wildcards.Options(string(name))
+	if err != nil {
+		return nil, err
+	}
+
+	choice := make(choiceNode, 0, len(options))
+	for _, option := range options {
+		seq, err := parseTemplate(option, e.depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("in __%s__: %w", name, err)
+		}
+		choice = append(choice, seq)
+	}
+	return choice, nil
+}
+
+// combinations returns every expansion of seq, up to the expander's limit
+func (e *expander) combinations(seq []node) ([]string, error) {
+	results := []string{""}
+
+	for _, n := range seq {
+		var options []string
+		switch n := n.(type) {
+		case literalNode:
+			options = []string{string(n)}
+		case choiceNode:
+			for _, option := range n {
+				expanded, err := e.combinations(option)
+				if err != nil {
+					return nil, err
+				}
+				options = append(options, expanded...)
+			}
+		case wildcardNode:
+			choice, err := e.resolveWildcard(n)
+			if err != nil {
+				return nil, err
+			}
+			e.depth++
+			expanded, err := e.combinations([]node{choice})
+			e.depth--
+			if err != nil {
+				return nil, err
+			}
+			options = expanded
+		}
+
+		next := make([]string, 0, len(results)*len(options))
+	product:
+		for _, prefix := range results {
+			for _, option := range options {
+				next = append(next, prefix+option)
+				if len(next) >= e.limit {
+					break product
+				}
+			}
+		}
+		results = next
+	}
+
+	return results, nil
+}
+
+// sample returns one random expansion of seq
+func (e *expander) sample(seq []node, rng *rand.Rand) (string, error) {
+	var b strings.Builder
+
+	for _, n := range seq {
+		switch n := n.(type) {
+		case literalNode:
+			b.WriteString(string(n))
+		case choiceNode:
+			expanded, err := e.sample(n[rng.Intn(len(n))], rng)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+		case wildcardNode:
+			choice, err := e.resolveWildcard(n)
+			if err != nil {
+				return "", err
+			}
+			e.depth++
+			expanded, err := e.sample(choice[rng.Intn(len(choice))], rng)
+			e.depth--
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// isDynamic reports whether a parsed template contains any alternations or wildcards
+func isDynamic(seq []node) bool {
+	for _, n := range seq {
+		if _, ok := n.(literalNode); !ok {
+			return true
+		}
+	}
+	return false
+}
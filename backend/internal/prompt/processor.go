@@ -0,0 +1,140 @@
+// Package prompt implements prompt preprocessing: attention weighting syntax,
+// __wildcard__ files and {a|b|c} dynamic prompts.
+package prompt
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// Both errors wrap models.ErrInvalidPrompt, so the API reports them as
+// client errors
+var (
+	// ErrSyntax is returned for malformed prompt syntax
+	ErrSyntax = fmt.Errorf("%w syntax", models.ErrInvalidPrompt)
+	// ErrWildcardNotFound is returned when a wildcard file does not exist
+	ErrWildcardNotFound = fmt.Errorf("%w: wildcard not found", models.ErrInvalidPrompt)
+)
+
+// Mode selects how dynamic prompts are expanded
+type Mode string
+
+const (
+	// ModeRandom picks one random option per alternation for each prompt
+	ModeRandom Mode = "random"
+	// ModeCombinatorial produces every combination of options
+	ModeCombinatorial Mode = "combinatorial"
+)
+
+// Processor expands and checks prompts before they are queued
+type Processor struct {
+	wildcards  *Wildcards
+	maxPrompts int
+}
+
+// NewProcessor creates a new prompt processor
+func NewProcessor(cfg config.PromptsConfig) *Processor {
+	maxPrompts := cfg.MaxPrompts
+	if maxPrompts <= 0 {
+		maxPrompts = 1
+	}
+	return &Processor{
+		wildcards:  NewWildcards(cfg.WildcardsDir),
+		maxPrompts: maxPrompts,
+	}
+}
+
+// MaxPrompts returns the maximum number of prompts a single request may expand into
+func (p *Processor) MaxPrompts() int {
+	return p.maxPrompts
+}
+
+// Expand expands a dynamic prompt template.
+//
+// In random mode count prompts are sampled using a generator seeded with seed.
+// In combinatorial mode the first count combinations are produced. When count
+// is zero every combination is produced, and templates with more than
+// MaxPrompts combinations are rejected rather than cut short. Each expanded
+// prompt is checked for valid attention syntax.
+func (p *Processor) Expand(template string, mode Mode, count int, seed int64) ([]string, error) {
+	seq, err := parseTemplate(template, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	allCombinations := count <= 0 && mode == ModeCombinatorial
+	if count <= 0 {
+		count = 1
+		if mode == ModeCombinatorial {
+			count = p.maxPrompts
+		}
+	}
+	if count > p.maxPrompts {
+		return nil, fmt.Errorf("%w: at most %d prompts may be generated per request", ErrSyntax, p.maxPrompts)
+	}
+
+	e := &expander{wildcards: p.wildcards, limit: count}
+	if allCombinations {
+		// Produce one more to find out whether the template has too many
+		e.limit = count + 1
+	}
+
+	var prompts []string
+	switch {
+	case !isDynamic(seq):
+		prompts = []string{template}
+		if mode == ModeRandom || mode == "" {
+			for len(prompts) < count {
+				prompts = append(prompts, template)
+			}
+		}
+	case mode == ModeCombinatorial:
+		prompts, err = e.combinations(seq)
+	case mode == ModeRandom || mode == "":
+		rng := rand.New(rand.NewSource(seed))
+		for i := 0; i < count; i++ {
+			expanded, err := e.sample(seq, rng)
+			if err != nil {
+				return nil, err
+			}
+			prompts = append(prompts, expanded)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown prompt mode %q", ErrSyntax, mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(prompts) > count {
+		return nil, fmt.Errorf("%w: the template has more than %d combinations, set prompt_count to generate only the first ones", ErrSyntax, count)
+	}
+
+	for _, expanded := range prompts {
+		if _, err := ParseAttention(expanded); err != nil {
+			return nil, err
+		}
+	}
+
+	return prompts, nil
+}
+
+// ExpandOne expands a template into a single prompt using random mode
+func (p *Processor) ExpandOne(template string, seed int64) (string, error) {
+	prompts, err := p.Expand(template, ModeRandom, 1, seed)
+	if err != nil {
+		return "", err
+	}
+	return prompts[0], nil
+}
+
+// Seed returns the seed to use for prompt expansion, drawing a random one for -1
+func Seed(seed int64) int64 {
+	if seed >= 0 {
+		return seed
+	}
+	return time.Now().UnixNano()
+}
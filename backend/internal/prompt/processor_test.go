@@ -0,0 +1,67 @@
+package prompt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+func TestExpandPlainPrompts(t *testing.T) {
+	p := NewProcessor(config.PromptsConfig{WildcardsDir: t.TempDir(), MaxPrompts: 10})
+
+	// Braces and pipes outside an alternation are literal text
+	for _, input := range []string{
+		"a cat",
+		"a cat }",
+		"black | white",
+		"a } b | c",
+		"smile :) (cat:1.2)",
+	} {
+		got, err := p.Expand(input, ModeRandom, 1, 1)
+		if err != nil {
+			t.Errorf("Expand(%q) error: %v", input, err)
+			continue
+		}
+		if len(got) != 1 || got[0] != input {
+			t.Errorf("Expand(%q) = %q, want it unchanged", input, got)
+		}
+	}
+}
+
+func TestExpandCombinatorial(t *testing.T) {
+	p := NewProcessor(config.PromptsConfig{WildcardsDir: t.TempDir(), MaxPrompts: 10})
+
+	got, err := p.Expand("a {red|blue} cat | {big|small}", ModeCombinatorial, 0, 1)
+	if err != nil {
+		t.Fatalf("Expand error: %v", err)
+	}
+	want := []string{"a red cat | big", "a red cat | small", "a blue cat | big", "a blue cat | small"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expand = %q, want %q", got, want)
+	}
+}
+
+func TestExpandSyntaxErrors(t *testing.T) {
+	p := NewProcessor(config.PromptsConfig{WildcardsDir: t.TempDir(), MaxPrompts: 10})
+
+	for _, input := range []string{
+		"a {red|blue cat",
+		"a (cat:1.2.3)",
+		"a {red|(cat:1..2)}",
+	} {
+		_, err := p.Expand(input, ModeRandom, 1, 1)
+		if !errors.Is(err, ErrSyntax) {
+			t.Errorf("Expand(%q) error = %v, want ErrSyntax", input, err)
+		}
+		if code := models.CodeOf(err); code != models.CodeInvalidRequest {
+			t.Errorf("Expand(%q) error code = %q, want %q", input, code, models.CodeInvalidRequest)
+		}
+	}
+
+	if _, err := p.Expand("a __missing__ cat", ModeRandom, 1, 1); models.CodeOf(err) != models.CodeInvalidRequest {
+		t.Errorf("Expand with a missing wildcard error = %v, want an invalid_request error", err)
+	}
+}
@@ -0,0 +1,96 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// wildcardName restricts wildcard names to safe relative paths
+var wildcardName = regexp.MustCompile(`^[A-Za-z0-9_\-]+(/[A-Za-z0-9_\-]+)*$`)
+
+// Wildcards loads wildcard option lists from text files.
+// "__colors__" reads <dir>/colors.txt and "__animals/cats__" reads
+// <dir>/animals/cats.txt, one option per line. Blank lines and lines
+// starting with '#' are ignored. Files are reloaded when they change.
+type Wildcards struct {
+	dir   string
+	mu    sync.Mutex
+	cache map[string]*wildcardFile
+}
+
+type wildcardFile struct {
+	options []string
+	modTime time.Time
+}
+
+// NewWildcards creates a wildcard loader rooted at dir
+func NewWildcards(dir string) *Wildcards {
+	return &Wildcards{
+		dir:   dir,
+		cache: make(map[string]*wildcardFile),
+	}
+}
+
+// Options returns the options of a wildcard
+func (w *Wildcards) Options(name string) ([]string, error) {
+	if w.dir == "" {
+		return nil, fmt.Errorf("%w: __%s__ (no wildcards directory configured)", ErrWildcardNotFound, name)
+	}
+	if !wildcardName.MatchString(name) {
+		return nil, fmt.Errorf("%w: invalid wildcard name %q", ErrSyntax, name)
+	}
+
+	path := filepath.Join(w.dir, filepath.FromSlash(name)+".txt")
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: __%s__", ErrWildcardNotFound, name)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if cached, exists := w.cache[name]; exists && cached.modTime.Equal(info.ModTime()) {
+		return cached.options, nil
+	}
+
+	options, err := readWildcardFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(options) == 0 {
+		return nil, fmt.Errorf("%w: __%s__ is empty", ErrWildcardNotFound, name)
+	}
+
+	w.cache[name] = &wildcardFile{options: options, modTime: info.ModTime()}
+	return options, nil
+}
+
+// readWildcardFile reads the non-empty, non-comment lines of a wildcard file
+func readWildcardFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wildcard file: %w", err)
+	}
+	defer file.Close()
+
+	var options []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		options = append(options, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wildcard file: %w", err)
+	}
+
+	return options, nil
+}
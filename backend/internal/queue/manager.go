@@ -2,6 +2,9 @@ package queue
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -15,16 +18,26 @@ import (
 // Manager interface for queue operations
 type Manager interface {
 	Enqueue(req *models.GenerationRequest) (int, error)
-	Cancel(id string) error
-	GetStatus(id string) (*models.GenerationStatus, error)
-	GetQueue() ([]*models.QueueItem, error)
+	Cancel(id, owner string) error
+	GetStatus(id, owner string) (*models.GenerationStatus, error)
+	GetQueue(owner string) ([]*models.QueueItem, error)
 	StartProcessor(ctx context.Context)
+	AddListener(listener Listener)
+	UpdateConfig(cfg config.QueueConfig)
+	Wait(ctx context.Context, id string) (*models.GenerationStatus, error)
+	Watch(ctx context.Context, id, owner string) (<-chan models.GenerationStatus, error)
 }
 
+// Listener is called once when a generation reaches a terminal state
+// (completed, failed or cancelled). Listeners run on the queue's goroutines
+// and must not block.
+type Listener func(req *models.GenerationRequest, status models.GenerationStatus)
+
 // QueueManager implements the Manager interface
 type QueueManager struct {
 	queue          []*models.QueueItem
 	statuses       map[string]*models.GenerationStatus
+	requests       map[string]*models.GenerationRequest
 	mu             sync.RWMutex
 	config         config.QueueConfig
 	inference      inference.Engine
@@ -32,6 +45,11 @@ type QueueManager struct {
 	logger         *logrus.Logger
 	processingChan chan *models.GenerationRequest
 	cancelChans    map[string]chan struct{}
+	doneChans      map[string]chan struct{}
+	watchers       map[string][]chan models.GenerationStatus
+	listeners      []Listener
+	workerCtx      context.Context
+	workers        []chan struct{} // Closed to stop the worker after its current job
 }
 
 // NewManager creates a new queue manager
@@ -39,20 +57,33 @@ func NewManager(config config.QueueConfig, inference inference.Engine, storage s
 	return &QueueManager{
 		queue:          make([]*models.QueueItem, 0),
 		statuses:       make(map[string]*models.GenerationStatus),
+		requests:       make(map[string]*models.GenerationRequest),
 		config:         config,
 		inference:      inference,
 		storage:        storage,
 		logger:         logger,
 		processingChan: make(chan *models.GenerationRequest, config.MaxQueueSize),
 		cancelChans:    make(map[string]chan struct{}),
+		doneChans:      make(map[string]chan struct{}),
+		watchers:       make(map[string][]chan models.GenerationStatus),
 	}
 }
 
 // Enqueue adds a generation request to the queue
 func (m *QueueManager) Enqueue(req *models.GenerationRequest) (int, error) {
+	// Refuse work whose outputs could not be stored
+	if err := m.storage.CheckQuota(req.Owner); err != nil {
+		return -1, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// Client-supplied IDs must not replace another job, whoever owns it
+	if _, exists := m.statuses[req.ID]; exists {
+		return -1, models.ErrGenerationExists
+	}
+
 	// Check queue size
 	if len(m.queue) >= m.config.MaxQueueSize {
 		return -1, models.ErrQueueFull
@@ -76,9 +107,11 @@ func (m *QueueManager) Enqueue(req *models.GenerationRequest) (int, error) {
 	// Add to queue
 	m.queue = append(m.queue, item)
 	m.statuses[req.ID] = status
+	m.requests[req.ID] = req
 
-	// Create cancel channel
+	// Create cancel and completion channels
 	m.cancelChans[req.ID] = make(chan struct{})
+	m.doneChans[req.ID] = make(chan struct{})
 
 	// Send to processing channel
 	select {
@@ -91,16 +124,30 @@ func (m *QueueManager) Enqueue(req *models.GenerationRequest) (int, error) {
 	return item.Position, nil
 }
 
-// Cancel cancels a generation request
-func (m *QueueManager) Cancel(id string) error {
+// AddListener registers a listener for terminal status transitions
+func (m *QueueManager) AddListener(listener Listener) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.listeners = append(m.listeners, listener)
+}
+
+// Cancel cancels a generation request of owner
+func (m *QueueManager) Cancel(id, owner string) error {
+	m.mu.Lock()
+
 	status, exists := m.statuses[id]
-	if !exists {
+	if !exists || !m.owns(id, owner) {
+		m.mu.Unlock()
 		return models.ErrGenerationNotFound
 	}
 
+	// Finished generations cannot be cancelled
+	if status.Status.IsTerminal() {
+		m.mu.Unlock()
+		return nil
+	}
+
 	// Update status
 	status.Status = models.StatusCancelled
 	now := time.Now()
@@ -119,44 +166,188 @@ func (m *QueueManager) Cancel(id string) error {
 			break
 		}
 	}
+	m.mu.Unlock()
 
+	m.notify(id)
 	return nil
 }
 
-// GetStatus returns the status of a generation request
-func (m *QueueManager) GetStatus(id string) (*models.GenerationStatus, error) {
+// GetStatus returns the status of a generation request of owner
+func (m *QueueManager) GetStatus(id, owner string) (*models.GenerationStatus, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	status, exists := m.statuses[id]
-	if !exists {
+	if !exists || !m.owns(id, owner) {
 		return nil, models.ErrGenerationNotFound
 	}
 
 	return status, nil
 }
 
-// GetQueue returns the current queue
-func (m *QueueManager) GetQueue() ([]*models.QueueItem, error) {
+// Wait blocks until the generation reaches a terminal state or ctx is done.
+// It returns a snapshot of the final status, or ctx.Err() on timeout.
+func (m *QueueManager) Wait(ctx context.Context, id string) (*models.GenerationStatus, error) {
+	m.mu.RLock()
+	status, exists := m.statuses[id]
+	if !exists {
+		m.mu.RUnlock()
+		return nil, models.ErrGenerationNotFound
+	}
+	if status.Status.IsTerminal() {
+		snapshot := *status
+		m.mu.RUnlock()
+		return &snapshot, nil
+	}
+	done := m.doneChans[id]
+	m.mu.RUnlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
+	status, exists = m.statuses[id]
+	if !exists {
+		return nil, models.ErrGenerationNotFound
+	}
+	snapshot := *status
+	return &snapshot, nil
+}
+
+// Watch streams snapshots of the status of owner's generation on every
+// status or progress change. Slow readers only see the latest snapshot. The
+// channel is closed after the terminal status is sent or when ctx is done.
+func (m *QueueManager) Watch(ctx context.Context, id, owner string) (<-chan models.GenerationStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status, exists := m.statuses[id]
+	if !exists || !m.owns(id, owner) {
+		return nil, models.ErrGenerationNotFound
+	}
+
+	ch := make(chan models.GenerationStatus, 1)
+	ch <- *status
+	if status.Status.IsTerminal() {
+		close(ch)
+		return ch, nil
+	}
+	m.watchers[id] = append(m.watchers[id], ch)
+	done := m.doneChans[id]
 
-	items := make([]*models.QueueItem, len(m.queue))
-	copy(items, m.queue)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		watchers := m.watchers[id]
+		for i, watcher := range watchers {
+			if watcher == ch {
+				m.watchers[id] = append(watchers[:i], watchers[i+1:]...)
+				close(ch)
+				break
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// publish sends a snapshot of a generation's status to its watchers,
+// replacing any snapshot they have not read yet. Callers must hold m.mu.
+func (m *QueueManager) publish(id string) {
+	status, exists := m.statuses[id]
+	if !exists {
+		return
+	}
+
+	for _, ch := range m.watchers[id] {
+		select {
+		case ch <- *status:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- *status
+		}
+	}
+}
+
+// GetQueue returns owner's queued and running generations
+func (m *QueueManager) GetQueue(owner string) ([]*models.QueueItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := make([]*models.QueueItem, 0, len(m.queue))
+	for _, item := range m.queue {
+		if item.Request.Owner == owner {
+			items = append(items, item)
+		}
+	}
 	return items, nil
 }
 
+// owns reports whether a generation was submitted with owner's API key.
+// Callers must hold m.mu.
+func (m *QueueManager) owns(id, owner string) bool {
+	req, exists := m.requests[id]
+	return exists && req.Owner == owner
+}
+
 // StartProcessor starts the queue processor
 func (m *QueueManager) StartProcessor(ctx context.Context) {
 	m.logger.Info("Starting queue processor")
 
-	for i := 0; i < m.config.MaxConcurrent; i++ {
-		go m.processWorker(ctx, i)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.workerCtx = ctx
+	m.resizeWorkers(m.config.MaxConcurrent)
+}
+
+// UpdateConfig applies a changed queue config. The worker pool grows or
+// shrinks to MaxConcurrent, stopped workers finish their current job first.
+// MaxQueueSize sizes the processing channel and needs a restart.
+func (m *QueueManager) UpdateConfig(cfg config.QueueConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config.MaxConcurrent = cfg.MaxConcurrent
+	m.config.Timeout = cfg.Timeout
+	m.config.Retention = cfg.Retention
+	if m.workerCtx != nil {
+		m.resizeWorkers(cfg.MaxConcurrent)
 	}
+	m.logger.WithFields(logrus.Fields{
+		"max_concurrent": cfg.MaxConcurrent,
+		"timeout":        cfg.Timeout,
+	}).Info("Queue configuration updated")
 }
 
-// processWorker processes generation requests
-func (m *QueueManager) processWorker(ctx context.Context, workerID int) {
+// resizeWorkers starts or stops workers until n are running, caller must hold the lock
+func (m *QueueManager) resizeWorkers(n int) {
+	for len(m.workers) < n {
+		stop := make(chan struct{})
+		m.workers = append(m.workers, stop)
+		go m.processWorker(m.workerCtx, len(m.workers)-1, stop)
+	}
+	for len(m.workers) > n {
+		close(m.workers[len(m.workers)-1])
+		m.workers = m.workers[:len(m.workers)-1]
+	}
+}
+
+// processWorker processes generation requests until ctx is done or stop is closed
+func (m *QueueManager) processWorker(ctx context.Context, workerID int, stop <-chan struct{}) {
 	logger := m.logger.WithField("worker_id", workerID)
 	logger.Info("Queue worker started")
 
@@ -166,6 +357,10 @@ func (m *QueueManager) processWorker(ctx context.Context, workerID int) {
 			logger.Info("Queue worker stopped")
 			return
 
+		case <-stop:
+			logger.Info("Queue worker stopped")
+			return
+
 		case req := <-m.processingChan:
 			if req == nil {
 				continue
@@ -179,18 +374,34 @@ func (m *QueueManager) processWorker(ctx context.Context, workerID int) {
 
 // processRequest processes a single generation request
 func (m *QueueManager) processRequest(ctx context.Context, req *models.GenerationRequest) {
-	// Update status to processing
-	m.updateStatus(req.ID, models.StatusProcessing, 0)
+	// Remove from queue when done
+	defer m.removeFromQueue(req.ID)
+
+	// Update status to processing, skipping requests cancelled while queued
+	if !m.updateStatus(req.ID, models.StatusProcessing, 0) {
+		m.logger.WithField("request_id", req.ID).Info("Skipping cancelled generation")
+		return
+	}
 
-	// Get cancel channel
+	// Get cancel channel and timeout
 	m.mu.RLock()
 	cancelChan := m.cancelChans[req.ID]
+	timeout := time.Duration(m.config.Timeout) * time.Second
 	m.mu.RUnlock()
 
 	// Create timeout context
-	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(m.config.Timeout)*time.Second)
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// Stop inference when the generation is cancelled while running
+	go func() {
+		select {
+		case <-cancelChan:
+			cancel()
+		case <-timeoutCtx.Done():
+		}
+	}()
+
 	// Progress callback
 	progressCallback := func(progress float64, step int) {
 		m.updateProgress(req.ID, progress, step)
@@ -205,46 +416,121 @@ func (m *QueueManager) processRequest(ctx context.Context, req *models.Generatio
 
 	case <-timeoutCtx.Done():
 		m.logger.WithField("request_id", req.ID).Error("Generation timeout")
-		m.updateStatusWithError(req.ID, models.StatusFailed, "Generation timeout")
+		m.updateStatusWithError(req.ID, models.StatusFailed, models.ErrGenerationTimeout)
 		return
 
 	default:
 		// Call inference engine (placeholder for now)
 		results, err := m.inference.Generate(timeoutCtx, req, progressCallback)
 		if err != nil {
-			m.logger.WithError(err).WithField("request_id", req.ID).Error("Generation failed")
-			m.updateStatusWithError(req.ID, models.StatusFailed, err.Error())
+			if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) && !errors.Is(err, models.ErrGenerationTimeout) {
+				err = fmt.Errorf("%w: %v", models.ErrGenerationTimeout, err)
+			}
+			m.logger.WithError(err).WithFields(logrus.Fields{
+				"request_id": req.ID,
+				"error_code": models.CodeOf(err),
+			}).Error("Generation failed")
+			m.updateStatusWithError(req.ID, models.StatusFailed, err)
 			return
 		}
 
+		// Move images written by the inference service onto the storage backend
+		if err := m.publishResults(storage.WithOwner(timeoutCtx, req.Owner), req, results); err != nil {
+			m.logger.WithError(err).WithFields(logrus.Fields{
+				"request_id": req.ID,
+				"error_code": models.CodeOf(err),
+			}).Error("Generation outputs rejected by storage")
+			m.updateStatusWithError(req.ID, models.StatusFailed, err)
+			return
+		}
+
+		// Record the expanded prompt in each result
+		for _, result := range results {
+			if result.Metadata == nil {
+				result.Metadata = make(map[string]string)
+			}
+			result.Metadata["prompt"] = req.Prompt
+			if req.PromptTemplate != "" {
+				result.Metadata["prompt_template"] = req.PromptTemplate
+			}
+		}
+
 		// Update status with results
 		m.updateStatusWithResults(req.ID, models.StatusCompleted, results)
 		m.logger.WithField("request_id", req.ID).Info("Generation completed")
 	}
+}
 
-	// Remove from queue
-	m.removeFromQueue(req.ID)
+// publishResults publishes each result image to the storage backend,
+// converting it to the requested format and adding thumbnails. Images that
+// were never written, such as mock results, are skipped. The first image
+// rejected by a quota or size limit is returned, other failures only logged.
+func (m *QueueManager) publishResults(ctx context.Context, req *models.GenerationRequest, results []*models.GenerationResult) error {
+	var rejected error
+	for _, result := range results {
+		if result.ImagePath == "" {
+			continue
+		}
+		published, err := m.storage.PublishOutput(ctx, filepath.Base(result.ImagePath), req.OutputFormat, req.OutputQuality)
+		if published != nil {
+			result.ImagePath = filepath.Join(filepath.Dir(result.ImagePath), published.Name)
+			result.ImageURL = m.storage.OutputPath(published.Name)
+			result.Thumbnails = published.Thumbnails
+			if err != nil {
+				m.logger.WithError(err).WithFields(logrus.Fields{
+					"request_id": req.ID,
+					"image":      result.ImagePath,
+				}).Warn("Output published without all requested formats")
+			}
+			continue
+		}
+		switch {
+		case err == nil, errors.Is(err, models.ErrFileNotFound):
+		case errors.Is(err, models.ErrStorageFull), errors.Is(err, models.ErrFileTooLarge):
+			if rejected == nil {
+				rejected = err
+			}
+		default:
+			m.logger.WithError(err).WithFields(logrus.Fields{
+				"request_id": req.ID,
+				"image":      result.ImagePath,
+			}).Error("Failed to publish output")
+		}
+	}
+	return rejected
 }
 
-// updateStatus updates the status of a generation
-func (m *QueueManager) updateStatus(id string, status models.GenerationStatusType, progress float64) {
+// updateStatus updates the status of a generation. Generations that already
+// reached a terminal state are left unchanged and false is returned.
+func (m *QueueManager) updateStatus(id string, status models.GenerationStatusType, progress float64) bool {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
-	if genStatus, exists := m.statuses[id]; exists {
-		genStatus.Status = status
-		genStatus.Progress = progress
-		
-		if status == models.StatusProcessing && genStatus.StartedAt == nil {
-			now := time.Now()
-			genStatus.StartedAt = &now
-		}
-		
-		if status == models.StatusCompleted || status == models.StatusFailed || status == models.StatusCancelled {
-			now := time.Now()
-			genStatus.CompletedAt = &now
-		}
+	genStatus, exists := m.statuses[id]
+	if !exists || genStatus.Status.IsTerminal() {
+		m.mu.Unlock()
+		return false
 	}
+
+	genStatus.Status = status
+	genStatus.Progress = progress
+
+	if status == models.StatusProcessing && genStatus.StartedAt == nil {
+		now := time.Now()
+		genStatus.StartedAt = &now
+	}
+
+	if status.IsTerminal() {
+		now := time.Now()
+		genStatus.CompletedAt = &now
+	} else {
+		m.publish(id)
+	}
+	m.mu.Unlock()
+
+	if status.IsTerminal() {
+		m.notify(id)
+	}
+	return true
 }
 
 // updateProgress updates the progress of a generation
@@ -255,37 +541,109 @@ func (m *QueueManager) updateProgress(id string, progress float64, step int) {
 	if status, exists := m.statuses[id]; exists {
 		status.Progress = progress
 		status.CurrentStep = step
+		m.publish(id)
 	}
 }
 
-// updateStatusWithError updates the status with an error
-func (m *QueueManager) updateStatusWithError(id string, status models.GenerationStatusType, errorMsg string) {
+// updateStatusWithError updates the status with an error and its code
+func (m *QueueManager) updateStatusWithError(id string, status models.GenerationStatusType, err error) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
-	if genStatus, exists := m.statuses[id]; exists {
-		genStatus.Status = status
-		genStatus.Error = errorMsg
-		now := time.Now()
-		genStatus.CompletedAt = &now
+	genStatus, exists := m.statuses[id]
+	if !exists || genStatus.Status.IsTerminal() {
+		m.mu.Unlock()
+		return
 	}
+
+	genStatus.Status = status
+	genStatus.Error = err.Error()
+	genStatus.ErrorCode = models.CodeOf(err)
+	now := time.Now()
+	genStatus.CompletedAt = &now
+	m.mu.Unlock()
+
+	m.notify(id)
 }
 
 // updateStatusWithResults updates the status with results
 func (m *QueueManager) updateStatusWithResults(id string, status models.GenerationStatusType, results []*models.GenerationResult) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
-	if genStatus, exists := m.statuses[id]; exists {
-		genStatus.Status = status
-		genStatus.Progress = 100
-		genStatus.Results = make([]models.GenerationResult, len(results))
-		for i, result := range results {
-			genStatus.Results[i] = *result
+	genStatus, exists := m.statuses[id]
+	if !exists || genStatus.Status.IsTerminal() {
+		m.mu.Unlock()
+		return
+	}
+
+	genStatus.Status = status
+	genStatus.Progress = 100
+	genStatus.Results = make([]models.GenerationResult, len(results))
+	for i, result := range results {
+		genStatus.Results[i] = *result
+	}
+	now := time.Now()
+	genStatus.CompletedAt = &now
+	m.mu.Unlock()
+
+	m.notify(id)
+}
+
+// notify wakes up waiters and calls the listeners with a snapshot of a
+// generation's terminal status
+func (m *QueueManager) notify(id string) {
+	m.mu.Lock()
+	status, exists := m.statuses[id]
+	if !exists {
+		m.mu.Unlock()
+		return
+	}
+	m.publish(id)
+	for _, ch := range m.watchers[id] {
+		close(ch)
+	}
+	delete(m.watchers, id)
+	if done, exists := m.doneChans[id]; exists {
+		close(done)
+		delete(m.doneChans, id)
+	}
+	snapshot := *status
+	req := m.requests[id]
+	listeners := make([]Listener, len(m.listeners))
+	copy(listeners, m.listeners)
+	retention := time.Duration(m.config.Retention) * time.Second
+	m.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(req, snapshot)
+	}
+
+	m.release(id, req, retention)
+}
+
+// release drops the input images of a finished generation and forgets it
+// after the retention window, so the queue does not grow without bound
+func (m *QueueManager) release(id string, req *models.GenerationRequest, retention time.Duration) {
+	if req != nil {
+		trimmed := req.Clone()
+		trimmed.InitImage = ""
+		trimmed.Mask = ""
+		for i := range trimmed.ControlNets {
+			trimmed.ControlNets[i].Image = ""
 		}
-		now := time.Now()
-		genStatus.CompletedAt = &now
+		m.mu.Lock()
+		if m.requests[id] == req {
+			m.requests[id] = trimmed
+		}
+		m.mu.Unlock()
 	}
+
+	time.AfterFunc(retention, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.statuses, id)
+		delete(m.requests, id)
+		delete(m.cancelChans, id)
+	})
 }
 
 // removeFromQueue removes a request from the queue
@@ -299,6 +657,7 @@ func (m *QueueManager) removeFromQueue(id string) {
 			break
 		}
 	}
+	delete(m.cancelChans, id)
 
 	// Update positions
 	for i, item := range m.queue {
@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/ablerefusal/ablerefusal/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// unlimitedStorage is a storage manager without quotas, only CheckQuota is used
+type unlimitedStorage struct {
+	storage.Manager
+}
+
+func (unlimitedStorage) CheckQuota(owner string) error {
+	return nil
+}
+
+func TestEnqueueRejectsIDInUse(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	m := NewManager(config.QueueConfig{MaxQueueSize: 10}, nil, unlimitedStorage{}, logger)
+
+	first := &models.GenerationRequest{ID: "job-1", Owner: "key-1", Prompt: "a cat", Steps: 20}
+	if _, err := m.Enqueue(first); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	second := &models.GenerationRequest{ID: "job-1", Owner: "key-2", Prompt: "a dog", Steps: 30}
+	_, err := m.Enqueue(second)
+	if !errors.Is(err, models.ErrGenerationExists) {
+		t.Fatalf("Enqueue with a reused ID error = %v, want ErrGenerationExists", err)
+	}
+	if code := models.CodeOf(err); code != models.CodeGenerationExists {
+		t.Errorf("error code = %q, want %q", code, models.CodeGenerationExists)
+	}
+
+	// The first key's job is untouched and the second key cannot see it
+	status, err := m.GetStatus("job-1", "key-1")
+	if err != nil {
+		t.Fatalf("GetStatus for the owner: %v", err)
+	}
+	if status.TotalSteps != 20 {
+		t.Errorf("TotalSteps = %d, want the first job's 20", status.TotalSteps)
+	}
+	if _, err := m.GetStatus("job-1", "key-2"); !errors.Is(err, models.ErrGenerationNotFound) {
+		t.Errorf("GetStatus for the other key error = %v, want ErrGenerationNotFound", err)
+	}
+	if queue, _ := m.GetQueue("key-1"); len(queue) != 1 {
+		t.Errorf("queue has %d items, want 1", len(queue))
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// Storage backends
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// Output URL modes
+const (
+	URLModeProxy   = "proxy"
+	URLModePresign = "presign"
+)
+
+// ObjectInfo describes a stored output
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModifiedAt  time.Time
+	ETag        string // Changes whenever the content does, empty if unknown
+	Owner       string // API key ID the output was stored for, empty if unknown
+}
+
+// Backend stores output objects by slash-separated key. Missing objects are
+// reported as models.ErrFileNotFound.
+type Backend interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, fn func(ObjectInfo) error) error
+	// PresignGet returns a time-limited link to the object, or an empty
+	// string if the backend cannot issue one
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// NewBackend creates the output backend selected in the config
+func NewBackend(cfg config.StorageConfig) (Backend, error) {
+	switch cfg.Backend {
+	case "", BackendLocal:
+		return NewLocalBackend(cfg.OutputDir)
+	case BackendS3:
+		return NewS3Backend(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// cleanKey validates an object key. Keys are relative, slash-separated and
+// may not escape the backend's root.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(strings.ReplaceAll(key, "\\", "/"), "/")
+	cleaned := path.Clean(key)
+	if key == "" || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", models.ErrInvalidFileName
+	}
+	return cleaned, nil
+}
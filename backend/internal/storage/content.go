@@ -0,0 +1,562 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// blobPrefix is the key prefix of content-addressed blobs
+const blobPrefix = "blobs/"
+
+// ContentStore implements the Backend interface on top of another backend.
+// Each distinct output is stored once as a blob named by its SHA-256 and
+// sharded as blobs/ab/cd/<hash><ext>. An index maps output names to blobs and
+// counts the names per blob, so a blob is only removed with its last name.
+// Names missing from the index fall through to flat objects written before
+// content addressing, which Migrate moves into blobs.
+type ContentStore struct {
+	blobs   Backend
+	tempDir string
+	file    string
+	maxSize int64 // Largest object Put accepts, 0 for no limit
+
+	writeMu sync.Mutex // Serialises changes to the index and blobs
+
+	mu        sync.RWMutex
+	entries   map[string]*indexEntry
+	refs      map[string]int
+	blobBytes int64            // Size of all referenced blobs
+	ownerSize map[string]int64 // Size of the outputs of each owner
+}
+
+// indexEntry maps an output name to its blob. Entries are appended to the
+// index file as JSON lines; a deleted entry removes an earlier one.
+type indexEntry struct {
+	Name        string    `json:"name"`
+	Blob        string    `json:"blob,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Owner       string    `json:"owner,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Deleted     bool      `json:"deleted,omitempty"`
+}
+
+// MigrationReport describes a migration of flat outputs into blobs
+type MigrationReport struct {
+	DryRun       bool
+	Files        int
+	Bytes        int64
+	Deduplicated int // Files whose content was already stored
+	Errors       []string
+}
+
+// NewContentStore creates a content store on blobs with its index in file.
+// Uploads are spooled to tempDir while they are hashed and rejected with
+// models.ErrFileTooLarge beyond maxSize bytes.
+func NewContentStore(blobs Backend, tempDir, file string, maxSize int64) (*ContentStore, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	s := &ContentStore{
+		blobs:     blobs,
+		tempDir:   tempDir,
+		file:      file,
+		maxSize:   maxSize,
+		entries:   make(map[string]*indexEntry),
+		refs:      make(map[string]int),
+		ownerSize: make(map[string]int64),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put stores the object under its content hash and maps key to it. The
+// output is accounted to the owner set on ctx with WithOwner.
+func (s *ContentStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	name, err := outputName(key)
+	if err != nil {
+		return err
+	}
+	if s.maxSize > 0 && size > s.maxSize {
+		return models.ErrFileTooLarge
+	}
+
+	content, err := spool(s.tempDir, r, s.maxSize)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	return s.store(ctx, name, content, contentType, OwnerFrom(ctx), time.Now())
+}
+
+// Get opens the object mapped to key
+func (s *ContentStore) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	name, err := outputName(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entry, indexed := s.lookup(name)
+	if !indexed {
+		return s.blobs.Get(ctx, name)
+	}
+	reader, info, err := s.blobs.Get(ctx, entry.Blob)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, entry.objectInfo(info), nil
+}
+
+// Stat returns the metadata of the object mapped to key
+func (s *ContentStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	name, err := outputName(key)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, indexed := s.lookup(name)
+	if !indexed {
+		return s.blobs.Stat(ctx, name)
+	}
+	info, err := s.blobs.Stat(ctx, entry.Blob)
+	if err != nil {
+		return nil, err
+	}
+	return entry.objectInfo(info), nil
+}
+
+// Delete unmaps key and removes its blob once nothing else references it
+func (s *ContentStore) Delete(ctx context.Context, key string) error {
+	name, err := outputName(key)
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	entry, indexed := s.lookup(name)
+	if !indexed {
+		return s.blobs.Delete(ctx, name)
+	}
+
+	if err := s.append(&indexEntry{Name: name, CreatedAt: time.Now(), Deleted: true}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.entries, name)
+	unreferenced := s.release(entry)
+	s.mu.Unlock()
+
+	if unreferenced {
+		return s.deleteBlob(ctx, entry.Blob)
+	}
+	return nil
+}
+
+// List calls fn for every indexed output, then for every flat object that
+// has not been migrated yet
+func (s *ContentStore) List(ctx context.Context, fn func(ObjectInfo) error) error {
+	s.mu.RLock()
+	objects := make([]ObjectInfo, 0, len(s.entries))
+	for _, entry := range s.entries {
+		objects = append(objects, *entry.objectInfo(nil))
+	}
+	s.mu.RUnlock()
+
+	for _, object := range objects {
+		if err := fn(object); err != nil {
+			return err
+		}
+	}
+
+	return s.blobs.List(ctx, func(object ObjectInfo) error {
+		if isBlob(object.Key) {
+			return nil
+		}
+		return fn(object)
+	})
+}
+
+// PresignGet returns a time-limited link to the blob mapped to key
+func (s *ContentStore) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	name, err := outputName(key)
+	if err != nil {
+		return "", err
+	}
+
+	blob, _ := s.Resolve(name)
+	return s.blobs.PresignGet(ctx, blob, expiry)
+}
+
+// Size returns the total size of the stored blobs
+func (s *ContentStore) Size() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.blobBytes
+}
+
+// OwnerSize returns the total size of the outputs stored for owner. Outputs
+// shared with other owners count in full for each.
+func (s *ContentStore) OwnerSize(owner string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.ownerSize[owner]
+}
+
+// Resolve returns the key on the underlying backend that holds the output,
+// and whether the output is content-addressed
+func (s *ContentStore) Resolve(name string) (string, bool) {
+	entry, indexed := s.lookup(name)
+	if !indexed {
+		return name, false
+	}
+	return entry.Blob, true
+}
+
+// Migrate moves flat objects written before content addressing into blobs,
+// keeping their names and modification times. In a dry run nothing changes.
+func (s *ContentStore) Migrate(ctx context.Context, dryRun bool) (*MigrationReport, error) {
+	var flat []ObjectInfo
+	err := s.blobs.List(ctx, func(object ObjectInfo) error {
+		if _, indexed := s.lookup(object.Key); !isBlob(object.Key) && !indexed {
+			flat = append(flat, object)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outputs: %w", err)
+	}
+
+	report := &MigrationReport{DryRun: dryRun}
+	seen := make(map[string]bool) // Blobs a dry run would have stored
+	for _, object := range flat {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		duplicate, err := s.migrate(ctx, object, dryRun, seen)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", object.Key, err))
+			continue
+		}
+		report.Files++
+		report.Bytes += object.Size
+		if duplicate {
+			report.Deduplicated++
+		}
+	}
+	return report, nil
+}
+
+// migrate moves a single flat object into a blob and reports whether its
+// content was already stored
+func (s *ContentStore) migrate(ctx context.Context, object ObjectInfo, dryRun bool, seen map[string]bool) (bool, error) {
+	reader, _, err := s.blobs.Get(ctx, object.Key)
+	if err != nil {
+		return false, err
+	}
+	content, err := spool(s.tempDir, reader, 0)
+	reader.Close()
+	if err != nil {
+		return false, err
+	}
+	defer content.Close()
+
+	blob := blobKey(content.hash, object.Key)
+	s.mu.RLock()
+	duplicate := s.refs[blob] > 0 || seen[blob]
+	s.mu.RUnlock()
+
+	if dryRun {
+		seen[blob] = true
+		return duplicate, nil
+	}
+
+	if err := s.store(ctx, object.Key, content, object.ContentType, "", object.ModifiedAt); err != nil {
+		return false, err
+	}
+	return duplicate, s.blobs.Delete(ctx, object.Key)
+}
+
+// store uploads the spooled content unless its blob already exists and maps
+// name to it
+func (s *ContentStore) store(ctx context.Context, name string, content *spooled, contentType, owner string, createdAt time.Time) error {
+	blob := blobKey(content.hash, name)
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	exists := s.refs[blob] > 0
+	s.mu.RUnlock()
+
+	if !exists {
+		if _, err := content.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := s.blobs.Put(ctx, blob, content.file, content.size, contentType); err != nil {
+			return err
+		}
+	}
+
+	entry := &indexEntry{
+		Name:        name,
+		Blob:        blob,
+		Size:        content.size,
+		ContentType: contentType,
+		Owner:       owner,
+		CreatedAt:   createdAt,
+	}
+	if err := s.append(entry); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	previous, replaced := s.entries[name]
+	s.entries[name] = entry
+	s.retain(entry)
+	unreferenced := replaced && s.release(previous)
+	s.mu.Unlock()
+
+	if unreferenced {
+		return s.deleteBlob(ctx, previous.Blob)
+	}
+	return nil
+}
+
+// lookup returns the index entry of name
+func (s *ContentStore) lookup(name string) (*indexEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, exists := s.entries[name]
+	return entry, exists
+}
+
+// retain adds the entry's reference to its blob, caller must hold the lock
+func (s *ContentStore) retain(entry *indexEntry) {
+	if s.refs[entry.Blob] == 0 {
+		s.blobBytes += entry.Size
+	}
+	s.refs[entry.Blob]++
+	if entry.Owner != "" {
+		s.ownerSize[entry.Owner] += entry.Size
+	}
+}
+
+// release drops the entry's reference to its blob and reports whether it was
+// the last, caller must hold the lock
+func (s *ContentStore) release(entry *indexEntry) bool {
+	if entry.Owner != "" {
+		s.ownerSize[entry.Owner] -= entry.Size
+		if s.ownerSize[entry.Owner] <= 0 {
+			delete(s.ownerSize, entry.Owner)
+		}
+	}
+	s.refs[entry.Blob]--
+	if s.refs[entry.Blob] > 0 {
+		return false
+	}
+	delete(s.refs, entry.Blob)
+	s.blobBytes -= entry.Size
+	return true
+}
+
+// deleteBlob removes an unreferenced blob
+func (s *ContentStore) deleteBlob(ctx context.Context, blob string) error {
+	if err := s.blobs.Delete(ctx, blob); err != nil && !errors.Is(err, models.ErrFileNotFound) {
+		return fmt.Errorf("failed to delete blob %s: %w", blob, err)
+	}
+	return nil
+}
+
+// append writes an entry to the index file
+func (s *ContentStore) append(entry *indexEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open output index: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write output index: %w", err)
+	}
+	return nil
+}
+
+// load replays the index file and compacts it when most of its lines are
+// superseded. A torn last line from a crash is ignored.
+func (s *ContentStore) load() error {
+	file, err := os.Open(s.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read output index: %w", err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+		var entry indexEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Name == "" {
+			continue
+		}
+		if entry.Deleted {
+			delete(s.entries, entry.Name)
+		} else {
+			s.entries[entry.Name] = &entry
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read output index: %w", err)
+	}
+
+	for _, entry := range s.entries {
+		s.retain(entry)
+	}
+
+	if lines > 2*len(s.entries)+1024 {
+		return s.compact()
+	}
+	return nil
+}
+
+// compact rewrites the index file with only the live entries
+func (s *ContentStore) compact() error {
+	names := make([]string, 0, len(s.entries))
+	for name := range s.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tmp := s.file + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to compact output index: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, name := range names {
+		if err := encoder.Encode(s.entries[name]); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to compact output index: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to compact output index: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to compact output index: %w", err)
+	}
+	return os.Rename(tmp, s.file)
+}
+
+// objectInfo describes the output, using the blob's metadata when given
+func (e *indexEntry) objectInfo(blob *ObjectInfo) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         e.Name,
+		Size:        e.Size,
+		ContentType: e.ContentType,
+		ModifiedAt:  e.CreatedAt,
+		ETag:        strings.TrimSuffix(path.Base(e.Blob), path.Ext(e.Blob)),
+		Owner:       e.Owner,
+	}
+	if blob != nil && info.ContentType == "" {
+		info.ContentType = blob.ContentType
+	}
+	return info
+}
+
+// spooled is content written to a temp file while it was hashed
+type spooled struct {
+	file *os.File
+	hash string
+	size int64
+}
+
+// spool copies r to a temp file in dir and hashes it. Content beyond
+// maxSize bytes is rejected, 0 means no limit.
+func spool(dir string, r io.Reader, maxSize int64) (*spooled, error) {
+	file, err := os.CreateTemp(dir, ".blob-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	if maxSize > 0 {
+		r = io.LimitReader(r, maxSize+1)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), r)
+	if err == nil && maxSize > 0 && size > maxSize {
+		err = models.ErrFileTooLarge
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		if errors.Is(err, models.ErrFileTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	return &spooled{file: file, hash: hex.EncodeToString(hash.Sum(nil)), size: size}, nil
+}
+
+// Close removes the temp file
+func (c *spooled) Close() error {
+	c.file.Close()
+	return os.Remove(c.file.Name())
+}
+
+// blobKey returns the sharded key of a blob, keeping the name's extension
+func blobKey(hash, name string) string {
+	return blobPrefix + hash[:2] + "/" + hash[2:4] + "/" + hash + strings.ToLower(path.Ext(name))
+}
+
+// isBlob reports whether key is a blob rather than a flat object
+func isBlob(key string) bool {
+	return strings.HasPrefix(key, blobPrefix)
+}
+
+// outputName validates an output name. Blob keys are not output names.
+func outputName(key string) (string, error) {
+	name, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if isBlob(name) {
+		return "", models.ErrInvalidFileName
+	}
+	return name, nil
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// newContentStore creates a content store on a local backend in a temp directory
+func newContentStore(t *testing.T) (*ContentStore, *LocalBackend) {
+	t.Helper()
+	dir := t.TempDir()
+	backend, err := NewLocalBackend(filepath.Join(dir, "outputs"))
+	if err != nil {
+		t.Fatalf("NewLocalBackend: %v", err)
+	}
+	s, err := NewContentStore(backend, t.TempDir(), filepath.Join(dir, "outputs.index"), 0)
+	if err != nil {
+		t.Fatalf("NewContentStore: %v", err)
+	}
+	return s, backend
+}
+
+// objects returns the blob and flat object counts of the backend
+func objects(t *testing.T, backend Backend) (blobs, flat int) {
+	t.Helper()
+	err := backend.List(context.Background(), func(object ObjectInfo) error {
+		if isBlob(object.Key) {
+			blobs++
+		} else {
+			flat++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	return blobs, flat
+}
+
+// read returns the content of the output name
+func read(t *testing.T, s *ContentStore, name string) string {
+	t.Helper()
+	r, _, err := s.Get(context.Background(), name)
+	if err != nil {
+		t.Fatalf("Get %s: %v", name, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestContentStoreReferences(t *testing.T) {
+	s, backend := newContentStore(t)
+
+	// Each step changes the store, then the blobs on the backend, the size
+	// of the referenced blobs and key-1's size are checked
+	steps := []struct {
+		desc      string
+		put       string // Output to write with data, empty to delete del
+		data      string
+		owner     string
+		del       string
+		blobs     int
+		size      int64
+		ownerSize int64
+	}{
+		{desc: "first write stores a blob", put: "a.png", data: "same bytes", owner: "key-1", blobs: 1, size: 10, ownerSize: 10},
+		{desc: "same bytes share the blob", put: "b.png", data: "same bytes", owner: "key-2", blobs: 1, size: 10, ownerSize: 10},
+		{desc: "other bytes get a blob", put: "c.png", data: "other", owner: "key-1", blobs: 2, size: 15, ownerSize: 15},
+		{desc: "deleting one reference keeps the blob", del: "a.png", blobs: 2, size: 15, ownerSize: 5},
+		{desc: "deleting the last reference removes the blob", del: "b.png", blobs: 1, size: 5, ownerSize: 5},
+		{desc: "overwriting releases the old blob", put: "c.png", data: "replaced", owner: "key-1", blobs: 1, size: 8, ownerSize: 8},
+	}
+
+	for _, step := range steps {
+		ctx := WithOwner(context.Background(), step.owner)
+		if step.put != "" {
+			if err := s.Put(ctx, step.put, strings.NewReader(step.data), int64(len(step.data)), "image/png"); err != nil {
+				t.Fatalf("%s: Put %s: %v", step.desc, step.put, err)
+			}
+			if got := read(t, s, step.put); got != step.data {
+				t.Errorf("%s: Get %s = %q, want %q", step.desc, step.put, got, step.data)
+			}
+		} else if err := s.Delete(ctx, step.del); err != nil {
+			t.Fatalf("%s: Delete %s: %v", step.desc, step.del, err)
+		}
+
+		if blobs, _ := objects(t, backend); blobs != step.blobs {
+			t.Errorf("%s: %d blobs, want %d", step.desc, blobs, step.blobs)
+		}
+		if size := s.Size(); size != step.size {
+			t.Errorf("%s: Size = %d, want %d", step.desc, size, step.size)
+		}
+		if size := s.OwnerSize("key-1"); size != step.ownerSize {
+			t.Errorf("%s: OwnerSize(key-1) = %d, want %d", step.desc, size, step.ownerSize)
+		}
+	}
+
+	if _, err := s.Stat(context.Background(), "b.png"); !errors.Is(err, models.ErrFileNotFound) {
+		t.Errorf("Stat of a deleted output = %v, want ErrFileNotFound", err)
+	}
+
+	// The index replays to the same references
+	reopened, err := NewContentStore(backend, t.TempDir(), s.file, 0)
+	if err != nil {
+		t.Fatalf("NewContentStore: %v", err)
+	}
+	if reopened.Size() != s.Size() || reopened.OwnerSize("key-1") != s.OwnerSize("key-1") {
+		t.Errorf("reopened sizes = %d, %d, want %d, %d", reopened.Size(), reopened.OwnerSize("key-1"), s.Size(), s.OwnerSize("key-1"))
+	}
+	if got := read(t, reopened, "c.png"); got != "replaced" {
+		t.Errorf("reopened Get c.png = %q, want %q", got, "replaced")
+	}
+}
+
+func TestContentStoreMigrate(t *testing.T) {
+	s, backend := newContentStore(t)
+	ctx := context.Background()
+
+	// Flat outputs written before content addressing, two of them identical
+	flat := map[string]string{"a.png": "same bytes", "b.png": "same bytes", "c.png": "other"}
+	for name, data := range flat {
+		if err := backend.Put(ctx, name, strings.NewReader(data), int64(len(data)), "image/png"); err != nil {
+			t.Fatalf("Put %s: %v", name, err)
+		}
+	}
+
+	runs := []struct {
+		desc         string
+		dryRun       bool
+		files        int
+		deduplicated int
+		blobs        int
+		flat         int
+	}{
+		{desc: "dry run", dryRun: true, files: 3, deduplicated: 1, blobs: 0, flat: 3},
+		{desc: "migration", files: 3, deduplicated: 1, blobs: 2, flat: 0},
+		{desc: "second migration", files: 0, deduplicated: 0, blobs: 2, flat: 0},
+	}
+
+	for _, run := range runs {
+		report, err := s.Migrate(ctx, run.dryRun)
+		if err != nil {
+			t.Fatalf("%s: Migrate: %v", run.desc, err)
+		}
+		if report.Files != run.files || report.Deduplicated != run.deduplicated || len(report.Errors) != 0 {
+			t.Errorf("%s: report = %+v, want %d files, %d deduplicated", run.desc, report, run.files, run.deduplicated)
+		}
+		if blobs, flatCount := objects(t, backend); blobs != run.blobs || flatCount != run.flat {
+			t.Errorf("%s: %d blobs and %d flat objects, want %d and %d", run.desc, blobs, flatCount, run.blobs, run.flat)
+		}
+		for name, data := range flat {
+			if got := read(t, s, name); got != data {
+				t.Errorf("%s: Get %s = %q, want %q", run.desc, name, got, data)
+			}
+		}
+	}
+
+	if size := s.Size(); size != int64(len("same bytes")+len("other")) {
+		t.Errorf("Size = %d, want %d", size, len("same bytes")+len("other"))
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package storage
+
+// freeSpace is not measured on this platform, -1 disables the free space floor
+func freeSpace(dir string) (int64, error) {
+	return -1, nil
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/imaging"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// PublishedOutput describes an output moved into storage
+type PublishedOutput struct {
+	Name       string // Differs from the staged name when the output was transcoded
+	Thumbnails []models.Thumbnail
+}
+
+// Variant selects a resized or transcoded copy of an output
+type Variant struct {
+	Width   int                // 0 keeps the original width
+	Format  models.ImageFormat // Empty keeps the original format
+	Quality int                // 1-100, 0 for the format's default
+}
+
+// OpenVariant opens a resized or transcoded copy of an output. Copies are
+// rendered on first use and cached in the temp directory, where the
+// janitor's temp TTL expires those that are no longer requested.
+func (m *StorageManager) OpenVariant(ctx context.Context, name string, variant Variant) (io.ReadCloser, *ObjectInfo, error) {
+	source, err := m.outputs.Stat(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if variant.Format == "" {
+		format, ok := imaging.FormatOf(name)
+		if !ok {
+			format = models.FormatPNG
+		}
+		variant.Format = format
+	}
+	if !imaging.Supported(variant.Format) {
+		return nil, nil, fmt.Errorf("%w: %s is not available", models.ErrInvalidOutputFormat, variant.Format)
+	}
+
+	etag := variantETag(source, variant)
+	cachePath := filepath.Join(m.config.TempDir, "variants", etag+imaging.Extension(variant.Format))
+	info := &ObjectInfo{
+		Key:         name,
+		ContentType: imaging.ContentType(variant.Format),
+		ModifiedAt:  source.ModifiedAt,
+		ETag:        etag,
+	}
+
+	file, err := os.Open(cachePath)
+	if os.IsNotExist(err) {
+		if err := m.renderVariant(ctx, name, variant, cachePath); err != nil {
+			return nil, nil, err
+		}
+		file, err = os.Open(cachePath)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	info.Size = stat.Size()
+
+	// Keep copies that are still requested from expiring
+	now := time.Now()
+	os.Chtimes(cachePath, now, now)
+	return file, info, nil
+}
+
+// renderVariant writes the variant of an output to cachePath
+func (m *StorageManager) renderVariant(ctx context.Context, name string, variant Variant, cachePath string) error {
+	reader, _, err := m.outputs.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	img, _, err := imaging.Decode(reader)
+	reader.Close()
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupportedFormat) {
+			return fmt.Errorf("%w: %s cannot be resized", models.ErrInvalidOutputFormat, name)
+		}
+		return fmt.Errorf("failed to decode output: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), ".variant-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := imaging.Encode(tmp, imaging.Resize(img, variant.Width), variant.Format, variant.Quality); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cachePath)
+}
+
+// transcode stores img in format under the output's name with the format's
+// extension. On failure the original name is returned with the error.
+func (m *StorageManager) transcode(ctx context.Context, name string, img image.Image, format models.ImageFormat, quality int) (string, error) {
+	transcoded := stem(name) + imaging.Extension(format)
+	if err := m.writeImage(ctx, transcoded, img, format, quality); err != nil {
+		return name, fmt.Errorf("failed to transcode output to %s: %w", format, err)
+	}
+	return transcoded, nil
+}
+
+// writeThumbnails stores a thumbnail of img for every configured width.
+// Thumbnails are named <stem>_<width>w<ext> next to the output.
+func (m *StorageManager) writeThumbnails(ctx context.Context, name string, img image.Image) ([]models.Thumbnail, error) {
+	cfg := m.config.Thumbnails
+	format, ok := models.ParseImageFormat(cfg.Format)
+	if !ok || !imaging.Supported(format) {
+		format = models.FormatJPEG
+	}
+
+	var thumbnails []models.Thumbnail
+	for _, width := range cfg.Sizes {
+		if width <= 0 {
+			continue
+		}
+		thumbnail := imaging.Resize(img, width)
+		thumbName := stem(name) + "_" + strconv.Itoa(width) + "w" + imaging.Extension(format)
+		if err := m.writeImage(ctx, thumbName, thumbnail, format, cfg.Quality); err != nil {
+			return thumbnails, fmt.Errorf("failed to create thumbnail %s: %w", thumbName, err)
+		}
+		bounds := thumbnail.Bounds()
+		thumbnails = append(thumbnails, models.Thumbnail{
+			Width:     bounds.Dx(),
+			Height:    bounds.Dy(),
+			ImagePath: thumbName,
+			ImageURL:  m.OutputPath(thumbName),
+		})
+	}
+	return thumbnails, nil
+}
+
+// writeImage encodes img and writes it as an output
+func (m *StorageManager) writeImage(ctx context.Context, name string, img image.Image, format models.ImageFormat, quality int) error {
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format, quality); err != nil {
+		return err
+	}
+	return m.WriteOutput(ctx, name, &buf, int64(buf.Len()), imaging.ContentType(format))
+}
+
+// variantETag identifies a variant of a specific version of an output
+func variantETag(source *ObjectInfo, variant Variant) string {
+	version := source.ETag
+	if version == "" {
+		version = fmt.Sprintf("%x-%x", source.ModifiedAt.UnixNano(), source.Size)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s|%d", source.Key, version, variant.Width, variant.Format, variant.Quality)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// stem returns name without its extension
+func stem(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name))
+}
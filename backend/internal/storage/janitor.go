@@ -0,0 +1,307 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// ReferenceFunc returns the output file names that are still referenced,
+// mapped to whether they are protected from age and size limits
+type ReferenceFunc func() map[string]bool
+
+// Janitor interface for retention and cleanup operations
+type Janitor interface {
+	Start(ctx context.Context)
+	Run(dryRun bool) (*models.CleanupReport, error)
+	Stats() models.CleanupStats
+}
+
+// RetentionJanitor implements the Janitor interface.
+// It applies the retention policy to the output and temp directories on a
+// schedule and on request.
+type RetentionJanitor struct {
+	config     config.StorageConfig
+	outputs    Backend
+	references ReferenceFunc
+	logger     *logrus.Logger
+
+	runMu sync.Mutex // Serialises runs
+
+	mu    sync.RWMutex
+	stats models.CleanupStats
+}
+
+// fileInfo is a candidate file for cleanup, either a temp file path or an
+// output key
+type fileInfo struct {
+	path    string
+	name    string
+	size    int64
+	modTime time.Time
+	output  bool
+}
+
+// NewJanitor creates a new storage janitor for the given output backend.
+// references may be nil, in which case no output is protected and orphan
+// detection is disabled.
+func NewJanitor(cfg config.StorageConfig, outputs Backend, references ReferenceFunc, logger *logrus.Logger) Janitor {
+	return &RetentionJanitor{
+		config:     cfg,
+		outputs:    outputs,
+		references: references,
+		logger:     logger,
+		stats:      models.CleanupStats{ByReason: make(map[models.CleanupReason]int)},
+	}
+}
+
+// Start runs the janitor every retention interval until ctx is done
+func (j *RetentionJanitor) Start(ctx context.Context) {
+	interval := time.Duration(j.config.Retention.Interval) * time.Second
+	if interval <= 0 {
+		j.logger.Info("Scheduled storage cleanup disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := j.Run(j.config.Retention.DryRun); err != nil {
+					j.logger.WithError(err).Error("Scheduled storage cleanup failed")
+				}
+			}
+		}
+	}()
+
+	j.logger.WithField("interval", interval).Info("Storage janitor started")
+}
+
+// Run applies the retention policy once. In a dry run nothing is removed and
+// the report lists what would have been.
+func (j *RetentionJanitor) Run(dryRun bool) (*models.CleanupReport, error) {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	report := &models.CleanupReport{
+		DryRun:    dryRun,
+		StartedAt: time.Now(),
+		Items:     []models.CleanupItem{},
+	}
+
+	temp, err := listFiles(j.config.TempDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+	var outputs []fileInfo
+	err = j.outputs.List(context.Background(), func(object ObjectInfo) error {
+		outputs = append(outputs, fileInfo{
+			path:    object.Key,
+			name:    path.Base(object.Key),
+			size:    object.Size,
+			modTime: object.ModifiedAt,
+			output:  true,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outputs: %w", err)
+	}
+
+	candidates := j.selectTemp(temp, report.StartedAt)
+	candidates = append(candidates, j.selectOutputs(outputs, report.StartedAt)...)
+
+	for _, file := range candidates {
+		if !dryRun {
+			if err := j.remove(file.fileInfo); err != nil && !errors.Is(err, models.ErrFileNotFound) {
+				report.Errors = append(report.Errors, err.Error())
+				continue
+			}
+			report.FilesRemoved++
+			report.BytesReclaimed += file.size
+		}
+		report.Items = append(report.Items, file.item())
+	}
+	report.CompletedAt = time.Now()
+
+	j.record(report)
+
+	j.logger.WithFields(logrus.Fields{
+		"dry_run":         dryRun,
+		"candidates":      len(report.Items),
+		"files_removed":   report.FilesRemoved,
+		"bytes_reclaimed": report.BytesReclaimed,
+		"errors":          len(report.Errors),
+	}).Info("Storage cleanup finished")
+
+	return report, nil
+}
+
+// Stats returns the cumulative cleanup metrics
+func (j *RetentionJanitor) Stats() models.CleanupStats {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	stats := j.stats
+	stats.ByReason = make(map[models.CleanupReason]int, len(j.stats.ByReason))
+	for reason, count := range j.stats.ByReason {
+		stats.ByReason[reason] = count
+	}
+	return stats
+}
+
+// record adds a report to the metrics
+func (j *RetentionJanitor) record(report *models.CleanupReport) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.stats.Runs++
+	j.stats.LastRun = report
+	if report.DryRun {
+		return
+	}
+	j.stats.FilesRemoved += report.FilesRemoved
+	j.stats.BytesReclaimed += report.BytesReclaimed
+	for _, item := range report.Items {
+		j.stats.ByReason[item.Reason]++
+	}
+}
+
+// remove deletes a temp file or an output
+func (j *RetentionJanitor) remove(file fileInfo) error {
+	if file.output {
+		return j.outputs.Delete(context.Background(), file.path)
+	}
+	if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// candidate is a file selected for removal
+type candidate struct {
+	fileInfo
+	reason models.CleanupReason
+}
+
+// selectTemp returns the temp files past their TTL
+func (j *RetentionJanitor) selectTemp(files []fileInfo, now time.Time) []candidate {
+	ttl := time.Duration(j.config.Retention.TempTTL) * time.Second
+	if ttl <= 0 {
+		return nil
+	}
+
+	var items []candidate
+	for _, file := range files {
+		if now.Sub(file.modTime) > ttl {
+			items = append(items, candidate{file, models.CleanupTempExpired})
+		}
+	}
+	return items
+}
+
+// selectOutputs returns the outputs to remove: orphans first, then expired
+// files, then the oldest files until the directory fits the size limit.
+// Protected files are only ever removed as orphans, which they cannot be.
+func (j *RetentionJanitor) selectOutputs(files []fileInfo, now time.Time) []candidate {
+	policy := j.config.Retention
+	maxAge := time.Duration(policy.MaxAge) * time.Second
+	orphanGrace := time.Duration(policy.OrphanGrace) * time.Second
+
+	var refs map[string]bool
+	if j.references != nil {
+		refs = j.references()
+	}
+
+	// Oldest first so the size limit removes the oldest files
+	sort.Slice(files, func(a, b int) bool {
+		return files[a].modTime.Before(files[b].modTime)
+	})
+
+	var items []candidate
+	var kept []fileInfo
+	var keptSize int64
+	for _, file := range files {
+		protected, referenced := refs[file.name]
+		age := now.Sub(file.modTime)
+
+		switch {
+		case policy.RemoveOrphans && refs != nil && !referenced && age > orphanGrace:
+			items = append(items, candidate{file, models.CleanupOrphaned})
+		case !protected && maxAge > 0 && age > maxAge:
+			items = append(items, candidate{file, models.CleanupExpired})
+		default:
+			kept = append(kept, file)
+			keptSize += file.size
+		}
+	}
+
+	if policy.MaxTotalSize <= 0 {
+		return items
+	}
+	for _, file := range kept {
+		if keptSize <= policy.MaxTotalSize {
+			break
+		}
+		if refs[file.name] {
+			continue
+		}
+		items = append(items, candidate{file, models.CleanupOverSize})
+		keptSize -= file.size
+	}
+	return items
+}
+
+// item converts the candidate to a report item. Outputs are reported by
+// their /outputs path so the report reads the same for every backend.
+func (c candidate) item() models.CleanupItem {
+	itemPath := c.path
+	if c.output {
+		itemPath = "outputs/" + c.path
+	}
+	return models.CleanupItem{
+		Path:       itemPath,
+		Size:       c.size,
+		ModifiedAt: c.modTime,
+		Reason:     c.reason,
+	}
+}
+
+// listFiles returns the regular files below dir
+func listFiles(dir string) ([]fileInfo, error) {
+	var files []fileInfo
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		files = append(files, fileInfo{
+			path:    path,
+			name:    info.Name(),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	return files, err
+}
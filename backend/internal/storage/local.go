@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// uploadPrefix names partially written files, which are not listed
+const uploadPrefix = ".upload-"
+
+// LocalBackend implements the Backend interface on a local directory
+type LocalBackend struct {
+	root string
+}
+
+// NewLocalBackend creates a backend storing objects below root
+func NewLocalBackend(root string) (*LocalBackend, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", root, err)
+	}
+	return &LocalBackend{root: root}, nil
+}
+
+// Path returns the file path of an object
+func (b *LocalBackend) Path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(b.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object atomically
+func (b *LocalBackend) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	filePath, err := b.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), uploadPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// Get opens the object for reading
+func (b *LocalBackend) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	filePath, err := b.Path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, localError(err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, localError(err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, models.ErrFileNotFound
+	}
+	return file, objectInfo(key, info), nil
+}
+
+// Stat returns the object's metadata
+func (b *LocalBackend) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	filePath, err := b.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, localError(err)
+	}
+	if info.IsDir() {
+		return nil, models.ErrFileNotFound
+	}
+	return objectInfo(key, info), nil
+}
+
+// Delete removes the object
+func (b *LocalBackend) Delete(ctx context.Context, key string) error {
+	filePath, err := b.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil {
+		return localError(err)
+	}
+	return nil
+}
+
+// List calls fn for every object
+func (b *LocalBackend) List(ctx context.Context, fn func(ObjectInfo) error) error {
+	return filepath.WalkDir(b.root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), uploadPrefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, filePath)
+		if err != nil {
+			return err
+		}
+		return fn(*objectInfo(filepath.ToSlash(rel), info))
+	})
+}
+
+// PresignGet is not supported by local storage
+func (b *LocalBackend) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", nil
+}
+
+// objectInfo converts file info to object info
+func objectInfo(key string, info os.FileInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ModifiedAt:  info.ModTime(),
+	}
+}
+
+// localError maps file system errors to storage errors
+func localError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return models.ErrFileNotFound
+	}
+	return err
+}
@@ -1,13 +1,20 @@
 package storage
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/imaging"
 	"github.com/ablerefusal/ablerefusal/internal/models"
 )
 
@@ -18,11 +25,31 @@ type Manager interface {
 	GetModelPath(modelName string) (string, error)
 	CleanupTemp() error
 	GetStorageStats() (*StorageStats, error)
+	CheckQuota(owner string) error
+
+	// Streaming access to outputs on the configured backend
+	WriteOutput(ctx context.Context, name string, r io.Reader, size int64, contentType string) error
+	OpenOutput(ctx context.Context, name string) (io.ReadCloser, *ObjectInfo, error)
+	DeleteOutput(ctx context.Context, name string) error
+	OutputURL(ctx context.Context, name string) (string, error)
+	OutputPath(name string) string
+	AuthorizeOutput(ctx context.Context, name string, query url.Values, owner string) error
+	PublishOutput(ctx context.Context, name string, format models.ImageFormat, quality int) (*PublishedOutput, error)
+	OpenVariant(ctx context.Context, name string, variant Variant) (io.ReadCloser, *ObjectInfo, error)
+	MigrateOutputs(ctx context.Context, dryRun bool) (*MigrationReport, error)
+	Outputs() Backend
 }
 
-// StorageManager implements the Manager interface
+// StorageManager implements the Manager interface.
+// Outputs are content-addressed on the configured backend.
 type StorageManager struct {
-	config config.StorageConfig
+	config  config.StorageConfig
+	backend Backend
+	outputs *ContentStore
+	signer  *URLSigner // nil when outputs are public
+
+	usageMu sync.Mutex
+	usage   usage
 }
 
 // StorageStats represents storage statistics
@@ -31,10 +58,11 @@ type StorageStats struct {
 	ModelsDirSize int64
 	TempDirSize   int64
 	TotalSize     int64
+	ScannedAt     time.Time // When the models and temp directories were last measured
 }
 
 // NewManager creates a new storage manager
-func NewManager(config config.StorageConfig) (Manager, error) {
+func NewManager(config config.StorageConfig, auth config.AuthConfig) (Manager, error) {
 	// Ensure directories exist
 	dirs := []string{
 		config.OutputDir,
@@ -48,28 +76,214 @@ func NewManager(config config.StorageConfig) (Manager, error) {
 		}
 	}
 
-	return &StorageManager{
-		config: config,
-	}, nil
+	backend, err := NewBackend(config)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := NewContentStore(backend, config.TempDir, filepath.Join(config.DataDir, "outputs.index"), config.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := NewURLSigner(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &StorageManager{
+		config:  config,
+		backend: backend,
+		outputs: outputs,
+		signer:  signer,
+	}
+	if err := m.rescan(); err != nil {
+		return nil, fmt.Errorf("failed to measure storage: %w", err)
+	}
+	return m, nil
 }
 
-// SaveImage saves an image to the output directory
+// SaveImage saves an image to the output backend
 func (m *StorageManager) SaveImage(id string, data []byte) (string, error) {
 	// Generate filename with timestamp
 	timestamp := time.Now().Format("20060102_150405")
 	filename := fmt.Sprintf("%s_%s.png", id, timestamp)
-	filePath := filepath.Join(m.config.OutputDir, filename)
 
 	// Write file
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := m.WriteOutput(context.Background(), filename, bytes.NewReader(data), int64(len(data)), "image/png"); err != nil {
 		return "", fmt.Errorf("failed to save image: %w", err)
 	}
 
 	return filename, nil
 }
 
-// GetOutputPath returns the full path for an output file
+// WriteOutput streams an output to the backend. Outputs that would pass the
+// file size limit, a quota or the free space floor are rejected.
+func (m *StorageManager) WriteOutput(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	if err := m.checkFileSize(size); err != nil {
+		return err
+	}
+	if err := m.checkSpace(OwnerFrom(ctx), max(size, 0)); err != nil {
+		return err
+	}
+	return m.outputs.Put(ctx, name, r, size, contentType)
+}
+
+// OpenOutput opens an output for reading
+func (m *StorageManager) OpenOutput(ctx context.Context, name string) (io.ReadCloser, *ObjectInfo, error) {
+	return m.outputs.Get(ctx, name)
+}
+
+// DeleteOutput removes an output
+func (m *StorageManager) DeleteOutput(ctx context.Context, name string) error {
+	return m.outputs.Delete(ctx, name)
+}
+
+// OutputURL returns the URL clients fetch an output from: a presigned link
+// in presign mode when the backend supports it, otherwise the proxied path
+func (m *StorageManager) OutputURL(ctx context.Context, name string) (string, error) {
+	key, err := cleanKey(name)
+	if err != nil {
+		return "", err
+	}
+	if m.config.URLMode == URLModePresign {
+		expiry := time.Duration(m.config.PresignExpiry) * time.Second
+		link, err := m.outputs.PresignGet(ctx, key, expiry)
+		if err != nil {
+			return "", err
+		}
+		if link != "" {
+			return link, nil
+		}
+	}
+	return m.OutputPath(key), nil
+}
+
+// OutputPath returns the path this server serves an output from, signed
+// when auth requires it
+func (m *StorageManager) OutputPath(name string) string {
+	if m.signer != nil {
+		return m.signer.Sign(name)
+	}
+	return "/outputs/" + name
+}
+
+// AuthorizeOutput checks that an output may be served: outputs are public
+// unless auth requires signed URLs, otherwise query must carry a valid
+// signature or owner must be the API key the output was stored for.
+func (m *StorageManager) AuthorizeOutput(ctx context.Context, name string, query url.Values, owner string) error {
+	if m.signer == nil {
+		return nil
+	}
+	if query.Get(SignatureParam) != "" {
+		return m.signer.Verify(name, query)
+	}
+	if owner == "" {
+		return fmt.Errorf("%w: output URLs must be signed", models.ErrForbidden)
+	}
+
+	info, err := m.outputs.Stat(ctx, name)
+	if err != nil {
+		return err
+	}
+	if info.Owner != owner {
+		return fmt.Errorf("%w: output belongs to another API key", models.ErrForbidden)
+	}
+	return nil
+}
+
+// PublishOutput moves an output written to the output directory by the
+// inference service into content-addressed storage on the backend, encoded
+// in format unless that is empty, and creates its thumbnails. Outputs
+// rejected by the file size limit, a quota or the free space floor are
+// discarded. If transcoding or a thumbnail fails, the output is still
+// published and returned along with the error.
+func (m *StorageManager) PublishOutput(ctx context.Context, name string, format models.ImageFormat, quality int) (*PublishedOutput, error) {
+	if _, err := cleanKey(name); err != nil {
+		return nil, err
+	}
+	filePath := filepath.Join(m.config.OutputDir, filepath.FromSlash(name))
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, models.ErrFileNotFound
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	err = m.checkFileSize(info.Size())
+	if err == nil {
+		err = m.checkSpace(OwnerFrom(ctx), info.Size())
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(filePath)
+		return nil, err
+	}
+
+	img, source, processErr := imaging.Decode(file)
+	if processErr != nil {
+		processErr = fmt.Errorf("failed to decode output: %w", processErr)
+	}
+
+	published := &PublishedOutput{Name: name}
+	if processErr == nil && format != "" && format != source {
+		published.Name, processErr = m.transcode(ctx, name, img, format, quality)
+	}
+	if published.Name == name {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := m.outputs.Put(ctx, name, file, info.Size(), mime.TypeByExtension(filepath.Ext(name))); err != nil {
+			return nil, err
+		}
+	}
+	if img != nil {
+		thumbnails, err := m.writeThumbnails(ctx, published.Name, img)
+		published.Thumbnails = thumbnails
+		processErr = errors.Join(processErr, err)
+	}
+
+	file.Close()
+	if err := os.Remove(filePath); err != nil {
+		return published, err
+	}
+	return published, processErr
+}
+
+// MigrateOutputs moves flat outputs written before content addressing into
+// content-addressed storage
+func (m *StorageManager) MigrateOutputs(ctx context.Context, dryRun bool) (*MigrationReport, error) {
+	return m.outputs.Migrate(ctx, dryRun)
+}
+
+// Outputs returns the outputs by name
+func (m *StorageManager) Outputs() Backend {
+	return m.outputs
+}
+
+// GetOutputPath returns the full path for an output file. On local storage
+// published outputs resolve to their blob, otherwise only flat files in the
+// output directory are found.
 func (m *StorageManager) GetOutputPath(filename string) (string, error) {
+	if local, ok := m.backend.(*LocalBackend); ok {
+		if blob, indexed := m.outputs.Resolve(filename); indexed {
+			filePath, err := local.Path(blob)
+			if err != nil {
+				return "", err
+			}
+			if _, err := os.Stat(filePath); os.IsNotExist(err) {
+				return "", models.ErrFileNotFound
+			}
+			return filePath, nil
+		}
+	}
+
 	filePath := filepath.Join(m.config.OutputDir, filename)
 	
 	// Check if file exists
@@ -100,8 +314,12 @@ func (m *StorageManager) CleanupTemp() error {
 		return fmt.Errorf("failed to read temp directory: %w", err)
 	}
 
-	// Remove files older than 1 hour
-	cutoff := time.Now().Add(-1 * time.Hour)
+	// Remove files older than the temp TTL, one hour if unset
+	ttl := time.Duration(m.config.Retention.TempTTL) * time.Second
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	cutoff := time.Now().Add(-ttl)
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -121,31 +339,32 @@ func (m *StorageManager) CleanupTemp() error {
 	return nil
 }
 
-// GetStorageStats returns storage statistics
+// GetStorageStats returns storage statistics. Content-addressed outputs are
+// counted as they change; flat outputs and the models and temp directories
+// are rescanned in the background once the last scan is older than the
+// stats refresh interval.
 func (m *StorageManager) GetStorageStats() (*StorageStats, error) {
-	stats := &StorageStats{}
-
-	// Calculate output directory size
-	outputSize, err := getDirSize(m.config.OutputDir)
-	if err != nil {
-		return nil, err
+	m.usageMu.Lock()
+	current := m.usage
+	refresh := time.Duration(m.config.Quota.StatsRefresh) * time.Second
+	if !current.scanning && time.Since(current.scannedAt) >= refresh {
+		m.usage.scanning = true
+		go func() {
+			if err := m.rescan(); err != nil {
+				m.usageMu.Lock()
+				m.usage.scanning = false
+				m.usageMu.Unlock()
+			}
+		}()
 	}
-	stats.OutputDirSize = outputSize
+	m.usageMu.Unlock()
 
-	// Calculate models directory size
-	modelsSize, err := getDirSize(m.config.ModelsDir)
-	if err != nil {
-		return nil, err
+	stats := &StorageStats{
+		OutputDirSize: m.outputs.Size() + current.flatSize,
+		ModelsDirSize: current.modelSize,
+		TempDirSize:   current.tempSize,
+		ScannedAt:     current.scannedAt,
 	}
-	stats.ModelsDirSize = modelsSize
-
-	// Calculate temp directory size
-	tempSize, err := getDirSize(m.config.TempDir)
-	if err != nil {
-		return nil, err
-	}
-	stats.TempDirSize = tempSize
-
 	stats.TotalSize = stats.OutputDirSize + stats.ModelsDirSize + stats.TempDirSize
 
 	return stats, nil
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// ownerKey is the context key of the output owner
+type ownerKey struct{}
+
+// WithOwner returns a context that accounts outputs written with it to owner,
+// the API key ID of the request that produced them
+func WithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// OwnerFrom returns the output owner set on ctx
+func OwnerFrom(ctx context.Context) string {
+	owner, _ := ctx.Value(ownerKey{}).(string)
+	return owner
+}
+
+// CheckQuota returns models.ErrStorageFull if a quota or the free space
+// floor is already reached, so new generations for owner are not queued
+func (m *StorageManager) CheckQuota(owner string) error {
+	return m.checkSpace(owner, 0)
+}
+
+// checkSpace returns models.ErrStorageFull if writing size more bytes of
+// output for owner would pass a quota or the free space floor
+func (m *StorageManager) checkSpace(owner string, size int64) error {
+	quota := m.config.Quota
+	stats, err := m.GetStorageStats()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case exceeds(stats.OutputDirSize, size, quota.MaxOutputSize):
+		return fmt.Errorf("%w: output quota of %d bytes reached", models.ErrStorageFull, quota.MaxOutputSize)
+	case exceeds(stats.TempDirSize, 0, quota.MaxTempSize):
+		return fmt.Errorf("%w: temp quota of %d bytes reached", models.ErrStorageFull, quota.MaxTempSize)
+	case exceeds(stats.TotalSize, size, quota.MaxTotalSize):
+		return fmt.Errorf("%w: storage quota of %d bytes reached", models.ErrStorageFull, quota.MaxTotalSize)
+	case owner != "" && exceeds(m.outputs.OwnerSize(owner), size, quota.MaxKeyOutputSize):
+		return fmt.Errorf("%w: API key output quota of %d bytes reached", models.ErrStorageFull, quota.MaxKeyOutputSize)
+	}
+
+	if quota.MinFreeSpace <= 0 {
+		return nil
+	}
+	for _, dir := range []string{m.config.OutputDir, m.config.TempDir, m.config.DataDir} {
+		free, err := freeSpace(dir)
+		if err != nil || free < 0 {
+			continue
+		}
+		if free-size < quota.MinFreeSpace {
+			return fmt.Errorf("%w: less than %d bytes free in %s", models.ErrStorageFull, quota.MinFreeSpace, dir)
+		}
+	}
+	return nil
+}
+
+// checkFileSize returns models.ErrFileTooLarge if size passes the file limit
+func (m *StorageManager) checkFileSize(size int64) error {
+	if m.config.MaxFileSize > 0 && size > m.config.MaxFileSize {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d", models.ErrFileTooLarge, size, m.config.MaxFileSize)
+	}
+	return nil
+}
+
+// exceeds reports whether adding size bytes to used passes limit. A zero
+// size asks whether the limit is already reached. Limits of zero are off.
+func exceeds(used, size, limit int64) bool {
+	if limit <= 0 {
+		return false
+	}
+	if size <= 0 {
+		return used >= limit
+	}
+	return used+size > limit
+}
+
+// usage caches the sizes that are only known by scanning
+type usage struct {
+	flatSize  int64 // Outputs not yet content-addressed
+	modelSize int64
+	tempSize  int64
+	scannedAt time.Time
+	scanning  bool
+}
+
+// rescan measures the flat outputs and the models and temp directories
+func (m *StorageManager) rescan() error {
+	var flatSize int64
+	err := m.backend.List(context.Background(), func(object ObjectInfo) error {
+		if !isBlob(object.Key) {
+			flatSize += object.Size
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	modelSize, err := getDirSize(m.config.ModelsDir)
+	if err != nil {
+		return err
+	}
+	tempSize, err := getDirSize(m.config.TempDir)
+	if err != nil {
+		return err
+	}
+
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+
+	m.usage = usage{
+		flatSize:  flatSize,
+		modelSize: modelSize,
+		tempSize:  tempSize,
+		scannedAt: time.Now(),
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Backend implements the Backend interface on an S3-compatible bucket
+type S3Backend struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Backend connects to the bucket and creates it if configured to
+func NewS3Backend(cfg config.S3Config) (*S3Backend, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 storage requires an endpoint and a bucket")
+	}
+
+	accessKey, secretKey := cfg.AccessKeyID, cfg.SecretAccessKey
+	if accessKey == "" {
+		accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if secretKey == "" {
+		secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	b := &S3Backend{
+		client: client,
+		bucket: cfg.Bucket,
+		prefix: strings.TrimPrefix(cfg.Prefix, "/"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrStorageUnavailable, err)
+	}
+	if !exists {
+		if !cfg.CreateBucket {
+			return nil, fmt.Errorf("s3 bucket %q does not exist", cfg.Bucket)
+		}
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create s3 bucket %q: %w", cfg.Bucket, err)
+		}
+	}
+
+	return b, nil
+}
+
+// Put uploads the object. A negative size streams it in parts.
+func (b *S3Backend) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	objectKey, err := b.objectKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = b.client.PutObject(ctx, b.bucket, objectKey, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return b.convertError(err)
+}
+
+// Get opens the object for reading
+func (b *S3Backend) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	objectKey, err := b.objectKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	object, err := b.client.GetObject(ctx, b.bucket, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, b.convertError(err)
+	}
+	// GetObject is lazy, Stat makes the request and reports missing objects
+	stat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, nil, b.convertError(err)
+	}
+	return object, b.objectInfo(stat), nil
+}
+
+// Stat returns the object's metadata
+func (b *S3Backend) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	objectKey, err := b.objectKey(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := b.client.StatObject(ctx, b.bucket, objectKey, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, b.convertError(err)
+	}
+	return b.objectInfo(stat), nil
+}
+
+// Delete removes the object
+func (b *S3Backend) Delete(ctx context.Context, key string) error {
+	objectKey, err := b.objectKey(key)
+	if err != nil {
+		return err
+	}
+	return b.convertError(b.client.RemoveObject(ctx, b.bucket, objectKey, minio.RemoveObjectOptions{}))
+}
+
+// List calls fn for every object under the prefix
+func (b *S3Backend) List(ctx context.Context, fn func(ObjectInfo) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for object := range b.client.ListObjects(ctx, b.bucket, minio.ListObjectsOptions{Prefix: b.prefix, Recursive: true}) {
+		if object.Err != nil {
+			return b.convertError(object.Err)
+		}
+		if err := fn(*b.objectInfo(object)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PresignGet returns a presigned GET link
+func (b *S3Backend) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	objectKey, err := b.objectKey(key)
+	if err != nil {
+		return "", err
+	}
+	link, err := b.client.PresignedGetObject(ctx, b.bucket, objectKey, expiry, nil)
+	if err != nil {
+		return "", b.convertError(err)
+	}
+	return link.String(), nil
+}
+
+// objectKey prefixes a validated key
+func (b *S3Backend) objectKey(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return b.prefix + key, nil
+}
+
+// objectInfo converts S3 metadata, stripping the prefix from the key
+func (b *S3Backend) objectInfo(object minio.ObjectInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         strings.TrimPrefix(object.Key, b.prefix),
+		Size:        object.Size,
+		ContentType: object.ContentType,
+		ModifiedAt:  object.LastModified,
+		ETag:        object.ETag,
+	}
+}
+
+// convertError maps S3 errors to storage errors
+func (b *S3Backend) convertError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NotFound":
+		return models.ErrFileNotFound
+	}
+	return fmt.Errorf("%w: %v", models.ErrStorageUnavailable, err)
+}
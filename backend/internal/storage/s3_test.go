@@ -0,0 +1,347 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// s3Object is an object held by the stub
+type s3Object struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+}
+
+// s3Stub is a minimal path-style S3 endpoint holding one bucket in memory.
+// It serves the calls S3Backend makes: bucket HEAD and PUT, object PUT, GET,
+// HEAD and DELETE, multipart uploads and ListObjectsV2.
+type s3Stub struct {
+	t      *testing.T
+	bucket string
+
+	mu      sync.Mutex
+	created bool
+	objects map[string]*s3Object
+	uploads map[string]*s3Upload
+}
+
+// s3Upload is a multipart upload in progress
+type s3Upload struct {
+	contentType string
+	parts       map[int][]byte
+}
+
+func newS3Stub(t *testing.T, bucket string, created bool) *httptest.Server {
+	stub := &s3Stub{t: t, bucket: bucket, created: created, objects: make(map[string]*s3Object), uploads: make(map[string]*s3Upload)}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.bucket {
+		s.error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key == "" {
+		switch {
+		case r.Method == http.MethodHead && s.created:
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPut:
+			s.created = true
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+			s.list(w, r.URL.Query().Get("prefix"))
+		default:
+			s.error(w, http.StatusNotImplemented, "NotImplemented")
+		}
+		return
+	}
+	if !s.created {
+		s.error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadID := strconv.Itoa(len(s.uploads) + 1)
+		s.uploads[uploadID] = &s3Upload{contentType: r.Header.Get("Content-Type"), parts: make(map[int][]byte)}
+		s.xml(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: s.bucket, Key: key, UploadId: uploadID})
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		upload, ok := s.uploads[query.Get("uploadId")]
+		part, _ := strconv.Atoi(query.Get("partNumber"))
+		data, err := s.readBody(r)
+		if !ok || err != nil {
+			s.t.Errorf("upload part %d of %s: %v", part, key, err)
+			s.error(w, http.StatusBadRequest, "NoSuchUpload")
+			return
+		}
+		upload.parts[part] = data
+		w.Header().Set("ETag", etag(data))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		upload, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			s.error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		numbers := make([]int, 0, len(upload.parts))
+		for number := range upload.parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for _, number := range numbers {
+			data = append(data, upload.parts[number]...)
+		}
+		delete(s.uploads, query.Get("uploadId"))
+		s.objects[key] = &s3Object{data: data, contentType: upload.contentType, modified: time.Now().UTC()}
+		s.xml(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: s.bucket, Key: key, ETag: etag(data)})
+	case r.Method == http.MethodPut:
+		data, err := s.readBody(r)
+		if err != nil {
+			s.t.Errorf("PUT %s: %v", key, err)
+			s.error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		s.objects[key] = &s3Object{data: data, contentType: r.Header.Get("Content-Type"), modified: time.Now().UTC()}
+		w.Header().Set("ETag", etag(data))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := s.objects[key]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			s.error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("ETag", etag(object.data))
+		w.Header().Set("Last-Modified", object.modified.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// readBody returns the uploaded bytes, decoding the aws-chunked encoding
+// used for streaming signatures over plain HTTP
+func (s *s3Stub) readBody(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	reader := bufio.NewReader(r.Body)
+	var data []byte
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("read chunk header: %w", err)
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("chunk size %q: %w", sizeHex, err)
+		}
+		if size == 0 {
+			break
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, fmt.Errorf("read chunk: %w", err)
+		}
+		data = append(data, chunk...)
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, fmt.Errorf("read chunk end: %w", err)
+		}
+	}
+
+	if decoded := r.Header.Get("X-Amz-Decoded-Content-Length"); decoded != strconv.Itoa(len(data)) {
+		return nil, fmt.Errorf("decoded %d bytes, header says %s", len(data), decoded)
+	}
+	return data, nil
+}
+
+// list writes a ListObjectsV2 result with every object under prefix
+func (s *s3Stub) list(w http.ResponseWriter, prefix string) {
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+	}
+	result := struct {
+		XMLName  xml.Name `xml:"ListBucketResult"`
+		Name     string
+		Prefix   string
+		KeyCount int
+		Contents []content
+	}{Name: s.bucket, Prefix: prefix}
+
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		object := s.objects[key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: object.modified.Format(time.RFC3339),
+			ETag:         etag(object.data),
+			Size:         len(object.data),
+		})
+	}
+	result.KeyCount = len(result.Contents)
+	s.xml(w, result)
+}
+
+// xml writes an XML response document
+func (s *s3Stub) xml(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		s.t.Errorf("encode response: %v", err)
+	}
+}
+
+// error writes an S3 error document
+func (s *s3Stub) error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+// etag returns the quoted MD5 of the content, as S3 does for single uploads
+func etag(data []byte) string {
+	return fmt.Sprintf(`"%x"`, md5.Sum(data))
+}
+
+// s3Config points a backend at the stub
+func s3Config(srv *httptest.Server, createBucket bool) config.S3Config {
+	return config.S3Config{
+		Endpoint:        strings.TrimPrefix(srv.URL, "http://"),
+		Region:          "us-east-1",
+		Bucket:          "outputs",
+		Prefix:          "images/",
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+		CreateBucket:    createBucket,
+	}
+}
+
+func TestS3Backend(t *testing.T) {
+	srv := newS3Stub(t, "outputs", false)
+	b, err := NewS3Backend(s3Config(srv, true))
+	if err != nil {
+		t.Fatalf("NewS3Backend: %v", err)
+	}
+	ctx := context.Background()
+
+	// Put with a known size and streamed with an unknown one
+	if err := b.Put(ctx, "a.png", strings.NewReader("first image"), 11, "image/png"); err != nil {
+		t.Fatalf("Put a.png: %v", err)
+	}
+	if err := b.Put(ctx, "thumbs/b.webp", strings.NewReader("second"), -1, "image/webp"); err != nil {
+		t.Fatalf("Put thumbs/b.webp: %v", err)
+	}
+
+	r, info, err := b.Get(ctx, "a.png")
+	if err != nil {
+		t.Fatalf("Get a.png: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(data) != "first image" {
+		t.Errorf("Get a.png = %q, %v, want %q", data, err, "first image")
+	}
+	if info.Key != "a.png" || info.Size != 11 || info.ContentType != "image/png" || info.ETag == "" {
+		t.Errorf("Get a.png info = %+v", info)
+	}
+
+	info, err = b.Stat(ctx, "thumbs/b.webp")
+	if err != nil {
+		t.Fatalf("Stat thumbs/b.webp: %v", err)
+	}
+	if info.Key != "thumbs/b.webp" || info.Size != 6 || info.ContentType != "image/webp" {
+		t.Errorf("Stat thumbs/b.webp = %+v", info)
+	}
+
+	// Keys are listed without the prefix
+	var listed []string
+	if err := b.List(ctx, func(object ObjectInfo) error {
+		listed = append(listed, fmt.Sprintf("%s:%d", object.Key, object.Size))
+		return nil
+	}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if want := "a.png:11,thumbs/b.webp:6"; strings.Join(listed, ",") != want {
+		t.Errorf("List = %v, want %s", listed, want)
+	}
+
+	if err := b.Delete(ctx, "a.png"); err != nil {
+		t.Fatalf("Delete a.png: %v", err)
+	}
+	if _, err := b.Stat(ctx, "a.png"); !errors.Is(err, models.ErrFileNotFound) {
+		t.Errorf("Stat after Delete = %v, want ErrFileNotFound", err)
+	}
+	if _, _, err := b.Get(ctx, "a.png"); !errors.Is(err, models.ErrFileNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrFileNotFound", err)
+	}
+
+	if _, err := b.Stat(ctx, "../escape.png"); !errors.Is(err, models.ErrInvalidFileName) {
+		t.Errorf("Stat outside the prefix = %v, want ErrInvalidFileName", err)
+	}
+}
+
+func TestS3BackendMissingBucket(t *testing.T) {
+	srv := newS3Stub(t, "outputs", false)
+	if _, err := NewS3Backend(s3Config(srv, false)); err == nil {
+		t.Error("NewS3Backend succeeded without the bucket or create_bucket")
+	}
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// Query parameters of signed output URLs
+const (
+	ExpiresParam   = "expires"
+	SignatureParam = "sig"
+)
+
+// URLSigner signs output paths with an expiry, so they can be fetched by
+// browsers and other clients that cannot send an API key
+type URLSigner struct {
+	secret []byte
+	expiry time.Duration
+}
+
+// NewURLSigner creates a signer from the auth config. It returns nil when
+// outputs do not need signed URLs.
+func NewURLSigner(cfg config.AuthConfig) (*URLSigner, error) {
+	if !cfg.SignOutputs() {
+		return nil, nil
+	}
+
+	// A generated secret would break links on restart and across replicas
+	secret := []byte(cfg.URLSecret)
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("auth.url_secret is required to sign output URLs")
+	}
+	expiry := time.Duration(cfg.URLExpiry) * time.Second
+	if expiry <= 0 {
+		expiry = 24 * time.Hour
+	}
+	return &URLSigner{secret: secret, expiry: expiry}, nil
+}
+
+// Sign returns the /outputs path of key with an expiry and signature
+func (s *URLSigner) Sign(key string) string {
+	expires := strconv.FormatInt(time.Now().Add(s.expiry).Unix(), 10)
+	query := url.Values{
+		ExpiresParam:   {expires},
+		SignatureParam: {s.signature(key, expires)},
+	}
+	return "/outputs/" + key + "?" + query.Encode()
+}
+
+// Verify checks the expiry and signature in query against key. Other
+// parameters, such as a resize, are not covered by the signature.
+func (s *URLSigner) Verify(key string, query url.Values) error {
+	expires := query.Get(ExpiresParam)
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signed URL", models.ErrForbidden)
+	}
+	signature := query.Get(SignatureParam)
+	if !hmac.Equal([]byte(signature), []byte(s.signature(key, expires))) {
+		return fmt.Errorf("%w: invalid URL signature", models.ErrForbidden)
+	}
+	if time.Now().Unix() > unix {
+		return fmt.Errorf("%w: signed URL expired", models.ErrForbidden)
+	}
+	return nil
+}
+
+// signature is the HMAC of the key and expiry
+func (s *URLSigner) signature(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,435 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Manager interface for webhook delivery operations
+type Manager interface {
+	Notify(req *models.GenerationRequest, status models.GenerationStatus)
+	ListDeliveries(filter DeliveryFilter) []*models.WebhookDelivery
+	Replay(id, owner string) (*models.WebhookDelivery, error)
+	Start(ctx context.Context)
+	Flush()
+}
+
+// DeliveryFilter selects deliveries from the log. Only deliveries of Owner
+// match, the empty owner selects those of generations submitted without an
+// API key.
+type DeliveryFilter struct {
+	Owner  string
+	JobID  string
+	Status models.WebhookDeliveryStatus
+	Limit  int
+}
+
+// WebhookManager implements the Manager interface.
+// Deliveries are retried with exponential backoff and recorded in a JSON
+// log under the data directory so failed ones can be inspected and replayed.
+// The log is written by a background writer, so queue listeners never wait
+// for the disk.
+type WebhookManager struct {
+	config     config.WebhooksConfig
+	httpClient *http.Client
+	logger     *logrus.Logger
+	file       string
+
+	writeMu sync.Mutex    // Serialises writes of the log file
+	dirty   chan struct{} // Wakes the writer after a change
+
+	mu         sync.RWMutex
+	deliveries map[string]*delivery
+	ctx        context.Context
+}
+
+// delivery pairs a logged delivery with the secret needed to sign it and
+// the API key of the generation it reports
+type delivery struct {
+	models.WebhookDelivery
+	Secret string `json:"secret,omitempty"`
+	Owner  string `json:"owner,omitempty"`
+}
+
+// NewManager creates a new webhook manager
+func NewManager(cfg config.WebhooksConfig, storageConfig config.StorageConfig, logger *logrus.Logger) (Manager, error) {
+	return NewManagerWithClient(cfg, storageConfig, newClient(time.Duration(cfg.Timeout)*time.Second, cfg.AllowPrivate), logger)
+}
+
+// NewManagerWithClient creates a webhook manager that delivers with the given HTTP client
+func NewManagerWithClient(cfg config.WebhooksConfig, storageConfig config.StorageConfig, client *http.Client, logger *logrus.Logger) (Manager, error) {
+	file := cfg.LogFile
+	if file == "" {
+		file = filepath.Join(storageConfig.DataDir, "webhook_deliveries.json")
+	}
+
+	m := &WebhookManager{
+		config:     cfg,
+		httpClient: client,
+		logger:     logger,
+		file:       file,
+		dirty:      make(chan struct{}, 1),
+		deliveries: make(map[string]*delivery),
+		ctx:        context.Background(),
+	}
+
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	go m.writer()
+	return m, nil
+}
+
+// Start resumes pending deliveries; deliveries stop when ctx is cancelled
+func (m *WebhookManager) Start(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	var pending []*delivery
+	for _, d := range m.deliveries {
+		if d.Status == models.DeliveryPending {
+			pending = append(pending, d)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, d := range pending {
+		m.dispatch(d)
+	}
+
+	m.logger.WithField("resumed", len(pending)).Info("Webhook dispatcher started")
+}
+
+// Notify queues a delivery for a generation that reached a terminal state.
+// It matches the queue.Listener signature.
+func (m *WebhookManager) Notify(req *models.GenerationRequest, status models.GenerationStatus) {
+	if req == nil || req.Webhook == nil {
+		return
+	}
+
+	event := string(status.Status)
+	if !req.Webhook.Wants(event) {
+		return
+	}
+
+	payload, err := json.Marshal(models.WebhookPayload{
+		Event:     "generation." + event,
+		JobID:     req.ID,
+		Status:    &status,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		m.logger.WithError(err).WithField("request_id", req.ID).Error("Failed to encode webhook payload")
+		return
+	}
+
+	now := time.Now()
+	d := &delivery{
+		WebhookDelivery: models.WebhookDelivery{
+			ID:        uuid.New().String(),
+			JobID:     req.ID,
+			Event:     event,
+			URL:       req.Webhook.URL,
+			Payload:   payload,
+			Status:    models.DeliveryPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		Secret: req.Webhook.Secret,
+		Owner:  req.Owner,
+	}
+
+	m.mu.Lock()
+	m.deliveries[d.ID] = d
+	m.prune()
+	m.persist()
+	m.mu.Unlock()
+
+	m.dispatch(d)
+}
+
+// ListDeliveries returns logged deliveries, newest first
+func (m *WebhookManager) ListDeliveries(filter DeliveryFilter) []*models.WebhookDelivery {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for _, d := range m.deliveries {
+		if d.Owner != filter.Owner {
+			continue
+		}
+		if filter.JobID != "" && d.JobID != filter.JobID {
+			continue
+		}
+		if filter.Status != "" && d.Status != filter.Status {
+			continue
+		}
+		snapshot := d.WebhookDelivery
+		deliveries = append(deliveries, &snapshot)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(deliveries) > filter.Limit {
+		deliveries = deliveries[:filter.Limit]
+	}
+	return deliveries
+}
+
+// Replay resets a finished delivery of owner and sends it again
+func (m *WebhookManager) Replay(id, owner string) (*models.WebhookDelivery, error) {
+	m.mu.Lock()
+	d, exists := m.deliveries[id]
+	if !exists || d.Owner != owner {
+		m.mu.Unlock()
+		return nil, models.ErrDeliveryNotFound
+	}
+	if d.Status == models.DeliveryPending {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("delivery %s is already pending", id)
+	}
+
+	d.Status = models.DeliveryPending
+	d.Attempts = 0
+	d.LastError = ""
+	d.ResponseStatus = 0
+	d.UpdatedAt = time.Now()
+	snapshot := d.WebhookDelivery
+	m.persist()
+	m.mu.Unlock()
+
+	m.logger.WithField("delivery_id", id).Info("Replaying webhook delivery")
+	m.dispatch(d)
+	return &snapshot, nil
+}
+
+// dispatch delivers in the background, retrying with backoff
+func (m *WebhookManager) dispatch(d *delivery) {
+	m.mu.RLock()
+	ctx := m.ctx
+	m.mu.RUnlock()
+
+	go func() {
+		for {
+			err := m.attempt(ctx, d)
+
+			m.mu.Lock()
+			d.Attempts++
+			d.UpdatedAt = time.Now()
+			if err == nil {
+				d.Status = models.DeliveryDelivered
+				d.LastError = ""
+				d.NextAttemptAt = nil
+				m.persist()
+				m.mu.Unlock()
+				m.logger.WithFields(logrus.Fields{
+					"delivery_id": d.ID,
+					"request_id":  d.JobID,
+					"event":       d.Event,
+				}).Info("Webhook delivered")
+				return
+			}
+
+			d.LastError = err.Error()
+			if d.Attempts >= m.config.MaxAttempts {
+				d.Status = models.DeliveryFailed
+				d.NextAttemptAt = nil
+				m.persist()
+				m.mu.Unlock()
+				m.logger.WithError(err).WithFields(logrus.Fields{
+					"delivery_id": d.ID,
+					"request_id":  d.JobID,
+					"attempts":    d.Attempts,
+				}).Error("Webhook delivery failed")
+				return
+			}
+
+			backoff := m.backoff(d.Attempts)
+			next := time.Now().Add(backoff)
+			d.NextAttemptAt = &next
+			m.persist()
+			m.mu.Unlock()
+
+			m.logger.WithError(err).WithFields(logrus.Fields{
+				"delivery_id": d.ID,
+				"attempt":     d.Attempts,
+				"retry_in":    backoff.String(),
+			}).Warn("Webhook delivery attempt failed")
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+		}
+	}()
+}
+
+// attempt POSTs the payload once
+func (m *WebhookManager) attempt(ctx context.Context, d *delivery) error {
+	m.mu.RLock()
+	url, payload, secret, event, id := d.URL, d.Payload, d.Secret, d.Event, d.ID
+	m.mu.RUnlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AbleRefusal-Webhook/1.0")
+	req.Header.Set(HeaderEvent, "generation."+event)
+	req.Header.Set(HeaderDelivery, id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, timestamp, payload))
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	m.mu.Lock()
+	d.ResponseStatus = resp.StatusCode
+	m.mu.Unlock()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns the delay before the next attempt
+func (m *WebhookManager) backoff(attempts int) time.Duration {
+	delay := time.Duration(m.config.InitialBackoff) * time.Second
+	maxDelay := time.Duration(m.config.MaxBackoff) * time.Second
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return delay
+}
+
+// prune drops the oldest finished deliveries beyond the log limit, caller must hold the lock
+func (m *WebhookManager) prune() {
+	if m.config.MaxLogEntries <= 0 || len(m.deliveries) <= m.config.MaxLogEntries {
+		return
+	}
+
+	finished := make([]*delivery, 0, len(m.deliveries))
+	for _, d := range m.deliveries {
+		if d.Status != models.DeliveryPending {
+			finished = append(finished, d)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreatedAt.Before(finished[j].CreatedAt)
+	})
+
+	for _, d := range finished {
+		if len(m.deliveries) <= m.config.MaxLogEntries {
+			break
+		}
+		delete(m.deliveries, d.ID)
+	}
+}
+
+// load reads the delivery log from disk
+func (m *WebhookManager) load() error {
+	data, err := os.ReadFile(m.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read webhook log: %w", err)
+	}
+
+	var deliveries []*delivery
+	if err := json.Unmarshal(data, &deliveries); err != nil {
+		return fmt.Errorf("failed to parse webhook log: %w", err)
+	}
+
+	for _, d := range deliveries {
+		m.deliveries[d.ID] = d
+	}
+	return nil
+}
+
+// persist schedules a write of the delivery log. Changes made while a
+// write is pending are saved by that write.
+func (m *WebhookManager) persist() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// writer writes the delivery log after every change
+func (m *WebhookManager) writer() {
+	for range m.dirty {
+		m.write()
+	}
+}
+
+// Flush writes the delivery log now, for shutdown
+func (m *WebhookManager) Flush() {
+	m.write()
+}
+
+// write saves a snapshot of the delivery log to disk
+func (m *WebhookManager) write() {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	m.mu.RLock()
+	deliveries := make([]*delivery, 0, len(m.deliveries))
+	for _, d := range m.deliveries {
+		deliveries = append(deliveries, d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	data, err := json.MarshalIndent(deliveries, "", "  ")
+	m.mu.RUnlock()
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to encode webhook log")
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.file), 0755); err != nil {
+		m.logger.WithError(err).Error("Failed to create webhook log directory")
+		return
+	}
+
+	// The log holds signing secrets, keep it private
+	tmp := m.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		m.logger.WithError(err).Error("Failed to write webhook log")
+		return
+	}
+	if err := os.Rename(tmp, m.file); err != nil {
+		m.logger.WithError(err).Error("Failed to write webhook log")
+	}
+}
@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/internal/config"
+	"github.com/ablerefusal/ablerefusal/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// receiver is a webhook endpoint that fails the first failures requests
+// with 500 and records every request it gets
+type receiver struct {
+	t        *testing.T
+	failures int
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.t.Errorf("read body: %v", err)
+	}
+
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	attempt := len(r.requests)
+	r.mu.Unlock()
+
+	if attempt <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newManager creates a started manager that logs deliveries to a temp directory
+func newManager(t *testing.T, cfg config.WebhooksConfig) *WebhookManager {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	m, err := NewManager(cfg, config.StorageConfig{DataDir: t.TempDir()}, logger)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	// Let the log writer finish before the temp directory is removed
+	t.Cleanup(m.Flush)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	m.Start(ctx)
+	return m.(*WebhookManager)
+}
+
+// notify reports a completed generation with a webhook to url
+func notify(m *WebhookManager, url string) {
+	req := &models.GenerationRequest{
+		ID:      "job-1",
+		Owner:   "key-1",
+		Webhook: &models.WebhookConfig{URL: url, Secret: "shared-secret"},
+	}
+	m.Notify(req, models.GenerationStatus{ID: "job-1", Status: models.StatusCompleted, Progress: 100})
+}
+
+// waitFinished polls the log until the only delivery of key-1 is no longer pending
+func waitFinished(t *testing.T, m *WebhookManager) *models.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		deliveries := m.ListDeliveries(DeliveryFilter{Owner: "key-1"})
+		if len(deliveries) == 1 && deliveries[0].Status != models.DeliveryPending {
+			return deliveries[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("delivery did not finish")
+	return nil
+}
+
+func TestDeliverySignedAndRetried(t *testing.T) {
+	endpoint := &receiver{t: t, failures: 2}
+	srv := httptest.NewServer(endpoint)
+	t.Cleanup(srv.Close)
+
+	m := newManager(t, config.WebhooksConfig{Timeout: 5, MaxAttempts: 3, InitialBackoff: 0, MaxBackoff: 0, AllowPrivate: true})
+	notify(m, srv.URL)
+
+	delivery := waitFinished(t, m)
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 3 || delivery.ResponseStatus != http.StatusNoContent {
+		t.Errorf("delivery = %s after %d attempts with %d, want delivered after 3 with 204", delivery.Status, delivery.Attempts, delivery.ResponseStatus)
+	}
+
+	endpoint.mu.Lock()
+	defer endpoint.mu.Unlock()
+	if len(endpoint.requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(endpoint.requests))
+	}
+	for i, req := range endpoint.requests {
+		timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Fatalf("request %d: %s = %q", i, HeaderTimestamp, req.Header.Get(HeaderTimestamp))
+		}
+		if !Verify("shared-secret", timestamp, endpoint.bodies[i], req.Header.Get(HeaderSignature)) {
+			t.Errorf("request %d: signature %q does not verify", i, req.Header.Get(HeaderSignature))
+		}
+		if Verify("other-secret", timestamp, endpoint.bodies[i], req.Header.Get(HeaderSignature)) {
+			t.Errorf("request %d: signature verifies with the wrong secret", i)
+		}
+		if got := req.Header.Get(HeaderEvent); got != "generation.completed" {
+			t.Errorf("request %d: %s = %q, want generation.completed", i, HeaderEvent, got)
+		}
+		if got := req.Header.Get(HeaderDelivery); got != delivery.ID {
+			t.Errorf("request %d: %s = %q, want %q", i, HeaderDelivery, got, delivery.ID)
+		}
+	}
+
+	var payload models.WebhookPayload
+	if err := json.Unmarshal(endpoint.bodies[0], &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Event != "generation.completed" || payload.JobID != "job-1" || payload.Status.Status != models.StatusCompleted {
+		t.Errorf("payload = %+v, want generation.completed for job-1", payload)
+	}
+}
+
+func TestDeliveryFailsAfterMaxAttempts(t *testing.T) {
+	endpoint := &receiver{t: t, failures: 10}
+	srv := httptest.NewServer(endpoint)
+	t.Cleanup(srv.Close)
+
+	m := newManager(t, config.WebhooksConfig{Timeout: 5, MaxAttempts: 2, InitialBackoff: 0, MaxBackoff: 0, AllowPrivate: true})
+	notify(m, srv.URL)
+
+	delivery := waitFinished(t, m)
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != 2 || delivery.ResponseStatus != http.StatusInternalServerError {
+		t.Errorf("delivery = %s after %d attempts with %d, want failed after 2 with 500", delivery.Status, delivery.Attempts, delivery.ResponseStatus)
+	}
+	if delivery.LastError == "" {
+		t.Error("LastError is empty")
+	}
+
+	// Other keys neither see nor replay the delivery
+	if deliveries := m.ListDeliveries(DeliveryFilter{Owner: "key-2"}); len(deliveries) != 0 {
+		t.Errorf("key-2 sees %d deliveries, want 0", len(deliveries))
+	}
+	if _, err := m.Replay(delivery.ID, "key-2"); !errors.Is(err, models.ErrDeliveryNotFound) {
+		t.Errorf("Replay by key-2 = %v, want ErrDeliveryNotFound", err)
+	}
+}
+
+func TestDeliveryRefusesPrivateAddresses(t *testing.T) {
+	endpoint := &receiver{t: t}
+	srv := httptest.NewServer(endpoint)
+	t.Cleanup(srv.Close)
+
+	m := newManager(t, config.WebhooksConfig{Timeout: 5, MaxAttempts: 1})
+	notify(m, srv.URL)
+
+	delivery := waitFinished(t, m)
+	if delivery.Status != models.DeliveryFailed || !strings.Contains(delivery.LastError, ErrPrivateAddress.Error()) {
+		t.Errorf("delivery = %s with error %q, want failed with a private address error", delivery.Status, delivery.LastError)
+	}
+	endpoint.mu.Lock()
+	defer endpoint.mu.Unlock()
+	if len(endpoint.requests) != 0 {
+		t.Errorf("endpoint got %d requests, want 0", len(endpoint.requests))
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for _, tt := range []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.5", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+	} {
+		if got := isPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	m := &WebhookManager{config: config.WebhooksConfig{InitialBackoff: 1, MaxBackoff: 5}}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, expected := range want {
+		if got := m.backoff(i + 1); got != expected {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, expected)
+		}
+	}
+}
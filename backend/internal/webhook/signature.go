@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Headers sent with every webhook delivery
+const (
+	HeaderSignature = "X-AbleRefusal-Signature"
+	HeaderTimestamp = "X-AbleRefusal-Timestamp"
+	HeaderEvent     = "X-AbleRefusal-Event"
+	HeaderDelivery  = "X-AbleRefusal-Delivery"
+)
+
+// Sign computes the signature header value for a payload.
+// The HMAC-SHA256 covers "<timestamp>.<body>" so replayed requests with an
+// old timestamp can be rejected by the receiver.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value against a payload
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	expected := Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,356 @@
+// Package client is a Go SDK for the AbleRefusal backend REST API.
+//
+//	c := client.New("http://localhost:8080")
+//	statuses, err := c.GenerateAndWait(ctx, client.NewGenerationRequest("a lighthouse at dusk"))
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader is sent with every Generate call so retries never
+// queue a job twice
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// APIKeyHeader carries the API key set with WithAPIKey
+const APIKeyHeader = "X-API-Key"
+
+// Client calls the backend API. It is safe for concurrent use.
+type Client struct {
+	baseURL      string
+	apiKey       string
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+	pollInterval time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey sets the API key sent with every request, required when the
+// server has auth enabled
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithRetries sets how often failed requests are retried and the initial
+// backoff, which doubles after each attempt
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithPollInterval sets how often Watch polls when streaming is unavailable
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = interval
+	}
+}
+
+// New creates a client for the server at baseURL (e.g. "http://localhost:8080")
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		httpClient:   http.DefaultClient,
+		maxRetries:   3,
+		retryBackoff: 500 * time.Millisecond,
+		pollInterval: time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context that makes Generate send key instead
+// of a random one, so a request can be retried safely across processes
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// Generate queues a generation request
+func (c *Client) Generate(ctx context.Context, req *GenerationRequest) (*GenerateResponse, error) {
+	return c.GenerateBody(ctx, req)
+}
+
+// GenerateBody queues a generation request from any JSON-encodable body.
+// Fields left out of the body are filled in from the preset and the server
+// defaults, which a GenerationRequest cannot express.
+func (c *Client) GenerateBody(ctx context.Context, body interface{}) (*GenerateResponse, error) {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	if key == "" {
+		key = uuid.New().String()
+	}
+
+	var resp GenerateResponse
+	header := http.Header{IdempotencyKeyHeader: []string{key}}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/generate", header, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GenerateAndWait queues a generation request and blocks until every job it
+// created has finished. The statuses are in the order of the response IDs.
+func (c *Client) GenerateAndWait(ctx context.Context, req *GenerationRequest) ([]*GenerationStatus, error) {
+	resp, err := c.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := resp.JobIDs()
+	statuses := make([]*GenerationStatus, len(ids))
+	for i, id := range ids {
+		if statuses[i], err = c.Wait(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
+// Wait blocks until the generation finishes and returns its final status.
+// It returns ctx.Err() when ctx is done first.
+func (c *Client) Wait(ctx context.Context, id string) (*GenerationStatus, error) {
+	updates, errs := c.Watch(ctx, id)
+
+	var last *GenerationStatus
+	for status := range updates {
+		status := status
+		last = &status
+	}
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	if last != nil && last.Status.IsTerminal() {
+		return last, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if last == nil || !last.Status.IsTerminal() {
+		return nil, fmt.Errorf("ablerefusal: watch for %s ended before the generation finished", id)
+	}
+	return last, nil
+}
+
+// Status returns the current status of a generation
+func (c *Client) Status(ctx context.Context, id string) (*GenerationStatus, error) {
+	var status GenerationStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/generate/"+url.PathEscape(id), nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Cancel cancels a queued or running generation
+func (c *Client) Cancel(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/generate/"+url.PathEscape(id)+"/cancel", nil, nil, nil)
+}
+
+// Queue returns the queued and running generations
+func (c *Client) Queue(ctx context.Context) ([]*QueueItem, error) {
+	var resp struct {
+		Queue []*QueueItem `json:"queue"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/queue", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Queue, nil
+}
+
+// Models returns the models offered by the server
+func (c *Client) Models(ctx context.Context) ([]*Model, error) {
+	var resp struct {
+		Models []*Model `json:"models"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/models", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Models, nil
+}
+
+// History returns finished generations, newest first
+func (c *Client) History(ctx context.Context, opts HistoryOptions) (*HistoryPage, error) {
+	query := url.Values{}
+	if opts.Status != "" {
+		query.Set("status", string(opts.Status))
+	}
+	if opts.Query != "" {
+		query.Set("q", opts.Query)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+
+	path := "/api/v1/history"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var page HistoryPage
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Download writes the image of a generation result to w
+func (c *Client) Download(ctx context.Context, result GenerationResult, w io.Writer) error {
+	path := result.ImageURL
+	if path == "" {
+		path = "/outputs/" + pathpkg.Base(filepath.ToSlash(result.ImagePath))
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// do sends a JSON request and decodes the response into out, retrying
+// network errors and retryable statuses with exponential backoff
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("ablerefusal: failed to encode request: %w", err)
+		}
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, path, header, body, out)
+		if err == nil || attempt >= c.maxRetries || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send performs a single request
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, body []byte, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(data, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("ablerefusal: failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// newRequest creates a request for a path on the server, carrying the API key
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.apiKey != "" {
+		req.Header.Set(APIKeyHeader, c.apiKey)
+	}
+	return req, nil
+}
+
+// retryable reports whether a request may succeed if sent again
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		case http.StatusConflict:
+			// The same idempotency key is still being processed
+			return apiErr.Code == CodeIdempotencyInProgress
+		default:
+			return false
+		}
+	}
+
+	// Network errors
+	return true
+}
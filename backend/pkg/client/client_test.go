@@ -0,0 +1,275 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ablerefusal/ablerefusal/pkg/client"
+)
+
+// newServer starts a test server and a client for it that retries quickly
+func newServer(t *testing.T, handler http.HandlerFunc, opts ...client.Option) *client.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	opts = append([]client.Option{
+		client.WithRetries(2, time.Millisecond),
+		client.WithPollInterval(10 * time.Millisecond),
+	}, opts...)
+	return client.New(srv.URL+"/", opts...)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeEvents writes statuses as a server-sent event stream
+func writeEvents(w http.ResponseWriter, statuses ...client.GenerationStatus) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, status := range statuses {
+		data, _ := json.Marshal(status)
+		fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+	}
+	w.(http.Flusher).Flush()
+}
+
+func TestGenerate(t *testing.T) {
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/generate" {
+			t.Errorf("request = %s %s, want POST /api/v1/generate", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get(client.APIKeyHeader); got != "secret" {
+			t.Errorf("%s = %q, want %q", client.APIKeyHeader, got, "secret")
+		}
+		if got := r.Header.Get(client.IdempotencyKeyHeader); got != "key-1" {
+			t.Errorf("%s = %q, want %q", client.IdempotencyKeyHeader, got, "key-1")
+		}
+
+		var body client.GenerationRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if body.Prompt != "a lighthouse" {
+			t.Errorf("prompt = %q, want %q", body.Prompt, "a lighthouse")
+		}
+
+		writeJSON(w, http.StatusAccepted, client.GenerateResponse{ID: "job-1", IDs: []string{"job-1", "job-2"}, Status: "queued", Position: 3})
+	}, client.WithAPIKey("secret"))
+
+	ctx := client.WithIdempotencyKey(context.Background(), "key-1")
+	resp, err := c.Generate(ctx, client.NewGenerationRequest("a lighthouse"))
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if resp.ID != "job-1" || resp.Position != 3 {
+		t.Errorf("response = %+v, want job-1 at position 3", resp)
+	}
+	if ids := resp.JobIDs(); len(ids) != 2 || ids[1] != "job-2" {
+		t.Errorf("JobIDs() = %v, want [job-1 job-2]", ids)
+	}
+}
+
+func TestGenerateRetriesWithSameIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(client.IdempotencyKeyHeader))
+		attempt := len(keys)
+		mu.Unlock()
+
+		if attempt == 1 {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Queue is full", "code": string(client.CodeQueueFull)})
+			return
+		}
+		writeJSON(w, http.StatusAccepted, client.GenerateResponse{ID: "job-1", Status: "queued"})
+	})
+
+	if _, err := c.Generate(context.Background(), client.NewGenerationRequest("a lighthouse")); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("attempts = %d, want 2", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("idempotency keys = %q, want one non-empty key reused", keys)
+	}
+}
+
+func TestErrorDecoding(t *testing.T) {
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/generate":
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error":      "Invalid generation request",
+				"code":       client.CodeValidationFailed,
+				"request_id": "req-1",
+				"fields":     []map[string]string{{"field": "steps", "message": "must be between 1 and 150"}},
+			})
+		default:
+			http.Error(w, "upstream exploded", http.StatusBadGateway)
+		}
+	})
+
+	_, err := c.Generate(context.Background(), client.NewGenerationRequest("a lighthouse"))
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Generate error = %v, want *client.APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.RequestID != "req-1" || apiErr.Message != "Invalid generation request" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "steps" {
+		t.Errorf("Fields = %v, want steps", apiErr.Fields)
+	}
+	if !client.IsCode(err, client.CodeValidationFailed) {
+		t.Errorf("IsCode(%v, validation_failed) = false", err)
+	}
+
+	// Bodies that are not JSON fall back to the status text, after retrying
+	_, err = c.Status(context.Background(), "job-1")
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || apiErr.Message != "Bad Gateway" {
+		t.Errorf("Status error = %v, want 502 Bad Gateway", err)
+	}
+}
+
+func TestWaitStreamsEvents(t *testing.T) {
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/generate/job-1/events" {
+			t.Errorf("path = %s, want the event stream", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get(client.APIKeyHeader); got != "secret" {
+			t.Errorf("%s = %q, want %q", client.APIKeyHeader, got, "secret")
+		}
+		writeEvents(w,
+			client.GenerationStatus{ID: "job-1", Status: client.StatusQueued},
+			client.GenerationStatus{ID: "job-1", Status: client.StatusProcessing, Progress: 50},
+			client.GenerationStatus{ID: "job-1", Status: client.StatusCompleted, Progress: 100, Results: []client.GenerationResult{{ImageURL: "/outputs/a.png"}}},
+		)
+	}, client.WithAPIKey("secret"))
+
+	var seen []client.GenerationStatusType
+	updates, errs := c.Watch(context.Background(), "job-1")
+	for status := range updates {
+		seen = append(seen, status.Status)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	want := []client.GenerationStatusType{client.StatusQueued, client.StatusProcessing, client.StatusCompleted}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Errorf("Watch statuses = %v, want %v", seen, want)
+	}
+
+	status, err := c.Wait(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if status.Status != client.StatusCompleted || len(status.Results) != 1 {
+		t.Errorf("Wait = %+v, want completed with one result", status)
+	}
+}
+
+func TestWaitFallsBackToPolling(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/generate/job-1/events":
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Not found", "code": string(client.CodeEndpointNotFound)})
+		case "/api/v1/generate/job-1":
+			mu.Lock()
+			polls++
+			status := client.StatusProcessing
+			if polls >= 3 {
+				status = client.StatusFailed
+			}
+			mu.Unlock()
+			writeJSON(w, http.StatusOK, client.GenerationStatus{ID: "job-1", Status: status, ErrorCode: client.CodeOutOfMemory})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	status, err := c.Wait(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if status.Status != client.StatusFailed || status.ErrorCode != client.CodeOutOfMemory {
+		t.Errorf("Wait = %+v, want failed with out_of_memory", status)
+	}
+}
+
+func TestWaitUnknownGeneration(t *testing.T) {
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Generation not found", "code": string(client.CodeGenerationNotFound)})
+	})
+
+	if _, err := c.Wait(context.Background(), "missing"); !client.IsCode(err, client.CodeGenerationNotFound) {
+		t.Errorf("Wait error = %v, want generation_not_found", err)
+	}
+}
+
+func TestWaitCancelled(t *testing.T) {
+	started := make(chan struct{})
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// Report progress, then keep the stream open until the client leaves
+		writeEvents(w, client.GenerationStatus{ID: "job-1", Status: client.StatusProcessing, Progress: 10})
+		close(started)
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	if _, err := c.Wait(ctx, "job-1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWaitDeadline(t *testing.T) {
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, client.GenerationStatus{ID: "job-1", Status: client.StatusQueued})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Wait(ctx, "job-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	cancelled := false
+	c := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.EscapedPath() == "/api/v1/generate/job%201/cancel" {
+			cancelled = true
+			writeJSON(w, http.StatusOK, map[string]string{"id": "job 1", "status": "cancelled"})
+			return
+		}
+		t.Errorf("request = %s %s", r.Method, r.URL.EscapedPath())
+		http.NotFound(w, r)
+	})
+
+	if err := c.Cancel(context.Background(), "job 1"); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if !cancelled {
+		t.Error("cancel endpoint was not called")
+	}
+}
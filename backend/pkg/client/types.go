@@ -0,0 +1,156 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ablerefusal/ablerefusal/internal/models"
+)
+
+// The API types are shared with the server so requests and responses never
+// drift from what the backend accepts.
+type (
+	GenerationRequest    = models.GenerationRequest
+	GenerationStatus     = models.GenerationStatus
+	GenerationStatusType = models.GenerationStatusType
+	GenerationResult     = models.GenerationResult
+	QueueItem            = models.QueueItem
+	HistoryEntry         = models.HistoryEntry
+	ControlNetUnit       = models.ControlNetUnit
+	WebhookConfig        = models.WebhookConfig
+	FieldError           = models.FieldError
+	ErrorCode            = models.ErrorCode
+)
+
+// Generation states
+const (
+	StatusQueued     = models.StatusQueued
+	StatusProcessing = models.StatusProcessing
+	StatusCompleted  = models.StatusCompleted
+	StatusFailed     = models.StatusFailed
+	StatusCancelled  = models.StatusCancelled
+)
+
+// Error codes reported in APIError.Code and GenerationStatus.ErrorCode
+const (
+	CodeInvalidRequest        = models.CodeInvalidRequest
+	CodeValidationFailed      = models.CodeValidationFailed
+	CodeEndpointNotFound      = models.CodeEndpointNotFound
+	CodeNotImplemented        = models.CodeNotImplemented
+	CodeInternal              = models.CodeInternal
+	CodeIdempotencyKeyReused  = models.CodeIdempotencyKeyReused
+	CodeIdempotencyInProgress = models.CodeIdempotencyInProgress
+	CodeQueueFull             = models.CodeQueueFull
+	CodeGenerationNotFound    = models.CodeGenerationNotFound
+	CodeGenerationTimeout     = models.CodeGenerationTimeout
+	CodeGenerationCancelled   = models.CodeGenerationCancelled
+	CodeImageNotFound         = models.CodeImageNotFound
+	CodeInferenceUnavailable  = models.CodeInferenceUnavailable
+	CodeInferenceRejected     = models.CodeInferenceRejected
+	CodeInferenceFailed       = models.CodeInferenceFailed
+	CodeOutOfMemory           = models.CodeOutOfMemory
+	CodeModelNotFound         = models.CodeModelNotFound
+	CodeModelNotLoaded        = models.CodeModelNotLoaded
+	CodePipelineUnavailable   = models.CodePipelineUnavailable
+	CodeInvalidInput          = models.CodeInvalidInput
+	CodeControlNetNotFound    = models.CodeControlNetNotFound
+	CodePresetNotFound        = models.CodePresetNotFound
+	CodePresetExists          = models.CodePresetExists
+	CodeStyleNotFound         = models.CodeStyleNotFound
+	CodeStyleExists           = models.CodeStyleExists
+	CodePresetReadOnly        = models.CodePresetReadOnly
+	CodeDeliveryNotFound      = models.CodeDeliveryNotFound
+	CodeDeliveryNotReplayable = models.CodeDeliveryNotReplayable
+	CodeHistoryNotFound       = models.CodeHistoryNotFound
+	CodeFileNotFound          = models.CodeFileNotFound
+	CodeExportNotFound        = models.CodeExportNotFound
+	CodeExportNotReady        = models.CodeExportNotReady
+	CodeCollectionNotFound    = models.CodeCollectionNotFound
+	CodeCollectionExists      = models.CodeCollectionExists
+	CodeStorageUnavailable    = models.CodeStorageUnavailable
+	CodeStorageFull           = models.CodeStorageFull
+	CodeFileTooLarge          = models.CodeFileTooLarge
+	CodeUnauthorized          = models.CodeUnauthorized
+	CodeForbidden             = models.CodeForbidden
+)
+
+// NewGenerationRequest creates a generation request with the server defaults
+func NewGenerationRequest(prompt string) *GenerationRequest {
+	req := models.NewGenerationRequest()
+	req.Prompt = prompt
+	return req
+}
+
+// GenerateResponse is returned when a generation is queued
+type GenerateResponse struct {
+	ID       string   `json:"id"`
+	IDs      []string `json:"ids,omitempty"` // Every queued job when a dynamic prompt fanned out
+	Status   string   `json:"status"`
+	Position int      `json:"position"`
+	Message  string   `json:"message"`
+}
+
+// JobIDs returns the IDs of every job created by the request
+func (r *GenerateResponse) JobIDs() []string {
+	if len(r.IDs) > 0 {
+		return r.IDs
+	}
+	return []string{r.ID}
+}
+
+// Model describes a model offered by the server
+type Model struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Ready       bool   `json:"ready"`
+}
+
+// HistoryOptions filters History results
+type HistoryOptions struct {
+	Status GenerationStatusType
+	Query  string // Case-insensitive substring of the prompt
+	Limit  int
+	Offset int
+}
+
+// HistoryPage is one page of generation history
+type HistoryPage struct {
+	Entries []*HistoryEntry `json:"entries"`
+	Count   int             `json:"count"`
+	Total   int             `json:"total"`
+}
+
+// APIError is returned for non-2xx responses
+type APIError struct {
+	StatusCode int                    `json:"-"`
+	Message    string                 `json:"error"`
+	Code       ErrorCode              `json:"code,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	Fields     []*FieldError          `json:"fields,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	status := fmt.Sprint(e.StatusCode)
+	if e.Code != "" {
+		status += " " + string(e.Code)
+	}
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("ablerefusal: %s: %s", status, e.Message)
+	}
+	fields := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		fields[i] = field.Error()
+	}
+	return fmt.Sprintf("ablerefusal: %s: %s: %s", status, e.Message, strings.Join(fields, "; "))
+}
+
+// IsCode reports whether err is an APIError with the given code
+func IsCode(err error, code ErrorCode) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Watch streams status updates for a generation until it finishes. It reads
+// the server-sent event stream and falls back to polling when streaming is
+// unavailable or the connection drops. Both channels are closed when the
+// generation finishes or ctx is done; at most one error is sent.
+func (c *Client) Watch(ctx context.Context, id string) (<-chan GenerationStatus, <-chan error) {
+	updates := make(chan GenerationStatus, 1)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(updates)
+
+		send := func(status GenerationStatus) bool {
+			select {
+			case updates <- status:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// Polling also reports unknown generations, so any stream failure falls back to it
+		if done, _ := c.stream(ctx, id, send); done {
+			return
+		}
+		if err := c.poll(ctx, id, send); err != nil {
+			errs <- err
+		}
+	}()
+
+	return updates, errs
+}
+
+// stream reads status events until a terminal status arrives. It returns
+// true once the generation has finished or ctx is done.
+func (c *Client) stream(ctx context.Context, id string, send func(GenerationStatus) bool) (bool, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/api/v1/generate/"+url.PathEscape(id)+"/events", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() != nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if json.NewDecoder(resp.Body).Decode(apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return false, apiErr
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return false, fmt.Errorf("ablerefusal: unexpected event stream content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		var status GenerationStatus
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &status); err != nil {
+			return false, fmt.Errorf("ablerefusal: invalid status event: %w", err)
+		}
+		if !send(status) {
+			return true, ctx.Err()
+		}
+		if status.Status.IsTerminal() {
+			return true, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return ctx.Err() != nil, err
+	}
+	return ctx.Err() != nil, errors.New("ablerefusal: event stream closed early")
+}
+
+// poll fetches the status at the poll interval and sends every change
+func (c *Client) poll(ctx context.Context, id string, send func(GenerationStatus) bool) error {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	var last *GenerationStatus
+	for {
+		status, err := c.Status(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if last == nil || last.Status != status.Status || last.Progress != status.Progress {
+			if !send(*status) {
+				return nil
+			}
+			last = status
+		}
+		if status.Status.IsTerminal() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// Package pb contains the protobuf messages and gRPC stubs of the generation
+// service defined in proto/ablerefusal/v1/generation.proto.
+package pb
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=module=github.com/ablerefusal/ablerefusal/pkg/pb --go-grpc_out=. --go-grpc_opt=module=github.com/ablerefusal/ablerefusal/pkg/pb ablerefusal/v1/generation.proto
@@ -6,6 +6,13 @@ import { Download, Maximize2, X, Info, Copy, Check } from 'lucide-react';
 import { GenerationResult } from '@/lib/api';
 import sdApi from '@/lib/api';
 
+// Grid tiles use the smallest thumbnail that still fills them, or a resized
+// copy for outputs stored before thumbnails existed
+function thumbnailUrl(image: GenerationResult): string {
+  const thumbnail = image.thumbnails?.find((t) => t.width >= 512) ?? image.thumbnails?.[image.thumbnails.length - 1];
+  return thumbnail ? thumbnail.image_url : `${image.image_url}${image.image_url.includes('?') ? '&' : '?'}w=512`;
+}
+
 interface ImageGalleryProps {
   images: GenerationResult[];
   onImageClick?: (image: GenerationResult) => void;
@@ -23,7 +30,7 @@ export default function ImageGallery({ images, onImageClick }: ImageGalleryProps
       const url = window.URL.createObjectURL(blob);
       const a = document.createElement('a');
       a.href = url;
-      a.download = `sd-${image.seed}.png`;
+      a.download = `sd-${image.seed}${image.image_url.split('?')[0].match(/\.\w+$/)?.[0] ?? '.png'}`;
       document.body.appendChild(a);
       a.click();
       window.URL.revokeObjectURL(url);
@@ -66,7 +73,7 @@ export default function ImageGallery({ images, onImageClick }: ImageGalleryProps
             {/* Image */}
             <div className="relative aspect-square bg-palenight-bgDark rounded-lg overflow-hidden">
               <img
-                src={sdApi.getImageUrl(image.image_url)}
+                src={sdApi.getImageUrl(thumbnailUrl(image))}
                 alt={image.metadata?.prompt || 'Generated image'}
                 className="w-full h-full object-cover"
               />
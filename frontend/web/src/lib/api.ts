@@ -26,6 +26,9 @@ export interface GenerationRequest {
   // Image-to-image parameters
   init_image?: string;  // Base64 encoded image
   strength?: number;    // Denoising strength (0.0-1.0)
+  // Output encoding
+  output_format?: 'png' | 'jpeg' | 'webp' | 'avif';
+  output_quality?: number;  // 1-100, format default when omitted
 }
 
 export interface GenerationResponse {
@@ -54,6 +57,14 @@ export interface GenerationResult {
   width: number;
   height: number;
   metadata: Record<string, string>;
+  thumbnails?: Thumbnail[];
+}
+
+export interface Thumbnail {
+  width: number;
+  height: number;
+  image_path: string;
+  image_url: string;
 }
 
 export interface Model {
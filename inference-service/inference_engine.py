@@ -23,6 +23,10 @@ from diffusers import (
     StableDiffusionXLPipeline,
     StableDiffusionXLImg2ImgPipeline,
     DiffusionPipeline,
+    AutoPipelineForText2Image,
+    AutoPipelineForImage2Image,
+    AutoPipelineForInpainting,
+    ControlNetModel,
     DPMSolverMultistepScheduler,
     EulerAncestralDiscreteScheduler,
     EulerDiscreteScheduler,
@@ -34,6 +38,7 @@ from diffusers import (
     AutoencoderKL,
 )
 from diffusers.models import UNet2DConditionModel
+from diffusers.pipelines.controlnet import MultiControlNetModel
 from transformers import CLIPTextModel, CLIPTokenizer
 
 logger = logging.getLogger(__name__)
@@ -54,9 +59,15 @@ class GenerationRequest:
     loras: Optional[List[Dict[str, Any]]] = None
     enable_lcm: bool = False
     clip_skip: int = 1
+    # Attention weighted segments ({"text", "weight"}) for prompts using (word:1.3) syntax
+    prompt_weights: Optional[List[Dict[str, Any]]] = None
+    negative_prompt_weights: Optional[List[Dict[str, Any]]] = None
     # Image-to-image parameters
-    init_image: Optional[str] = None  # Base64 encoded image or file path
+    init_image: Optional[str] = None  # Base64 encoded image
     strength: float = 0.75  # Denoising strength (0.0 = no change, 1.0 = full generation)
+    mask: Optional[str] = None  # Base64 encoded inpainting mask, white areas are repainted
+    # ControlNet conditioning units, see _prepare_controlnets
+    controlnets: Optional[List[Dict[str, Any]]] = None
 
 
 @dataclass
@@ -111,6 +122,8 @@ class InferenceEngine:
         # Model storage
         self.pipelines: Dict[str, DiffusionPipeline] = {}
         self.img2img_pipelines: Dict[str, DiffusionPipeline] = {}
+        self.inpaint_pipelines: Dict[str, DiffusionPipeline] = {}
+        self.controlnets: Dict[str, ControlNetModel] = {}
         self.current_model: Optional[str] = None
         self.loaded_loras: Dict[str, Dict] = {}
         
@@ -362,17 +375,28 @@ class InferenceEngine:
         except Exception as e:
             logger.error(f"Failed to create img2img pipeline: {e}")
     
-    def _load_init_image(self, image_data: str) -> Image.Image:
-        """Load initial image from base64 or file path"""
+    def _get_inpaint_pipeline(self, model_path: str) -> DiffusionPipeline:
+        """Get or create the inpainting pipeline sharing the txt2img components"""
+        if model_path in self.inpaint_pipelines:
+            return self.inpaint_pipelines[model_path]
+        
+        try:
+            inpaint_pipe = AutoPipelineForInpainting.from_pipe(self.pipelines[model_path])
+        except Exception as e:
+            logger.error(f"Failed to create inpainting pipeline: {e}")
+            raise ValueError(f"Inpainting pipeline not available for model {model_path}")
+        
+        inpaint_pipe.set_progress_bar_config(disable=True)
+        self.inpaint_pipelines[model_path] = inpaint_pipe
+        logger.info(f"Created inpainting pipeline for {model_path}")
+        return inpaint_pipe
+    
+    def _decode_image(self, image_data: str) -> Image.Image:
+        """Decode an image from base64 or a base64 data URL, never from a file path"""
         import base64
         from io import BytesIO
         
         try:
-            # Check if it's a file path
-            if os.path.exists(image_data):
-                return Image.open(image_data).convert("RGB")
-            
-            # Try to decode as base64
             if image_data.startswith('data:image'):
                 # Remove data URL prefix
                 image_data = image_data.split(',')[1]
@@ -382,7 +406,7 @@ class InferenceEngine:
             return image
             
         except Exception as e:
-            logger.error(f"Failed to load init image: {e}")
+            logger.error(f"Failed to decode image: {e}")
             raise ValueError(f"Invalid image data: {e}")
     
     def _detect_sdxl(self, state_dict: Dict) -> bool:
@@ -467,16 +491,30 @@ class InferenceEngine:
         if not model_to_use or model_to_use not in self.pipelines:
             raise ValueError(f"Model {model_to_use} not loaded")
         
-        # Determine if this is img2img or txt2img
+        # Determine if this is inpainting, img2img or txt2img
         is_img2img = request.init_image is not None
+        is_inpaint = is_img2img and request.mask is not None
         
-        if is_img2img:
+        if is_inpaint:
+            pipe = self._get_inpaint_pipeline(model_to_use)
+        elif is_img2img:
             if model_to_use not in self.img2img_pipelines:
                 raise ValueError(f"Img2img pipeline not available for model {model_to_use}")
             pipe = self.img2img_pipelines[model_to_use]
         else:
             pipe = self.pipelines[model_to_use]
         
+        # Swap in the ControlNet variant of the pipeline when units are given
+        controlnet_units = self._prepare_controlnets(request.controlnets)
+        if controlnet_units:
+            if is_inpaint:
+                auto_class = AutoPipelineForInpainting
+            elif is_img2img:
+                auto_class = AutoPipelineForImage2Image
+            else:
+                auto_class = AutoPipelineForText2Image
+            pipe = self._get_controlnet_pipeline(model_to_use, auto_class, controlnet_units)
+        
         # Log what we're getting
         logger.info(f"Retrieved pipeline for model {model_to_use}")
         logger.info(f"Pipeline type: {type(pipe)}")
@@ -538,16 +576,44 @@ class InferenceEngine:
         
         # Add img2img specific parameters
         if is_img2img:
-            init_image = self._load_init_image(request.init_image)
+            init_image = self._decode_image(request.init_image)
             # Resize image to match requested dimensions
             init_image = init_image.resize((width, height), Image.LANCZOS)
             generation_kwargs["image"] = init_image
             generation_kwargs["strength"] = request.strength
+            if is_inpaint:
+                mask_image = self._decode_image(request.mask).convert("L")
+                generation_kwargs["mask_image"] = mask_image.resize((width, height), Image.NEAREST)
+                generation_kwargs["width"] = width
+                generation_kwargs["height"] = height
         else:
             # txt2img needs width and height
             generation_kwargs["width"] = width
             generation_kwargs["height"] = height
         
+        # Encode attention weights into embeddings, diffusers ignores them in plain prompts
+        if request.prompt_weights or request.negative_prompt_weights:
+            del generation_kwargs["prompt"]
+            del generation_kwargs["negative_prompt"]
+            generation_kwargs.update(self._encode_weighted_prompts(pipe, request))
+        
+        # Add ControlNet conditioning, txt2img pipelines take it as image
+        if controlnet_units:
+            control_images = [
+                self._preprocess_control_image(unit["image"], unit["preprocessor"], width, height)
+                for unit in controlnet_units
+            ]
+            control_kwargs = {
+                "control_image" if is_img2img else "image": control_images,
+                "controlnet_conditioning_scale": [unit["weight"] for unit in controlnet_units],
+                "control_guidance_start": [unit["guidance_start"] for unit in controlnet_units],
+                "control_guidance_end": [unit["guidance_end"] for unit in controlnet_units],
+            }
+            # A single ControlNetModel expects scalars rather than lists
+            if len(controlnet_units) == 1:
+                control_kwargs = {key: value[0] for key, value in control_kwargs.items()}
+            generation_kwargs.update(control_kwargs)
+        
         # Add callback for progress
         if progress_callback:
             def callback(pipe, step, timestep, callback_kwargs):
@@ -608,7 +674,11 @@ class InferenceEngine:
                         "model": model_to_use,
                         "loras": request.loras,
                         "enable_lcm": request.enable_lcm,
-                        "clip_skip": request.clip_skip
+                        "clip_skip": request.clip_skip,
+                        "controlnets": [
+                            {key: value for key, value in unit.items() if key != "image"}
+                            for unit in controlnet_units
+                        ]
                     }
                 )
                 results.append(result)
@@ -626,6 +696,57 @@ class InferenceEngine:
                 pipe.unload_lora_weights()
                 self.loaded_loras.clear()
     
+    def _encode_weighted_prompts(self, pipe: DiffusionPipeline, request: GenerationRequest) -> Dict[str, Any]:
+        """Encode attention weighted prompts into prompt embeddings with compel"""
+        from compel import Compel, ReturnedEmbeddingsType
+        
+        is_sdxl = getattr(pipe, "tokenizer_2", None) is not None
+        if is_sdxl:
+            compel = Compel(
+                tokenizer=[pipe.tokenizer, pipe.tokenizer_2],
+                text_encoder=[pipe.text_encoder, pipe.text_encoder_2],
+                returned_embeddings_type=ReturnedEmbeddingsType.PENULTIMATE_HIDDEN_STATES_NON_NORMALIZED,
+                requires_pooled=[False, True],
+            )
+        else:
+            compel = Compel(tokenizer=pipe.tokenizer, text_encoder=pipe.text_encoder, truncate_long_prompts=False)
+        
+        prompt = self._compel_prompt(request.prompt_weights, request.prompt)
+        negative = self._compel_prompt(request.negative_prompt_weights, request.negative_prompt)
+        
+        with torch.no_grad():
+            if is_sdxl:
+                embeds, pooled = compel(prompt)
+                negative_embeds, negative_pooled = compel(negative)
+            else:
+                embeds = compel(prompt)
+                negative_embeds = compel(negative)
+            embeds, negative_embeds = compel.pad_conditioning_tensors_to_same_length([embeds, negative_embeds])
+        
+        kwargs = {"prompt_embeds": embeds, "negative_prompt_embeds": negative_embeds}
+        if is_sdxl:
+            kwargs["pooled_prompt_embeds"] = pooled
+            kwargs["negative_pooled_prompt_embeds"] = negative_pooled
+        return kwargs
+    
+    @staticmethod
+    def _compel_prompt(segments: Optional[List[Dict[str, Any]]], text: str) -> str:
+        """Write weighted segments in compel syntax, escaping its brackets in the text"""
+        def escape(value: str) -> str:
+            return value.replace("\\", "\\\\").replace("(", "\\(").replace(")", "\\)")
+        
+        if not segments:
+            return escape(text)
+        
+        parts = []
+        for segment in segments:
+            weight = float(segment.get("weight", 1.0))
+            if weight == 1.0:
+                parts.append(escape(segment["text"]))
+            else:
+                parts.append(f"({escape(segment['text'])}){weight:.4g}")
+        return "".join(parts)
+    
     def get_loaded_models(self) -> List[str]:
         """Get list of loaded models"""
         return list(self.pipelines.keys())
@@ -661,12 +782,126 @@ class InferenceEngine:
         
         return models
     
+    CONTROLNET_TYPES = ["canny", "depth", "openpose", "lineart", "softedge", "scribble", "normal", "seg", "tile", "mlsd"]
+    # Control types with a built-in preprocessor; other types need a ready-made control image
+    CONTROLNET_PREPROCESSORS = ["canny"]
+    
+    def list_controlnet_models(self) -> List[Dict[str, Any]]:
+        """List ControlNet models available in the controlnet models directory"""
+        controlnets = []
+        controlnet_dir = self.models_dir / "controlnet"
+        if not controlnet_dir.exists():
+            return controlnets
+        
+        for entry in sorted(controlnet_dir.iterdir()):
+            if not (entry.is_dir() or entry.suffix == ".safetensors"):
+                continue
+            name = entry.stem if entry.is_file() else entry.name
+            control_type = next((t for t in self.CONTROLNET_TYPES if t in name.lower()), "unknown")
+            preprocessors = ["none"]
+            if control_type in self.CONTROLNET_PREPROCESSORS:
+                preprocessors.append(control_type)
+            controlnets.append({
+                "name": name,
+                "type": control_type,
+                "preprocessors": preprocessors,
+            })
+        
+        return controlnets
+    
+    def _prepare_controlnets(self, units: Optional[List[Dict[str, Any]]]) -> List[Dict[str, Any]]:
+        """Resolve each enabled unit to an installed control model, dropping units with weight 0"""
+        prepared = []
+        if not units:
+            return prepared
+        
+        available = self.list_controlnet_models()
+        for i, unit in enumerate(units):
+            weight = float(unit.get("weight", 1.0))
+            if weight == 0:
+                continue
+            
+            control_type = (unit.get("type") or "").lower()
+            name = unit.get("model")
+            model = next(
+                (m for m in available if (m["name"] == name if name else m["type"] == control_type)),
+                None
+            )
+            if model is None:
+                raise ValueError(f"ControlNet model for unit {i} ({name or control_type}) not found")
+            
+            preprocessor = (unit.get("preprocessor") or "none").lower()
+            if preprocessor not in model["preprocessors"]:
+                raise ValueError(f"ControlNet unit {i} ({control_type}): unsupported preprocessor {preprocessor}")
+            
+            prepared.append({
+                "type": control_type,
+                "model": model["name"],
+                "image": unit["image"],
+                "weight": weight,
+                "guidance_start": float(unit.get("guidance_start", 0.0)),
+                "guidance_end": float(unit.get("guidance_end", 1.0)),
+                "preprocessor": preprocessor,
+            })
+        
+        return prepared
+    
+    def _load_controlnet(self, name: str) -> ControlNetModel:
+        """Load a control model from the controlnet models directory, caching it"""
+        if name in self.controlnets:
+            return self.controlnets[name]
+        
+        path = self.models_dir / "controlnet" / name
+        if path.is_dir():
+            controlnet = ControlNetModel.from_pretrained(str(path), torch_dtype=self.dtype)
+        else:
+            controlnet = ControlNetModel.from_single_file(str(path.with_suffix(".safetensors")), torch_dtype=self.dtype)
+        controlnet.to(self.device)
+        
+        self.controlnets[name] = controlnet
+        logger.info(f"Loaded ControlNet model {name}")
+        return controlnet
+    
+    def _get_controlnet_pipeline(
+        self,
+        model_path: str,
+        auto_class: type,
+        units: List[Dict[str, Any]]
+    ) -> DiffusionPipeline:
+        """Build the ControlNet variant of a pipeline, sharing the loaded model's components"""
+        controlnets = [self._load_controlnet(unit["model"]) for unit in units]
+        controlnet = controlnets[0] if len(controlnets) == 1 else MultiControlNetModel(controlnets)
+        
+        try:
+            pipe = auto_class.from_pipe(self.pipelines[model_path], controlnet=controlnet)
+        except Exception as e:
+            logger.error(f"Failed to create ControlNet pipeline: {e}")
+            raise ValueError(f"ControlNet pipeline not available for model {model_path}")
+        
+        pipe.set_progress_bar_config(disable=True)
+        return pipe
+    
+    def _preprocess_control_image(self, image_data: str, preprocessor: str, width: int, height: int) -> Image.Image:
+        """Decode a control image, resize it to the output and run its preprocessor"""
+        image = self._decode_image(image_data).resize((width, height), Image.LANCZOS)
+        if preprocessor == "none":
+            return image
+        
+        if preprocessor == "canny":
+            import cv2
+            
+            edges = cv2.Canny(np.array(image), 100, 200)
+            return Image.fromarray(np.stack([edges] * 3, axis=-1))
+        
+        raise ValueError(f"Unsupported ControlNet preprocessor {preprocessor}")
+    
     async def cleanup(self):
         """Cleanup resources"""
         for pipe in self.pipelines.values():
             del pipe
         
         self.pipelines.clear()
+        self.controlnets.clear()
         
         if torch.cuda.is_available():
             torch.cuda.empty_cache()
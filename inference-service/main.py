@@ -71,9 +71,15 @@ class GenerateRequest(BaseModel):
     loras: Optional[List[Dict[str, Any]]] = None
     enable_lcm: bool = False
     clip_skip: int = Field(default=1, ge=1, le=12)
+    # Attention weighted segments for prompts using (word:1.3) syntax
+    prompt_weights: Optional[List[Dict[str, Any]]] = None
+    negative_prompt_weights: Optional[List[Dict[str, Any]]] = None
     # Image-to-image parameters
     init_image: Optional[str] = None  # Base64 encoded image
     strength: float = Field(default=0.75, ge=0.0, le=1.0)  # Denoising strength
+    mask: Optional[str] = None  # Base64 encoded inpainting mask, white areas are repainted
+    # ControlNet conditioning units
+    controlnets: Optional[List[Dict[str, Any]]] = None
 
 
 class GenerateResponse(BaseModel):
@@ -91,6 +97,7 @@ class JobStatus(BaseModel):
     message: Optional[str] = None
     results: Optional[List[str]] = None  # Image URLs/paths
     error: Optional[str] = None
+    error_code: Optional[str] = None  # Stable code for the failure, see classify_error
     created_at: datetime
     completed_at: Optional[datetime] = None
     
@@ -197,6 +204,20 @@ async def generate_image(
     )
 
 
+def classify_error(e: Exception) -> str:
+    """Map a generation failure to a stable error code for the backend"""
+    message = str(e)
+    if isinstance(e, MemoryError) or "out of memory" in message.lower():
+        return "out_of_memory"
+    if "not loaded" in message or "No base model" in message:
+        return "model_not_loaded"
+    if "pipeline not available" in message or "not properly initialized" in message:
+        return "pipeline_unavailable"
+    if isinstance(e, ValueError):
+        return "invalid_input"
+    return "inference_error"
+
+
 async def run_generation(job_id: str, request: GenerateRequest):
     """Run generation task in background"""
     try:
@@ -224,8 +245,12 @@ async def run_generation(job_id: str, request: GenerateRequest):
             loras=request.loras,
             enable_lcm=request.enable_lcm,
             clip_skip=request.clip_skip,
+            prompt_weights=request.prompt_weights,
+            negative_prompt_weights=request.negative_prompt_weights,
             init_image=request.init_image,
-            strength=request.strength
+            strength=request.strength,
+            mask=request.mask,
+            controlnets=request.controlnets
         )
         
         # Run generation
@@ -244,6 +269,7 @@ async def run_generation(job_id: str, request: GenerateRequest):
         logger.error(f"Generation failed for job {job_id}: {e}")
         jobs[job_id]["status"] = "failed"
         jobs[job_id]["error"] = str(e)
+        jobs[job_id]["error_code"] = classify_error(e)
         jobs[job_id]["completed_at"] = datetime.now(timezone.utc)
 
 
@@ -263,6 +289,7 @@ async def get_job_status(job_id: str):
         message=job.get("message"),
         results=job.get("results"),
         error=job.get("error"),
+        error_code=job.get("error_code"),
         created_at=job["created_at"],
         completed_at=job.get("completed_at")
     )
@@ -293,32 +320,28 @@ async def list_models():
     }
 
 
+@app.get("/controlnets")
+async def list_controlnets():
+    """List available ControlNet models"""
+    if not inference_engine:
+        raise HTTPException(status_code=503, detail="Inference engine not initialized")
+    
+    return {"controlnets": inference_engine.list_controlnet_models()}
+
+
 @app.get("/samplers")
 async def list_samplers():
-    """List available samplers"""
+    """List available samplers and the schedulers backing them"""
+    mapping = InferenceEngine.SCHEDULER_MAPPING
     return {
-        "samplers": [
-            "DPM++ 2M Karras",
-            "DPM++ 2M SDE Karras", 
-            "DPM++ SDE Karras",
-            "Euler a",
-            "Euler",
-            "LMS",
-            "Heun",
-            "DPM2",
-            "DPM2 a",
-            "DPM++ 2S a",
-            "DPM fast",
-            "DPM adaptive",
-            "LMS Karras",
-            "DDIM",
-            "PLMS",
-            "UniPC",
-        ],
-        "lcm_samplers": [
-            "LCM",
-            "LCM Karras"
-        ]
+        "samplers": [name for name in mapping if not name.startswith("LCM")],
+        "lcm_samplers": [name for name in mapping if name.startswith("LCM")],
+        "schedulers": {name: scheduler.__name__ for name, (scheduler, _) in mapping.items()},
+        "aliases": {
+            "Euler a": ["euler_a", "euler_ancestral"],
+            "DPM++ 2M Karras": ["dpmpp_2m_karras"],
+            "PNDM": ["PLMS"],
+        },
     }
 
 
@@ -101,7 +101,7 @@ echo "✅ Go dependencies installed"
 
 # Build backend
 echo "Building backend..."
-go build -o sd-backend cmd/server/main.go
+go build -o sd-backend ./cmd/server
 echo "✅ Backend built successfully"
 
 cd ..